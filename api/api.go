@@ -2,40 +2,67 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
-	"math/rand"
+	"mime"
+	"net"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/jinzhu/copier"
 
 	"github.com/DeFacto-Team/Factom-Open-API/config"
 	"github.com/DeFacto-Team/Factom-Open-API/errors"
+	"github.com/DeFacto-Team/Factom-Open-API/logging"
+	"github.com/DeFacto-Team/Factom-Open-API/metrics"
 	"github.com/DeFacto-Team/Factom-Open-API/model"
+	"github.com/DeFacto-Team/Factom-Open-API/proxy"
+	"github.com/DeFacto-Team/Factom-Open-API/ratelimit"
 	"github.com/DeFacto-Team/Factom-Open-API/service"
+	"github.com/DeFacto-Team/Factom-Open-API/totp"
+	"github.com/DeFacto-Team/Factom-Open-API/tracing"
+	"github.com/DeFacto-Team/Factom-Open-API/wallet"
 	"github.com/DeFacto-Team/Factom-Open-API/webpack"
 	"github.com/FactomProject/factom"
 	"github.com/dgrijalva/jwt-go"
+	"github.com/jinzhu/gorm/dialects/postgres"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	log "github.com/sirupsen/logrus"
 	echoSwagger "github.com/swaggo/echo-swagger"
 	_ "github.com/swaggo/echo-swagger/example/docs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/go-playground/validator.v9"
 )
 
 type API struct {
 	HTTP       *echo.Echo
 	conf       *config.Config
+	confMu     sync.RWMutex
+	applyMu    sync.Mutex // serializes config reloads, so apply & rebind of one reload is not interleaved with another
 	service    service.Service
 	configFile string
 	apiInfo    APIInfo
 	validate   *validator.Validate
-	user       *model.User
-	jwtSecret  []byte
+	proxy      *proxy.Policy
+	limiter    *ratelimit.Limiter
+	now        func() time.Time
+	server     *http.Server
+	serverMu   sync.Mutex
+	serverErr  chan error
 }
 
 type APIInfo struct {
@@ -66,6 +93,9 @@ type SuccessResponsePagination struct {
 	Total  *int        `json:"total"`
 }
 
+// request IDs received from clients are accepted only in this format
+var requestIDRegexp = regexp.MustCompile("^[A-Za-z0-9._-]{1,64}$")
+
 type ViewData struct {
 	assetsMapper webpack.AssetsMapper
 }
@@ -77,6 +107,22 @@ const (
 	DefaultSort            = "desc"
 	AlternativeSort        = "asc"
 	AccessTokenLength      = 32
+	// time to enter TOTP code after password
+	AdminMFATokenLifetime = 5 * time.Minute
+	// TOTP codes per minute per admin
+	AdminTOTPAttemptsLimit = 5
+	// time to complete requests on the old port after HTTP port change
+	RebindTimeout = 30 * time.Second
+	// issuer shown in authenticator apps
+	TOTPIssuer = "Factom Open API"
+	// echo context keys of authenticated API user & admin
+	userContextKey         = "user"
+	adminContextKey        = "admin"
+	adminSessionContextKey = "adminSession"
+	// raw entry ExtIDs header (repeated in order of ExtIDs) & multipart form fields
+	HeaderExtID = "X-Ext-Id"
+	FormExtIDs  = "extIds"
+	FormContent = "content"
 )
 
 // NewViewData creates new data for the view
@@ -106,42 +152,63 @@ func NewAPI(conf *config.Config, s service.Service, configFile string) *API {
 	api.service = s
 	api.configFile = configFile
 
+	api.now = time.Now
+	api.limiter = ratelimit.New()
+	api.serverErr = make(chan error, 1)
+
+	var err error
+	api.proxy, err = proxy.NewPolicy(conf.Factom.ProxyMethods)
+	if err != nil {
+		log.Error(err)
+		log.Warn("Using default read-only methods for generic factomd interface")
+		api.proxy, _ = proxy.NewPolicy(nil)
+	}
+
 	api.HTTP = echo.New()
 	api.HTTP.HideBanner = true
 	api.HTTP.HidePort = true
 	api.apiInfo.Version = Version
-	api.apiInfo.Port = api.conf.API.HTTPPort
+	api.apiInfo.Port = conf.API.HTTPPort
 	api.HTTP.Pre(middleware.RemoveTrailingSlash())
 
+	api.HTTP.Use(api.requestID)
+	api.apiInfo.MW = append(api.apiInfo.MW, "RequestID")
+
+	// tracing goes before logging, so request logs carry trace ID
+	api.HTTP.Use(api.traceRequest)
+	api.apiInfo.MW = append(api.apiInfo.MW, "Tracing")
+
 	if conf.API.Logging {
-		api.HTTP.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
-			Format: "  API[${status}] ${method} ${uri} (ip=${remote_ip}, latency=${latency_human})\n",
-		}))
+		api.HTTP.Use(api.logRequest)
 		api.apiInfo.MW = append(api.apiInfo.MW, "Logger")
 	}
 
+	api.HTTP.Use(api.measure)
+	api.apiInfo.MW = append(api.apiInfo.MW, "Metrics")
+
 	api.HTTP.Use(middleware.Recover())
 	api.apiInfo.MW = append(api.apiInfo.MW, "Recover")
 
 	authGroup := api.HTTP.Group("/v1")
 	authGroup.Use(middleware.KeyAuth(func(key string, c echo.Context) (bool, error) {
-		user := api.service.CheckUser(key)
+		user := api.svc(c).CheckUser(key)
 		if user != nil {
-			api.user = user
+			c.Set(userContextKey, user)
 			return true, nil
 		}
-		err := fmt.Errorf("Invalid auth key: %s", key)
+		// only prefix of the key is logged, so secrets aren't exposed in logs
+		prefix := key
+		if len(prefix) > model.APIKeyPrefixLength {
+			prefix = prefix[:model.APIKeyPrefixLength]
+		}
+		err := fmt.Errorf("Invalid auth key: %s...", prefix)
 		log.Error(err)
 		return false, err
 	}))
 	api.apiInfo.MW = append(api.apiInfo.MW, "KeyAuth")
 
 	adminGroup := api.HTTP.Group("/admin")
-	api.jwtSecret = generateJWTSecret()
-	adminGroup.Use(middleware.JWTWithConfig(middleware.JWTConfig{
-		SigningKey:  api.jwtSecret,
-		TokenLookup: "cookie:token",
-	}))
+	adminGroup.Use(api.adminAuth)
 	api.apiInfo.MW = append(api.apiInfo.MW, "JWT")
 
 	// Login endpoint
@@ -155,351 +222,1630 @@ func NewAPI(conf *config.Config, s service.Service, configFile string) *API {
 	api.HTTP.File("/favicon96x96.png", "ui/build/favicon96x96.png")
 	api.HTTP.Static("/static", "ui/build/static")
 
+	viewer := api.requireRole(model.AdminRoleViewer)
+	operator := api.requireRole(model.AdminRoleOperator)
+	superadmin := api.requireRole(model.AdminRoleSuperadmin)
+
 	// Admin endpoints
-	adminGroup.GET("", api.adminIndex)
-	adminGroup.GET("/queue", api.adminGetQueue)
-	adminGroup.DELETE("/queue", api.adminDeleteQueue)
-	adminGroup.GET("/users", api.adminGetUsers)
-	adminGroup.POST("/users", api.adminCreateUser)
-	adminGroup.DELETE("/users", api.adminDeleteUser)
-	adminGroup.GET("/users/:id", api.adminGetUser)
-	adminGroup.PUT("/users/:id", api.adminUpdateUser)
-	adminGroup.GET("/users/:id/rotate", api.adminRotateUserToken)
-	adminGroup.GET("/logout", api.adminLogout)
-	adminGroup.GET("/settings", api.adminGetSettings)
-	adminGroup.POST("/settings", api.adminUpdateSettings)
-	adminGroup.GET("/restart", api.adminRestartAPI)
-	adminGroup.GET("/ec/random", api.adminRandomEC)
-	adminGroup.GET("/ec/:esaddress", api.adminGetEC)
+	adminGroup.GET("", api.adminIndex, viewer)
+	adminGroup.GET("/me", api.adminGetMe, viewer)
+	adminGroup.POST("/me/totp", api.adminEnrollTOTP, viewer)
+	adminGroup.POST("/me/totp/enable", api.adminEnableTOTP, viewer)
+	adminGroup.POST("/me/totp/disable", api.adminDisableTOTP, viewer)
+	adminGroup.POST("/me/totp/recovery", api.adminRegenerateRecoveryCodes, viewer)
+	adminGroup.GET("/queue", api.adminGetQueue, viewer)
+	adminGroup.GET("/sync", api.adminGetSync, viewer)
+	adminGroup.GET("/pool", api.adminGetPool, viewer)
+	adminGroup.PUT("/pool/:chainid", api.adminUpdatePool, operator)
+	adminGroup.DELETE("/pool/:chainid", api.adminDeletePool, operator)
+	adminGroup.PUT("/chains/:chainid/pin", api.adminPinChain, operator)
+	adminGroup.DELETE("/chains/:chainid/pin", api.adminUnpinChain, operator)
+	adminGroup.DELETE("/queue", api.adminDeleteQueue, operator)
+	adminGroup.GET("/users", api.adminGetUsers, viewer)
+	adminGroup.POST("/users", api.adminCreateUser, operator)
+	adminGroup.DELETE("/users", api.adminDeleteUser, operator)
+	adminGroup.GET("/users/:id", api.adminGetUser, viewer)
+	adminGroup.PUT("/users/:id", api.adminUpdateUser, operator)
+	adminGroup.GET("/users/:id/rotate", api.adminRotateUserToken, operator)
+	adminGroup.GET("/users/:id/usage", api.adminGetUserUsage, viewer)
+	adminGroup.GET("/users/:id/keys", api.adminGetUserKeys, viewer)
+	adminGroup.POST("/users/:id/keys", api.adminCreateUserKey, operator)
+	adminGroup.DELETE("/users/:id/keys/:keyid", api.adminDeleteUserKey, operator)
+	adminGroup.GET("/logout", api.adminLogout, viewer)
+	adminGroup.GET("/settings", api.adminGetSettings, superadmin)
+	adminGroup.POST("/settings", api.adminUpdateSettings, superadmin)
+	adminGroup.POST("/settings/reload", api.adminReloadSettings, superadmin)
+	adminGroup.GET("/audit", api.adminGetAudit, superadmin)
+	adminGroup.GET("/sessions", api.adminGetSessions, superadmin)
+	adminGroup.DELETE("/sessions/:id", api.adminDeleteSession, superadmin)
+	adminGroup.POST("/sessions/rotate", api.adminRotateJWTKey, superadmin)
+	adminGroup.GET("/admins", api.adminGetAdmins, superadmin)
+	adminGroup.POST("/admins", api.adminCreateAdmin, superadmin)
+	adminGroup.PUT("/admins/:id", api.adminUpdateAdmin, superadmin)
+	adminGroup.DELETE("/admins/:id", api.adminDeleteAdmin, superadmin)
+	adminGroup.DELETE("/admins/:id/totp", api.adminResetAdminTOTP, superadmin)
+	adminGroup.GET("/ec/random", api.adminRandomEC, operator)
+	adminGroup.GET("/ec/:esaddress", api.adminGetEC, operator)
 
 	// Status
 	api.HTTP.GET("/v1", api.index)
 
+	// Liveness & readiness probes
+	api.HTTP.GET("/healthz", api.healthz)
+	api.HTTP.GET("/readyz", api.readyz)
+
+	// Prometheus metrics
+	api.HTTP.GET("/metrics", api.metrics)
+
 	// Documentation
 	url := echoSwagger.URL("swagger.json")
 	api.HTTP.File("/docs/swagger.json", "docs/swagger.json")
 	api.HTTP.GET("/docs/*", echoSwagger.EchoWrapHandler(url))
 
+	api.apiInfo.MW = append(api.apiInfo.MW, "RateLimit")
+
+	// scope is checked before rate limit, so requests without the scope don't consume tokens
+	read := api.rateLimit(model.RateLimitGroupRead)
+	write := api.rateLimit(model.RateLimitGroupWrite)
+
 	// Chains
-	authGroup.POST("/chains", api.createChain)
-	authGroup.GET("/chains", api.getChains)
-	authGroup.GET("/chains/:chainid", api.getChain)
-	authGroup.POST("/chains/search", api.searchChains)
+	authGroup.POST("/chains", api.createChain, api.requireScope(model.ScopeChainsWrite), write)
+	authGroup.GET("/chains", api.getChains, api.requireScope(model.ScopeChainsRead), read)
+	authGroup.GET("/chains/:chainid", api.getChain, api.requireScope(model.ScopeChainsRead), read)
+	authGroup.DELETE("/chains/:chainid", api.deleteChain, api.requireScope(model.ScopeChainsWrite), write)
+	authGroup.POST("/chains/search", api.searchChains, api.requireScope(model.ScopeChainsRead), read)
 
 	// Chains entries
-	authGroup.GET("/chains/:chainid/entries", api.getChainEntries)
-	authGroup.POST("/chains/:chainid/entries/search", api.searchChainEntries)
-	authGroup.GET("/chains/:chainid/entries/:item", api.getChainFirstOrLastEntry)
+	authGroup.GET("/chains/:chainid/entries", api.getChainEntries, api.requireScope(model.ScopeEntriesRead), read)
+	authGroup.POST("/chains/:chainid/entries/search", api.searchChainEntries, api.requireScope(model.ScopeEntriesRead), read)
+	authGroup.GET("/chains/:chainid/entries/:item", api.getChainFirstOrLastEntry, api.requireScope(model.ScopeEntriesRead), read)
+	authGroup.POST("/chains/:chainid/entries/raw", api.createRawEntry, api.requireScope(model.ScopeEntriesWrite), write)
 
 	// Entries
-	authGroup.POST("/entries", api.createEntry)
-	authGroup.GET("/entries/:entryhash", api.getEntry)
+	authGroup.POST("/entries", api.createEntry, api.requireScope(model.ScopeEntriesWrite), write)
+	authGroup.GET("/entries/:entryhash", api.getEntry, api.requireScope(model.ScopeEntriesRead), read)
+	authGroup.GET("/entries/:entryhash/content", api.getEntryContent, api.requireScope(model.ScopeEntriesRead), read)
 
 	// User
-	authGroup.GET("/user", api.getUser)
+	authGroup.GET("/user", api.getUser, read)
 
 	// Direct factomd call
-	authGroup.POST("/factomd/:method", api.factomd)
+	authGroup.POST("/factomd/:method", api.factomd, api.requireScope(model.ScopeFactomdProxy), api.rateLimit(model.RateLimitGroupProxy))
 
 	return api
 }
 
-// Start API server
+// Start API server. Blocks until server is stopped by Stop or fails
 func (api *API) Start() error {
-	return api.HTTP.Start(":" + strconv.Itoa(api.conf.API.HTTPPort))
+
+	ln, err := net.Listen("tcp", ":"+strconv.Itoa(api.currentConf().API.HTTPPort))
+	if err != nil {
+		return err
+	}
+
+	api.serve(ln)
+
+	return <-api.serverErr
+
 }
 
-// Stop API server
-func (api *API) Stop() error {
-	log.Warn("Gracefully restarting API in 5 seconds")
-	time.Sleep(3 * time.Second)
-	return api.HTTP.Close()
+// Stop API server gracefully: waits for active requests until ctx is done
+func (api *API) Stop(ctx context.Context) error {
+
+	api.serverMu.Lock()
+	server := api.server
+	api.serverMu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+
+	err := server.Shutdown(ctx)
+
+	select {
+	case api.serverErr <- err:
+	default:
+	}
+
+	return err
+
 }
 
 // Returns API information
 func (api *API) GetAPIInfo() APIInfo {
+
+	api.confMu.RLock()
+	defer api.confMu.RUnlock()
+
 	return api.apiInfo
-}
 
-func (api *API) login(c echo.Context) error {
-	user := c.FormValue("user")
-	password := c.FormValue("password")
+}
 
-	// Check admin auth
-	if user == api.conf.Admin.User && password == api.conf.Admin.Password {
+// serves HTTP requests on the listener in background
+func (api *API) serve(ln net.Listener) {
 
-		// Create token
-		token := jwt.New(jwt.SigningMethodHS256)
+	server := &http.Server{Handler: api.HTTP}
 
-		// Set claims
-		claims := token.Claims.(jwt.MapClaims)
-		claims["admin"] = true
-		claims["exp"] = time.Now().Add(time.Hour * 24).Unix()
+	api.serverMu.Lock()
+	api.server = server
+	api.serverMu.Unlock()
 
-		// Generate encoded token and send it as response.
-		t, err := token.SignedString(api.jwtSecret)
-		if err != nil {
-			return err
+	go func() {
+		if err := server.Serve(ln); err != http.ErrServerClosed {
+			select {
+			case api.serverErr <- err:
+			default:
+			}
 		}
+	}()
+
+}
+
+// rebind starts serving on the new port and gracefully shuts down the old server,
+// so requests in progress are completed on the old port
+func (api *API) rebind(port int) error {
+
+	ln, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		return err
+	}
+
+	api.serverMu.Lock()
+	old := api.server
+	api.serverMu.Unlock()
 
-		writeCookie(c, t)
+	api.serve(ln)
 
-		return c.JSON(http.StatusOK, map[string]string{
-			"token": t,
-		})
+	if old != nil {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), RebindTimeout)
+			defer cancel()
+			if err := old.Shutdown(ctx); err != nil {
+				log.Error(err)
+			}
+		}()
 	}
 
-	return echo.ErrUnauthorized
+	log.WithField("port", port).Info("REST API is listening on the new port")
+
+	return nil
 
 }
 
-func (api *API) adminLogout(c echo.Context) error {
+// Returns current config of API
+func (api *API) currentConf() *config.Config {
 
-	deleteCookie(c)
+	api.confMu.RLock()
+	defer api.confMu.RUnlock()
 
-	return c.JSON(http.StatusOK, map[string]bool{
-		"ok": true,
-	})
+	return api.conf
 
 }
 
-func (api *API) adminGetSettings(c echo.Context) error {
+// Returns current allow-list of factomd methods
+func (api *API) currentProxy() *proxy.Policy {
 
-	return c.JSON(http.StatusOK, api.conf)
+	api.confMu.RLock()
+	defer api.confMu.RUnlock()
+
+	return api.proxy
 
 }
 
-func (api *API) adminUpdateSettings(c echo.Context) error {
+// ApplyConfig applies new config to running API without restart.
+// Log level, factomd node, EC address, rate limits & factomd methods allow-list are swapped in place,
+// HTTP port change starts listening on the new port and gracefully closes the old one.
+// Returns true if some settings (store, HTTP logging) require restart of the process to be applied.
+func (api *API) ApplyConfig(newConf *config.Config) (bool, error) {
 
-	newConf := &config.Config{}
-	copier.Copy(newConf, api.conf)
+	api.applyMu.Lock()
+	defer api.applyMu.Unlock()
 
-	if err := c.Bind(newConf); err != nil {
-		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
-	}
+	return api.applyConfig(newConf)
 
-	if err := config.UpdateConfig(api.configFile, newConf); err != nil {
-		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
+}
+
+// applyConfig applies new config, caller holds applyMu
+func (api *API) applyConfig(newConf *config.Config) (bool, error) {
+
+	if err := newConf.Validate(); err != nil {
+		return false, err
 	}
 
-	return c.JSON(http.StatusOK, map[string]bool{
-		"ok": true,
-	})
+	oldConf := api.currentConf()
 
-}
+	policy, err := proxy.NewPolicy(newConf.Factom.ProxyMethods)
+	if err != nil {
+		return false, err
+	}
 
-func (api *API) adminRestartAPI(c echo.Context) error {
+	// everything that can fail is prepared first, so failed config is not applied partially
+	walletChanged := newConf.Factom.EsAddress != oldConf.Factom.EsAddress || newConf.Factom.URL != oldConf.Factom.URL
+	var w wallet.Wallet
+	if walletChanged && newConf.Factom.EsAddress != "" {
+		if w, err = wallet.NewWallet(newConf); err != nil {
+			return false, err
+		}
+	}
 
-	go api.Stop()
+	// rebind is the last step that can fail, the old port is closed only after the new one is listening
+	if newConf.API.HTTPPort != oldConf.API.HTTPPort {
+		if err := api.rebind(newConf.API.HTTPPort); err != nil {
+			return false, err
+		}
+	}
 
-	return c.JSON(http.StatusOK, map[string]bool{
-		"ok": true,
-	})
+	newConf.ApplyGlobals()
 
-}
+	if walletChanged {
+		api.service.SetWallet(w)
+	}
 
-func (api *API) adminGetEC(c echo.Context) error {
+	api.confMu.Lock()
+	api.conf = newConf
+	api.proxy = policy
+	api.apiInfo.Port = newConf.API.HTTPPort
+	api.confMu.Unlock()
 
-	ecAddress := model.GetEC(c.Param("esaddress"))
-	if ecAddress == nil {
-		return api.ErrorResponse(errors.New(errors.ServiceError, fmt.Errorf("Invalid Es address")), c)
+	restartRequired := oldConf.RestartRequired(newConf)
+	if restartRequired {
+		log.Warn("Store or HTTP logging settings changed, restart Factom Open API to apply them")
 	}
 
-	ecAddress.GetBalanceFromFactom()
+	log.Info("Config applied")
 
-	return api.SuccessResponse(ecAddress, c)
+	return restartRequired, nil
 
 }
 
-func (api *API) adminRandomEC(c echo.Context) error {
+// ReloadConfig reads config file and applies it, used on SIGHUP
+func (api *API) ReloadConfig() error {
 
-	ecAddress := model.GenerateEC()
-	if ecAddress == nil {
-		return api.ErrorResponse(errors.New(errors.ServiceError, fmt.Errorf("EC keypair generation error")), c)
-	}
+	api.applyMu.Lock()
+	defer api.applyMu.Unlock()
 
-	ecAddress.GetBalanceFromFactom()
+	newConf, err := config.NewConfig(api.configFile)
+	if err != nil {
+		return err
+	}
 
-	return api.SuccessResponse(ecAddress, c)
+	oldConf := api.currentConf()
 
-}
+	if _, err := api.applyConfig(newConf); err != nil {
+		return err
+	}
 
-func (api *API) adminIndex(c echo.Context) error {
+	api.service.Audit(&model.AuditLog{
+		ActorType:  model.AuditActorSystem,
+		Action:     model.AuditActionSettingsReload,
+		TargetType: "settings",
+		Before:     auditJSON(oldConf.Redacted()),
+		After:      auditJSON(newConf.Redacted()),
+	})
 
-	return api.SuccessResponse(api.GetAPIInfo(), c)
+	return nil
 
 }
 
-func (api *API) adminGetQueue(c echo.Context) error {
+// Admin login. If admin has two-factor authentication enabled, login is two-step:
+// the first request with user & password returns short-lived mfaToken,
+// the second request with mfaToken & code (TOTP or recovery code) starts the session
+func (api *API) login(c echo.Context) error {
 
-	resp := api.service.GetQueue(&model.Queue{})
+	if mfaToken := c.FormValue("mfaToken"); mfaToken != "" {
+		return api.loginTOTP(mfaToken, c.FormValue("code"), c)
+	}
 
-	return api.SuccessResponse(resp, c)
+	user := c.FormValue("user")
+	password := c.FormValue("password")
 
-}
+	if user == "" {
+		return echo.ErrUnauthorized
+	}
 
-func (api *API) adminDeleteQueue(c echo.Context) error {
+	// Check admin auth
+	admin := api.svc(c).CheckAdmin(user, password)
+	if admin == nil {
+		api.auditLoginFailed(user, c)
+		return echo.ErrUnauthorized
+	}
 
-	req := &model.Queue{}
+	if !admin.TOTPEnabled {
+		return api.startAdminSession(admin, c)
+	}
 
-	// bind input data
-	if err := c.Bind(req); err != nil {
-		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
+	claims := jwt.MapClaims{
+		"sub": admin.ID,
+		"mfa": true,
+		"exp": time.Now().Add(AdminMFATokenLifetime).Unix(),
 	}
 
-	if err := api.service.DeleteQueue(req); err != nil {
-		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	t, err := api.signAdminJWT(claims)
+	if err != nil {
+		return err
 	}
 
-	return api.SuccessResponse(req, c)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"totpRequired": true,
+		"mfaToken":     t,
+	})
 
 }
 
-func (api *API) adminGetUsers(c echo.Context) error {
+// The second step of admin login
+func (api *API) loginTOTP(mfaToken string, code string, c echo.Context) error {
 
-	user := &model.User{}
+	claims, err := api.parseAdminJWT(mfaToken)
+	if err != nil {
+		return echo.ErrUnauthorized
+	}
 
-	resp := api.service.GetUsers(user)
+	if mfa, _ := claims["mfa"].(bool); !mfa {
+		return echo.ErrUnauthorized
+	}
 
-	return api.SuccessResponse(resp, c)
+	id, _ := claims["sub"].(float64)
+	admin := api.svc(c).GetAdmin(&model.Admin{ID: int(id), Status: 1})
+	if admin == nil {
+		return echo.ErrUnauthorized
+	}
 
-}
+	// limit attempts to guess the code
+	if res := api.limiter.Allow("totp:"+strconv.Itoa(admin.ID), AdminTOTPAttemptsLimit); !res.Allowed {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(res.RetryAfter.Seconds())))
+		return echo.NewHTTPError(http.StatusTooManyRequests, "Too many two-factor authentication attempts")
+	}
 
-func (api *API) adminCreateUser(c echo.Context) error {
+	if !api.svc(c).VerifyAdminTOTP(admin, code, api.now()) {
+		api.auditLoginFailed(admin.Username, c)
+		return echo.ErrUnauthorized
+	}
 
-	req := &model.User{}
+	return api.startAdminSession(admin, c)
 
-	// bind input data
-	if err := c.Bind(req); err != nil {
-		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
-	}
+}
 
-	// generate access token
-	req.AccessToken = req.GenerateAccessToken(AccessTokenLength)
+// Creates session of admin and writes its JWT into cookie
+func (api *API) startAdminSession(admin *model.Admin, c echo.Context) error {
 
-	// validate Name, AccessToken
-	if err := api.validate.StructExcept(req, "ID"); err != nil {
-		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	session, err := api.svc(c).CreateAdminSession(admin, c.RealIP(), c.Request().UserAgent())
+	if err != nil {
+		return err
 	}
 
-	resp, err := api.service.CreateUser(req)
+	claims := jwt.MapClaims{
+		"jti":  session.JTI,
+		"sub":  admin.ID,
+		"role": admin.Role,
+		"exp":  session.ExpiresAt.Unix(),
+	}
 
+	// Generate encoded token and send it as response.
+	t, err := api.signAdminJWT(claims)
 	if err != nil {
-		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+		return err
 	}
 
-	return api.SuccessResponse(resp, c)
+	writeCookie(c, t)
+
+	c.Set(adminContextKey, admin)
+	api.audit(c, model.AuditActionLogin, "session", session.ID, nil, nil)
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"token": t,
+	})
 
 }
 
-func (api *API) adminGetUser(c echo.Context) error {
+// Signs admin JWT by the current signing key
+func (api *API) signAdminJWT(claims jwt.MapClaims) (string, error) {
 
-	userID, err := strconv.Atoi(c.Param("id"))
+	key, err := api.service.GetSigningJWTKey()
 	if err != nil {
-		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+		return "", err
 	}
 
-	user := api.service.GetUser(&model.User{ID: userID})
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = strconv.Itoa(key.ID)
 
-	return api.SuccessResponse(user, c)
+	return token.SignedString([]byte(key.Secret))
 
 }
 
-func (api *API) adminUpdateUser(c echo.Context) error {
+// Parses admin JWT signed by any known key
+func (api *API) parseAdminJWT(t string) (jwt.MapClaims, error) {
 
-	userID, err := strconv.Atoi(c.Param("id"))
+	token, err := jwt.Parse(t, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("Unexpected jwt signing method=%v", t.Header["alg"])
+		}
+		kid, _ := t.Header["kid"].(string)
+		id, _ := strconv.Atoi(kid)
+		key := api.service.GetJWTKey(id)
+		if key == nil {
+			return nil, fmt.Errorf("Unknown jwt key id=%s", kid)
+		}
+		return []byte(key.Secret), nil
+	})
 	if err != nil {
-		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+		return nil, err
 	}
 
-	user := api.service.GetUser(&model.User{ID: userID})
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("Invalid jwt")
+	}
 
-	// bind input data
-	if err := c.Bind(user); err != nil {
-		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
+	return claims, nil
+
+}
+
+// Middleware authenticating admin by JWT from cookie. Token is accepted only if it's signed
+// by the known key and its session is active, so revoked sessions and disabled admins lose access immediately
+func (api *API) adminAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		cookie, err := c.Cookie("token")
+		if err != nil || cookie.Value == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "missing or malformed jwt")
+		}
+
+		claims, err := api.parseAdminJWT(cookie.Value)
+		if err != nil {
+			deleteCookie(c)
+			return echo.ErrUnauthorized
+		}
+
+		// mfa tokens have no session, so they are rejected here
+		jti, _ := claims["jti"].(string)
+		id, _ := claims["sub"].(float64)
+
+		session := api.svc(c).CheckAdminSession(jti)
+		if session == nil || session.AdminID != int(id) {
+			deleteCookie(c)
+			return echo.ErrUnauthorized
+		}
+
+		admin := api.svc(c).GetAdmin(&model.Admin{ID: session.AdminID, Status: 1})
+		if admin == nil {
+			deleteCookie(c)
+			return echo.ErrUnauthorized
+		}
+
+		c.Set(adminContextKey, admin)
+		c.Set(adminSessionContextKey, session)
+
+		return next(c)
+	}
+}
+
+// Returns session of admin authenticated by JWT middleware
+func currentAdminSession(c echo.Context) *model.AdminSession {
+	session, _ := c.Get(adminSessionContextKey).(*model.AdminSession)
+	return session
+}
+
+// Returns admin authenticated by JWT middleware
+func currentAdmin(c echo.Context) *model.Admin {
+	admin, _ := c.Get(adminContextKey).(*model.Admin)
+	return admin
+}
+
+// Middleware allowing request only if admin has the role
+func (api *API) requireRole(role string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			admin := currentAdmin(c)
+			if admin == nil || !admin.HasRole(role) {
+				err := fmt.Errorf("Role '%s' is required", role)
+				return api.ErrorResponse(errors.New(errors.AccessDeniedError, err), c)
+			}
+			return next(c)
+		}
 	}
+}
 
-	if err := api.service.UpdateUser(user); err != nil {
+func (api *API) adminGetMe(c echo.Context) error {
+
+	return api.SuccessResponse(currentAdmin(c), c)
+
+}
+
+func (api *API) adminEnrollTOTP(c echo.Context) error {
+
+	admin := currentAdmin(c)
+
+	secret, err := api.svc(c).EnrollAdminTOTP(admin)
+	if err != nil {
 		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
 	}
 
-	return api.SuccessResponse(user, c)
+	return api.SuccessResponse(map[string]string{
+		"secret": secret,
+		"uri":    totp.ProvisioningURI(TOTPIssuer, admin.Username, secret),
+	}, c)
 
 }
 
-func (api *API) adminRotateUserToken(c echo.Context) error {
+func (api *API) adminEnableTOTP(c echo.Context) error {
 
-	userID, err := strconv.Atoi(c.Param("id"))
+	admin := currentAdmin(c)
+
+	codes, err := api.svc(c).EnableAdminTOTP(admin, c.FormValue("code"), api.now())
 	if err != nil {
 		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
 	}
 
-	user := api.service.GetUser(&model.User{ID: userID})
+	api.audit(c, model.AuditActionTOTPEnable, model.AuditActorAdmin, admin.ID, nil, nil)
+
+	return api.SuccessResponse(map[string][]string{
+		"recoveryCodes": codes,
+	}, c)
+
+}
+
+func (api *API) adminDisableTOTP(c echo.Context) error {
+
+	admin := currentAdmin(c)
 
-	user.AccessToken = user.GenerateAccessToken(AccessTokenLength)
+	if err := api.checkTOTPCode(admin, c); err != nil {
+		return api.ErrorResponse(errors.New(errors.AccessDeniedError, err), c)
+	}
 
-	if err := api.service.UpdateUser(user); err != nil {
+	if err := api.svc(c).DisableAdminTOTP(admin); err != nil {
 		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
 	}
 
-	return api.SuccessResponse(user, c)
+	api.audit(c, model.AuditActionTOTPDisable, model.AuditActorAdmin, admin.ID, nil, nil)
+
+	return api.SuccessResponse(admin, c)
 
 }
 
-func (api *API) adminDeleteUser(c echo.Context) error {
+func (api *API) adminRegenerateRecoveryCodes(c echo.Context) error {
 
-	req := &model.User{}
+	admin := currentAdmin(c)
 
-	// bind input data
-	if err := c.Bind(req); err != nil {
-		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
+	if err := api.checkTOTPCode(admin, c); err != nil {
+		return api.ErrorResponse(errors.New(errors.AccessDeniedError, err), c)
 	}
 
-	if err := api.service.DeleteUser(req); err != nil {
+	codes, err := api.svc(c).RegenerateAdminRecoveryCodes(admin)
+	if err != nil {
 		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
 	}
 
-	return api.SuccessResponse(req, c)
+	api.audit(c, model.AuditActionTOTPRecovery, model.AuditActorAdmin, admin.ID, nil, nil)
 
-}
+	return api.SuccessResponse(map[string][]string{
+		"recoveryCodes": codes,
+	}, c)
 
-// Get API user info
-func (api *API) getUser(c echo.Context) error {
-	resp, _ := api.user.FilterStruct([]string{"api"})
-	return c.JSON(http.StatusOK, &resp)
 }
 
-// Get API version
-func (api *API) index(c echo.Context) error {
-	return api.SuccessResponse(api.GetAPIInfo(), c)
+// Superadmin resets two-factor authentication of admin, who lost TOTP device & recovery codes
+func (api *API) adminResetAdminTOTP(c echo.Context) error {
+
+	adminID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	admin := api.svc(c).GetAdmin(&model.Admin{ID: adminID})
+	if admin == nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, fmt.Errorf("Admin %d not found", adminID)), c)
+	}
+
+	if err := api.svc(c).DisableAdminTOTP(admin); err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	api.audit(c, model.AuditActionAdminResetTOTP, model.AuditActorAdmin, admin.ID, nil, nil)
+
+	return api.SuccessResponse(admin, c)
+
 }
 
-// Check API user limit
-func (api *API) checkUserLimit(action string, c echo.Context) error {
+// Checks TOTP or recovery code from request before changing two-factor authentication settings
+func (api *API) checkTOTPCode(admin *model.Admin, c echo.Context) error {
 
-	var usageCost int
+	if !admin.TOTPEnabled {
+		return fmt.Errorf("Two-factor authentication is not enabled")
+	}
 
-	switch action {
-	case model.QueueActionChain:
-		usageCost = 2
-	case model.QueueActionEntry:
-		usageCost = 1
+	if res := api.limiter.Allow("totp:"+strconv.Itoa(admin.ID), AdminTOTPAttemptsLimit); !res.Allowed {
+		return fmt.Errorf("Too many two-factor authentication attempts")
 	}
 
-	if api.user.UsageLimit != 0 && api.user.UsageLimit-api.user.Usage < usageCost {
-		return fmt.Errorf("Writes limit (%d writes) is exceeded for API user '%s'", api.user.UsageLimit, api.user.Name)
+	if !api.svc(c).VerifyAdminTOTP(admin, c.FormValue("code"), api.now()) {
+		return fmt.Errorf("Invalid two-factor authentication code")
 	}
 
 	return nil
 
 }
 
-// Success API response
-func (api *API) SuccessResponse(res interface{}, c echo.Context) error {
-	resp := &SuccessResponse{
-		Result: res,
-	}
-	return c.JSON(http.StatusOK, resp)
+func (api *API) adminGetAdmins(c echo.Context) error {
+
+	resp := api.svc(c).GetAdmins(&model.Admin{})
+
+	return api.SuccessResponse(resp, c)
+
+}
+
+func (api *API) adminCreateAdmin(c echo.Context) error {
+
+	req := &model.Admin{}
+
+	// bind input data
+	if err := c.Bind(req); err != nil {
+		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
+	}
+
+	// validate Username, Password, Role
+	if err := api.validate.StructPartial(req, "Username", "Password", "Role"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	resp, err := api.svc(c).CreateAdmin(req)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	api.audit(c, model.AuditActionAdminCreate, model.AuditActorAdmin, resp.ID, nil, resp)
+
+	return api.SuccessResponse(resp, c)
+
+}
+
+func (api *API) adminUpdateAdmin(c echo.Context) error {
+
+	adminID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	admin := api.svc(c).GetAdmin(&model.Admin{ID: adminID})
+	if admin == nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, fmt.Errorf("Admin %d not found", adminID)), c)
+	}
+
+	before := *admin
+
+	// bind input data
+	if err := c.Bind(admin); err != nil {
+		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
+	}
+	admin.ID = adminID
+
+	// validate Username, Password, Role
+	if err := api.validate.StructPartial(admin, "Username", "Password", "Role"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	// superadmin can't lock themselves out
+	if admin.ID == currentAdmin(c).ID && (admin.Role != model.AdminRoleSuperadmin || admin.Status == 0) {
+		return api.ErrorResponse(errors.New(errors.ValidationError, fmt.Errorf("You can't downgrade or disable yourself")), c)
+	}
+
+	if err := api.svc(c).UpdateAdmin(admin); err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	api.audit(c, model.AuditActionAdminUpdate, model.AuditActorAdmin, admin.ID, &before, admin)
+
+	return api.SuccessResponse(admin, c)
+
+}
+
+func (api *API) adminDeleteAdmin(c echo.Context) error {
+
+	adminID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	if adminID == currentAdmin(c).ID {
+		return api.ErrorResponse(errors.New(errors.ValidationError, fmt.Errorf("You can't delete yourself")), c)
+	}
+
+	admin := api.svc(c).GetAdmin(&model.Admin{ID: adminID})
+	if admin == nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, fmt.Errorf("Admin %d not found", adminID)), c)
+	}
+
+	if err := api.svc(c).DeleteAdmin(admin); err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	api.audit(c, model.AuditActionAdminDelete, model.AuditActorAdmin, admin.ID, admin, nil)
+
+	return api.SuccessResponse(admin, c)
+
+}
+
+func (api *API) adminLogout(c echo.Context) error {
+
+	if session := currentAdminSession(c); session != nil {
+		if err := api.svc(c).RevokeAdminSession(session); err != nil {
+			return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+		}
+		api.audit(c, model.AuditActionLogout, "session", session.ID, nil, nil)
+	}
+
+	deleteCookie(c)
+
+	return c.JSON(http.StatusOK, map[string]bool{
+		"ok": true,
+	})
+
+}
+
+func (api *API) adminGetSessions(c echo.Context) error {
+
+	resp := api.svc(c).GetAdminSessions()
+
+	if current := currentAdminSession(c); current != nil {
+		for _, session := range resp {
+			session.Current = session.ID == current.ID
+		}
+	}
+
+	return api.SuccessResponse(resp, c)
+
+}
+
+func (api *API) adminDeleteSession(c echo.Context) error {
+
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	session := &model.AdminSession{ID: sessionID}
+	if err := api.svc(c).RevokeAdminSession(session); err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	api.audit(c, model.AuditActionSessionRevoke, "session", session.ID, nil, nil)
+
+	return api.SuccessResponse(session, c)
+
+}
+
+func (api *API) adminRotateJWTKey(c echo.Context) error {
+
+	resp, err := api.svc(c).RotateJWTKey()
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	api.audit(c, model.AuditActionJWTKeyRotate, "jwt_key", resp.ID, nil, nil)
+
+	return api.SuccessResponse(resp, c)
+
+}
+
+func (api *API) adminGetSettings(c echo.Context) error {
+
+	return c.JSON(http.StatusOK, api.currentConf())
+
+}
+
+// Validates & saves settings, then applies them without restart
+func (api *API) adminUpdateSettings(c echo.Context) error {
+
+	api.applyMu.Lock()
+	defer api.applyMu.Unlock()
+
+	oldConf := api.currentConf()
+
+	newConf := &config.Config{}
+	copier.Copy(newConf, oldConf)
+
+	if err := c.Bind(newConf); err != nil {
+		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
+	}
+
+	if err := newConf.Validate(); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	// running config includes environment & flags overrides, so only changed fields are saved into the file
+	fileConf, err := config.LoadFile(api.configFile)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+	fileConf.SetChanged(oldConf, newConf)
+
+	// config is saved only if it was applied, so the file never contains config that failed to apply
+	restartRequired, err := api.applyConfig(newConf)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	if err := config.UpdateConfig(api.configFile, fileConf); err != nil {
+		// running config is rolled back to match the file
+		if _, rollbackErr := api.applyConfig(oldConf); rollbackErr != nil {
+			log.Error("Rolling back config failed: ", rollbackErr)
+		}
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	api.audit(c, model.AuditActionSettingsUpdate, "settings", nil, oldConf.Redacted(), newConf.Redacted())
+
+	return c.JSON(http.StatusOK, map[string]bool{
+		"ok":              true,
+		"restartRequired": restartRequired,
+	})
+
+}
+
+// Reloads settings from config file
+func (api *API) adminReloadSettings(c echo.Context) error {
+
+	api.applyMu.Lock()
+	defer api.applyMu.Unlock()
+
+	oldConf := api.currentConf()
+
+	newConf, err := config.NewConfig(api.configFile)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	restartRequired, err := api.applyConfig(newConf)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	api.audit(c, model.AuditActionSettingsReload, "settings", nil, oldConf.Redacted(), newConf.Redacted())
+
+	return c.JSON(http.StatusOK, map[string]bool{
+		"ok":              true,
+		"restartRequired": restartRequired,
+	})
+
+}
+
+// Returns audit log entries filtered by actor, action, target & time range
+func (api *API) adminGetAudit(c echo.Context) error {
+
+	req := &model.AuditLog{
+		ActorType:  c.QueryParam("actorType"),
+		ActorName:  c.QueryParam("actorName"),
+		Action:     c.QueryParam("action"),
+		TargetType: c.QueryParam("targetType"),
+		TargetID:   c.QueryParam("targetId"),
+	}
+
+	if c.QueryParam("actorId") != "" {
+		actorID, err := strconv.Atoi(c.QueryParam("actorId"))
+		if err != nil {
+			return api.ErrorResponse(errors.New(errors.ValidationError, fmt.Errorf("'actorId' expected to be an integer")), c)
+		}
+		req.ActorID = actorID
+	}
+
+	var from, to *time.Time
+	for name, t := range map[string]**time.Time{"from": &from, "to": &to} {
+		if c.QueryParam(name) == "" {
+			continue
+		}
+		v, err := time.Parse(time.RFC3339, c.QueryParam(name))
+		if err != nil {
+			return api.ErrorResponse(errors.New(errors.ValidationError, fmt.Errorf("'%s' expected to be RFC3339 time", name)), c)
+		}
+		*t = &v
+	}
+
+	start, limit, sort, err := api.GetPaginationParams(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	resp, total := api.svc(c).GetAuditLogs(req, from, to, start, limit, sort)
+
+	return api.SuccessResponsePagination(resp, total, c)
+
+}
+
+func (api *API) adminGetEC(c echo.Context) error {
+
+	ecAddress := model.GetEC(c.Param("esaddress"))
+	if ecAddress == nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, fmt.Errorf("Invalid Es address")), c)
+	}
+
+	_, span := tracing.StartFactomd(c.Request().Context(), "entry-credit-balance")
+	ecAddress.GetBalanceFromFactom()
+	span.End()
+
+	return api.SuccessResponse(ecAddress, c)
+
+}
+
+func (api *API) adminRandomEC(c echo.Context) error {
+
+	ecAddress := model.GenerateEC()
+	if ecAddress == nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, fmt.Errorf("EC keypair generation error")), c)
+	}
+
+	_, span := tracing.StartFactomd(c.Request().Context(), "entry-credit-balance")
+	ecAddress.GetBalanceFromFactom()
+	span.End()
+
+	return api.SuccessResponse(ecAddress, c)
+
+}
+
+func (api *API) adminIndex(c echo.Context) error {
+
+	return api.SuccessResponse(api.GetAPIInfo(), c)
+
+}
+
+func (api *API) adminGetQueue(c echo.Context) error {
+
+	resp := api.svc(c).GetQueue(&model.Queue{})
+
+	return api.SuccessResponse(resp, c)
+
+}
+
+// Returns progress of updates parser, chains indexer & history parsing
+func (api *API) adminGetSync(c echo.Context) error {
+
+	resp := api.svc(c).GetSyncProgress()
+
+	conf := api.currentConf()
+	resp.SyncMode = conf.Parser.SyncMode
+	resp.Index = conf.Parser.Index
+
+	return api.SuccessResponse(resp, c)
+
+}
+
+func (api *API) adminGetPool(c echo.Context) error {
+
+	resp := api.svc(c).GetPoolState()
+
+	return api.SuccessResponse(resp, c)
+
+}
+
+// Sets history sync priority of the chain, resumes sync if it was cancelled
+func (api *API) adminUpdatePool(c echo.Context) error {
+
+	req := &model.PoolItem{}
+
+	// bind input data
+	if err := c.Bind(req); err != nil {
+		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
+	}
+
+	chain := &model.Chain{ChainID: c.Param("chainid")}
+	if err := api.validate.StructPartial(chain, "ChainID"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+	req.ChainID = chain.ChainID
+
+	if err := api.svc(c).SetChainSyncPriority(chain, req.Priority); err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	api.audit(c, model.AuditActionChainPrioritize, "chain", chain.ChainID, nil, req)
+
+	return api.SuccessResponse(req, c)
+
+}
+
+// Cancels history sync of the chain
+func (api *API) adminDeletePool(c echo.Context) error {
+
+	chain := &model.Chain{ChainID: c.Param("chainid")}
+	if err := api.validate.StructPartial(chain, "ChainID"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	if err := api.svc(c).CancelChainSync(chain); err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	resp := &model.PoolItem{ChainID: chain.ChainID, Priority: model.ChainPriorityCancelled}
+
+	api.audit(c, model.AuditActionChainCancelSync, "chain", chain.ChainID, nil, resp)
+
+	return api.SuccessResponse(resp, c)
+
+}
+
+// Pins chain, so it's synced & updated without users
+func (api *API) adminPinChain(c echo.Context) error {
+
+	return api.setChainPinned(c, true)
+
+}
+
+// Unpins chain, chain without users is not updated anymore & its entries are purged by retention policy
+func (api *API) adminUnpinChain(c echo.Context) error {
+
+	return api.setChainPinned(c, false)
+
+}
+
+func (api *API) setChainPinned(c echo.Context, pinned bool) error {
+
+	chain := &model.Chain{ChainID: c.Param("chainid")}
+	if err := api.validate.StructPartial(chain, "ChainID"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	if err := api.svc(c).PinChain(chain, pinned); err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	action := model.AuditActionChainPin
+	if !pinned {
+		action = model.AuditActionChainUnpin
+	}
+	api.audit(c, action, "chain", chain.ChainID, nil, nil)
+
+	return api.SuccessResponse(chain, c)
+
+}
+
+func (api *API) adminDeleteQueue(c echo.Context) error {
+
+	req := &model.Queue{}
+
+	// bind input data
+	if err := c.Bind(req); err != nil {
+		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
+	}
+
+	if err := api.svc(c).DeleteQueue(req); err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	api.audit(c, model.AuditActionQueueDelete, "queue", req.ID, req, nil)
+
+	return api.SuccessResponse(req, c)
+
+}
+
+func (api *API) adminGetUsers(c echo.Context) error {
+
+	user := &model.User{}
+
+	resp := api.svc(c).GetUsers(user)
+
+	return api.SuccessResponse(resp, c)
+
+}
+
+func (api *API) adminCreateUser(c echo.Context) error {
+
+	req := &model.User{}
+
+	// bind input data
+	if err := c.Bind(req); err != nil {
+		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
+	}
+
+	// generate access token
+	req.AccessToken = req.GenerateAccessToken(AccessTokenLength)
+
+	// validate Name, AccessToken
+	if err := api.validate.StructExcept(req, "ID"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	resp, err := api.svc(c).CreateUser(req)
+
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	api.audit(c, model.AuditActionUserCreate, model.AuditActorUser, resp.ID, nil, resp)
+
+	return api.SuccessResponse(resp, c)
+
+}
+
+func (api *API) adminGetUser(c echo.Context) error {
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	user := api.svc(c).GetUser(&model.User{ID: userID})
+
+	return api.SuccessResponse(user, c)
+
+}
+
+func (api *API) adminUpdateUser(c echo.Context) error {
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	user := api.svc(c).GetUser(&model.User{ID: userID})
+	if user == nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, fmt.Errorf("User %d not found", userID)), c)
+	}
+
+	before := *user
+
+	// bind input data
+	if err := c.Bind(user); err != nil {
+		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
+	}
+
+	// validate UsagePeriod, UsagePeriodDays
+	if err := api.validate.StructPartial(user, "UsagePeriod", "UsagePeriodDays"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+	if err := user.ValidateUsagePeriod(); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	if err := api.svc(c).UpdateUser(user); err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	api.audit(c, model.AuditActionUserUpdate, model.AuditActorUser, user.ID, &before, user)
+
+	return api.SuccessResponse(user, c)
+
+}
+
+func (api *API) adminRotateUserToken(c echo.Context) error {
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	user := api.svc(c).GetUser(&model.User{ID: userID})
+	if user == nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, fmt.Errorf("User %d not found", userID)), c)
+	}
+
+	user, err = api.svc(c).RotateUserToken(user)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	api.audit(c, model.AuditActionUserRotateToken, model.AuditActorUser, user.ID, nil, nil)
+
+	return api.SuccessResponse(user, c)
+
+}
+
+func (api *API) adminGetUserUsage(c echo.Context) error {
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	resp := api.svc(c).GetUsageHistory(&model.User{ID: userID})
+
+	return api.SuccessResponse(resp, c)
+
+}
+
+func (api *API) adminGetUserKeys(c echo.Context) error {
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	resp := api.svc(c).GetAPIKeys(&model.User{ID: userID})
+
+	return api.SuccessResponse(resp, c)
+
+}
+
+func (api *API) adminCreateUserKey(c echo.Context) error {
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	user := api.svc(c).GetUser(&model.User{ID: userID})
+	if user == nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, fmt.Errorf("User %d not found", userID)), c)
+	}
+
+	req := &model.APIKey{}
+
+	// bind input data
+	if err := c.Bind(req); err != nil {
+		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
+	}
+
+	// validate Name, Scopes, ChainIDs, ExtIDNamespaces
+	if err := api.validate.StructPartial(req, "Name", "Scopes", "ChainIDs", "ExtIDNamespaces"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	if req.IsExpired() {
+		return api.ErrorResponse(errors.New(errors.ValidationError, fmt.Errorf("'expiresAt' should be in the future")), c)
+	}
+
+	resp, err := api.svc(c).CreateAPIKey(req, user)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	api.audit(c, model.AuditActionAPIKeyCreate, "apikey", resp.ID, nil, resp)
+
+	return api.SuccessResponse(resp, c)
+
+}
+
+func (api *API) adminDeleteUserKey(c echo.Context) error {
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	keyID, err := strconv.Atoi(c.Param("keyid"))
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	req := &model.APIKey{ID: keyID}
+
+	if err := api.svc(c).RevokeAPIKey(req, &model.User{ID: userID}); err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	api.audit(c, model.AuditActionAPIKeyRevoke, "apikey", req.ID, nil, nil)
+
+	return api.SuccessResponse(req, c)
+
+}
+
+func (api *API) adminDeleteUser(c echo.Context) error {
+
+	req := &model.User{}
+
+	// bind input data
+	if err := c.Bind(req); err != nil {
+		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
+	}
+
+	before := api.svc(c).GetUser(&model.User{ID: req.ID})
+
+	if err := api.svc(c).DeleteUser(req); err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	api.audit(c, model.AuditActionUserDelete, model.AuditActorUser, req.ID, before, nil)
+
+	return api.SuccessResponse(req, c)
+
+}
+
+// Get API user info
+func (api *API) getUser(c echo.Context) error {
+	user := currentUser(c)
+	user.SetUsageRemaining()
+	resp, _ := user.FilterStruct([]string{"api"})
+	return c.JSON(http.StatusOK, &resp)
+}
+
+// Get API version
+func (api *API) index(c echo.Context) error {
+	return api.SuccessResponse(api.GetAPIInfo(), c)
+}
+
+// Liveness probe: API process is running and serving requests
+func (api *API) healthz(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"status": model.HealthOK})
+}
+
+// Readiness probe: reports dependencies, 503 if API can't serve requests
+func (api *API) readyz(c echo.Context) error {
+
+	readiness := api.svc(c).CheckReadiness()
+
+	if !readiness.IsReady() {
+		return c.JSON(http.StatusServiceUnavailable, readiness)
+	}
+
+	return c.JSON(http.StatusOK, readiness)
+
+}
+
+// Expose Prometheus metrics, protected by bearer token from config
+func (api *API) metrics(c echo.Context) error {
+
+	token := api.currentConf().API.MetricsToken
+	if token == "" {
+		return echo.ErrNotFound
+	}
+
+	auth := c.Request().Header.Get(echo.HeaderAuthorization)
+	if subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+token)) != 1 {
+		return echo.ErrUnauthorized
+	}
+
+	metrics.Handler().ServeHTTP(c.Response(), c.Request())
+	return nil
+
+}
+
+// Middleware assigning ID to the request: X-Request-ID header of the request or random one.
+// ID is returned in X-Request-ID header & carried by request context to service & store logs
+func (api *API) requestID(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		req := c.Request()
+
+		id := req.Header.Get(echo.HeaderXRequestID)
+		if !requestIDRegexp.MatchString(id) {
+			id = logging.NewRequestID()
+		}
+
+		c.Response().Header().Set(echo.HeaderXRequestID, id)
+		c.SetRequest(req.WithContext(logging.WithRequestID(req.Context(), id)))
+
+		return next(c)
+	}
+}
+
+// Middleware logging requests with status & latency
+func (api *API) logRequest(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		start := time.Now()
+
+		// error is handled here, so status is known
+		if err := next(c); err != nil {
+			c.Error(err)
+		}
+
+		logger(c).WithFields(log.Fields{
+			logging.FieldComponent: "api",
+			logging.FieldMethod:    c.Request().Method,
+			logging.FieldURI:       c.Request().RequestURI,
+			logging.FieldRoute:     c.Path(),
+			logging.FieldStatus:    c.Response().Status,
+			logging.FieldLatency:   float64(time.Since(start)) / float64(time.Millisecond),
+			logging.FieldIP:        c.RealIP(),
+		}).Info("API request")
+
+		return nil
+	}
+}
+
+// Returns logger, which logs carry ID of the request
+func logger(c echo.Context) *log.Entry {
+	return logging.FromContext(c.Request().Context())
+}
+
+// Returns service, which logs carry ID of the request
+func (api *API) svc(c echo.Context) service.Service {
+	return api.service.WithContext(c.Request().Context())
+}
+
+// Middleware counting requests & their latency by route template & status
+func (api *API) measure(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		start := time.Now()
+
+		// error is handled here, so status is known; it's still returned to the outer middleware,
+		// which handler skips already committed response
+		err := next(c)
+		if err != nil && !c.Response().Committed {
+			c.Error(err)
+		}
+
+		status := c.Response().Status
+		route := routeTemplate(c)
+		method := c.Request().Method
+		code := strconv.Itoa(status)
+
+		metrics.HTTPRequests.WithLabelValues(method, route, code).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(method, route, code).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+// Middleware tracing requests: span continues the trace from W3C traceparent header
+// and is carried by request context to service & store spans
+func (api *API) traceRequest(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		req := c.Request()
+		ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+		ctx, span := tracing.Start(ctx, req.Method, trace.SpanKindServer,
+			attribute.String(tracing.AttrHTTPMethod, req.Method),
+			attribute.String(tracing.AttrRequestID, logging.RequestID(ctx)),
+		)
+		defer span.End()
+		c.SetRequest(req.WithContext(ctx))
+
+		// error is handled here, so status is known
+		if err := next(c); err != nil {
+			c.Error(err)
+		}
+
+		status := c.Response().Status
+		route := routeTemplate(c)
+
+		span.SetName(req.Method + " " + route)
+		span.SetAttributes(attribute.String(tracing.AttrHTTPRoute, route), attribute.Int(tracing.AttrHTTPStatusCode, status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+
+		return nil
+	}
+}
+
+// Returns route template of the request, e.g. /v1/chains/:chainid.
+// Router leaves request path as route if nothing matched, it's not used to keep metrics & spans names bounded
+func routeTemplate(c echo.Context) string {
+
+	route := c.Path()
+	status := c.Response().Status
+	if route == "" || ((status == http.StatusNotFound || status == http.StatusMethodNotAllowed) && route == c.Request().URL.Path) {
+		route = "unmatched"
+	}
+
+	return route
+
+}
+
+// Check API user limit
+func (api *API) checkUserLimit(action string, c echo.Context) error {
+
+	var usageCost int
+
+	switch action {
+	case model.QueueActionChain:
+		usageCost = 2
+	case model.QueueActionEntry:
+		usageCost = 1
+	}
+
+	user := currentUser(c)
+
+	if user.UsageLimit != 0 && user.UsageLimit-user.Usage < usageCost {
+		if end := user.UsagePeriodEnd(); end != nil {
+			return fmt.Errorf("Writes limit (%d writes per %s period) is exceeded for API user '%s' until %s", user.UsageLimit, user.UsagePeriod, user.Name, end.Format(time.RFC3339))
+		}
+		return fmt.Errorf("Writes limit (%d writes) is exceeded for API user '%s'", user.UsageLimit, user.Name)
+	}
+
+	return nil
+
+}
+
+// Returns API user authenticated by KeyAuth middleware
+func currentUser(c echo.Context) *model.User {
+	user, _ := c.Get(userContextKey).(*model.User)
+	return user
+}
+
+// Middleware allowing request only if API key of the user is granted the scope
+func (api *API) requireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := currentUser(c).APIKey
+			if key != nil && !key.HasScope(scope) {
+				err := fmt.Errorf("API key '%s' is not granted '%s' scope", key.Name, scope)
+				return api.ErrorResponse(errors.New(errors.AccessDeniedError, err), c)
+			}
+			return next(c)
+		}
+	}
+}
+
+// Middleware limiting requests rate of the user for the group of endpoints
+func (api *API) rateLimit(group string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+
+			user := currentUser(c)
+
+			limit := user.GetRateLimit(group)
+			if limit == 0 {
+				switch group {
+				case model.RateLimitGroupRead:
+					limit = api.currentConf().RateLimit.Read
+				case model.RateLimitGroupWrite:
+					limit = api.currentConf().RateLimit.Write
+				case model.RateLimitGroupProxy:
+					limit = api.currentConf().RateLimit.Proxy
+				}
+			}
+
+			// negative limit means no limit
+			if limit <= 0 {
+				return next(c)
+			}
+
+			res := api.limiter.Allow(strconv.Itoa(user.ID)+":"+group, limit)
+
+			h := c.Response().Header()
+			h.Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("X-RateLimit-Reset", strconv.Itoa(int(res.Reset.Seconds())))
+
+			if !res.Allowed {
+				h.Set("Retry-After", strconv.Itoa(int(res.RetryAfter.Seconds())))
+				err := fmt.Errorf("Rate limit (%d %s requests per minute) is exceeded for API user '%s'", limit, group, user.Name)
+				return api.ErrorResponse(errors.New(errors.RateLimitError, err), c)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// Check if API key of the user has access to the chain
+func (api *API) checkChainAccess(chain *model.Chain, c echo.Context) error {
+
+	key := currentUser(c).APIKey
+	if key == nil || !key.IsRestricted() {
+		return nil
+	}
+
+	// chain namespace is checked by its first ExtID, so take it from local DB if not provided
+	if len(chain.ExtIDs) == 0 && chain.ChainID != "" {
+		for _, localChain := range api.svc(c).GetChains(&model.Chain{ChainID: chain.ChainID}) {
+			chain = localChain
+		}
+	}
+
+	if chain.ChainID == "" || !key.AllowsChain(chain) {
+		return fmt.Errorf("API key '%s' has no access to chain %s", key.Name, chain.ChainID)
+	}
+
+	return nil
+
+}
+
+// checkEntryAccess checks access to chain of entry before entry is fetched, bound or stored.
+// Chain of unknown entry can't be resolved without side effects, so it's denied for restricted keys
+func (api *API) checkEntryAccess(entry *model.Entry, c echo.Context) error {
+
+	key := currentUser(c).APIKey
+	if key == nil || !key.IsRestricted() {
+		return nil
+	}
+
+	chain := api.svc(c).GetEntryChain(entry)
+	if chain == nil {
+		return fmt.Errorf("API key '%s' has no access to entry %s", key.Name, entry.EntryHash)
+	}
+
+	return api.checkChainAccess(chain, c)
+
+}
+
+// Success API response
+func (api *API) SuccessResponse(res interface{}, c echo.Context) error {
+	resp := &SuccessResponse{
+		Result: res,
+	}
+	return c.JSON(http.StatusOK, resp)
 }
 
 // Accepted API response
@@ -540,13 +1886,18 @@ func (api *API) ErrorResponse(err *errors.Error, c echo.Context) error {
 	// factomd error codes will be lt 0
 	// error codes from 1400 to 1499 will be lt 0
 	// error codes from 1500 will be gte 0
-	if err.Code-1500 < 0 {
+	switch {
+	case err.Code == errors.AccessDeniedError:
+		HTTPResponseCode = http.StatusForbidden
+	case err.Code == errors.RateLimitError:
+		HTTPResponseCode = http.StatusTooManyRequests
+	case err.Code-1500 < 0:
 		HTTPResponseCode = http.StatusBadRequest
-	} else {
+	default:
 		HTTPResponseCode = http.StatusInternalServerError
 	}
 
-	log.Error(err.Error())
+	logger(c).Error(err.Error())
 	return c.JSON(HTTPResponseCode, resp)
 }
 
@@ -564,7 +1915,7 @@ func (api *API) GetPaginationParams(c echo.Context) (int, int, string, error) {
 		start, err = strconv.Atoi(c.QueryParam("start"))
 		if err != nil {
 			err = fmt.Errorf("'start' expected to be an integer, '%s' received", c.QueryParam("start"))
-			log.Error(err)
+			logger(c).Error(err)
 			return 0, 0, sort, err
 		}
 	}
@@ -573,7 +1924,7 @@ func (api *API) GetPaginationParams(c echo.Context) (int, int, string, error) {
 		limit, err = strconv.Atoi(c.QueryParam("limit"))
 		if err != nil {
 			err = fmt.Errorf("'limit' expected to be an integer, '%s' received", c.QueryParam("limit"))
-			log.Error(err)
+			logger(c).Error(err)
 			return 0, 0, sort, err
 		}
 	}
@@ -600,6 +1951,11 @@ func (api *API) createChain(c echo.Context) error {
 	req := &model.Chain{}
 	callback := &model.Callback{}
 
+	encoding, err := requestEncoding(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
 	// if JSON request, parse Content from it
 	body, err := bodyToJSON(c)
 	if err == nil {
@@ -613,157 +1969,411 @@ func (api *API) createChain(c echo.Context) error {
 		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
 	}
 
-	log.Debug("Validating input data")
+	logger(c).Debug("Validating input data")
+
+	req, err = req.FromEncoding(encoding)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
 
 	// validate ExtIDs, Content
 	if err := api.validate.StructExcept(req, "ChainID"); err != nil {
 		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
 	}
 
+	// check if API key has access to the new chain
+	if err := api.checkChainAccess(&model.Chain{ChainID: req.Base64Decode().ID(), ExtIDs: req.ExtIDs}, c); err != nil {
+		return api.ErrorResponse(errors.New(errors.AccessDeniedError, err), c)
+	}
+
 	// if received callback_url, then validate it
 	if c.QueryParam("callback_url") != "" {
 
 		callback.URL = c.QueryParam("callback_url")
 
-		log.Debug("Validating callback URL ", callback.URL)
+		logger(c).Debug("Validating callback URL ", callback.URL)
 
 		// validate Callback URL
 		if err := api.validate.StructPartial(callback, "URL"); err != nil {
 			return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
 		}
 
-	}
+	}
+
+	chain, err := api.svc(c).CreateChain(req, currentUser(c))
+
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	// if callback needed, create it
+	if callback.URL != "" {
+		err = api.svc(c).CreateCallback(chain.Base64Decode().FirstEntryHash(), callback.URL, currentUser(c))
+		if err != nil {
+			logger(c).Error("Error while creating callback")
+		}
+	}
+
+	api.audit(c, model.AuditActionChainCreate, "chain", chain.ChainID, nil, nil)
+
+	encoded, err := chain.ToEncoding(encoding)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	resp := &model.ChainWithLinks{Chain: encoded}
+	resp.Links = append(resp.Links, model.Link{Rel: "firstEntry", Href: "/entries/" + chain.Base64Decode().FirstEntryHash()})
+
+	return api.SuccessResponse(resp, c)
+}
+
+// Returns all user's chains
+func (api *API) getChains(c echo.Context) error {
+
+	chain := &model.Chain{}
+
+	encoding, err := requestEncoding(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	if c.QueryParam("status") != "" {
+		logger(c).Debug("Validating input data")
+		chain.Status = c.QueryParam("status")
+		// validate Status
+		if err := api.validate.StructPartial(chain, "Status"); err != nil {
+			return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+		}
+	}
+
+	start, limit, sort, err := api.GetPaginationParams(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.PaginationError, err), c)
+	}
+
+	resp, total := api.svc(c).GetUserChains(chain, currentUser(c), start, limit, sort)
+
+	chains, err := encodeChains(resp, encoding)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	return api.SuccessResponsePagination(chains.ConvertToChainsWithLinks(), total, c)
+
+}
+
+// Search user's chains by external id(s)
+func (api *API) searchChains(c echo.Context) error {
+
+	// Open API Chain struct
+	req := &model.Chain{}
+
+	encoding, err := requestEncoding(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	// bind input data
+	if err := c.Bind(req); err != nil {
+		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
+	}
+
+	logger(c).Debug("Validating input data")
+	req.Status = c.QueryParam("status")
+
+	req, err = req.FromEncoding(encoding)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	// validate ExtIDs
+	if err := api.validate.StructPartial(req, "ExtIDs", "Status"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	start, limit, sort, err := api.GetPaginationParams(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.PaginationError, err), c)
+	}
+
+	// chains indexer registers all chains on the network, so they are searched without binding to user
+	var resp []*model.Chain
+	var total int
+	if api.currentConf().Parser.Index {
+		resp, total = api.svc(c).SearchChains(req, currentUser(c), start, limit, sort)
+	} else {
+		resp, total = api.svc(c).SearchUserChains(req, currentUser(c), start, limit, sort)
+	}
+
+	chains, err := encodeChains(resp, encoding)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	return api.SuccessResponsePagination(chains.ConvertToChainsWithLinks(), total, c)
+
+}
+
+// Returns Factom chain by Chain ID
+func (api *API) getChain(c echo.Context) error {
+
+	req := &model.Chain{ChainID: c.Param("chainid")}
+
+	logger(c).Debug("Validating input data")
+
+	// validate ExtIDs, Content
+	if err := api.validate.StructPartial(req, "ChainID"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	if err := api.checkChainAccess(req, c); err != nil {
+		return api.ErrorResponse(errors.New(errors.AccessDeniedError, err), c)
+	}
+
+	encoding, err := requestEncoding(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	resp, err := api.svc(c).GetChain(req, currentUser(c))
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	resp, err = resp.ToEncoding(encoding)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	return api.SuccessResponse(resp.ConvertToChainWithLinks(), c)
+
+}
+
+// Unbinds chain from user, chain data is not deleted from the blockchain
+func (api *API) deleteChain(c echo.Context) error {
+
+	req := &model.Chain{ChainID: c.Param("chainid")}
+
+	logger(c).Debug("Validating input data")
+
+	// validate ChainID
+	if err := api.validate.StructPartial(req, "ChainID"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	if err := api.checkChainAccess(req, c); err != nil {
+		return api.ErrorResponse(errors.New(errors.AccessDeniedError, err), c)
+	}
+
+	if err := api.svc(c).UnbindChain(req, currentUser(c)); err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	api.audit(c, model.AuditActionChainDelete, "chain", req.ChainID, nil, nil)
+
+	return api.SuccessResponse(req, c)
+
+}
+
+// Creates entry on the Factom blockchain
+func (api *API) createEntry(c echo.Context) error {
+
+	// check user limits
+	if err := api.checkUserLimit(model.QueueActionEntry, c); err != nil {
+		return api.ErrorResponse(errors.New(errors.LimitationError, err), c)
+	}
+
+	// Open API Entry struct
+	req := &model.Entry{}
+
+	encoding, err := requestEncoding(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	// bind input data
+	if err := c.Bind(req); err != nil {
+		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
+	}
+
+	// content type is set for raw entries only
+	req.ContentType = ""
+
+	req, err = req.FromEncoding(encoding)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	return api.submitEntry(req, encoding, c)
+}
+
+// Creates entry on the Factom blockchain from raw content in request body.
+// ExtIDs are passed in X-Ext-Id headers, or as extIds fields with content part of multipart form
+func (api *API) createRawEntry(c echo.Context) error {
 
-	chain, err := api.service.CreateChain(req, api.user)
+	// check user limits
+	if err := api.checkUserLimit(model.QueueActionEntry, c); err != nil {
+		return api.ErrorResponse(errors.New(errors.LimitationError, err), c)
+	}
 
+	// body is raw content, so encoding of response is passed in query only
+	encoding, err := queryEncoding(c)
 	if err != nil {
-		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
 	}
 
-	// if callback needed, create it
-	if callback.URL != "" {
-		err = api.service.CreateCallback(chain.Base64Decode().FirstEntryHash(), callback.URL, api.user)
-		if err != nil {
-			log.Error("Error while creating callback")
-		}
+	extIDs, content, contentType, err := readRawEntry(c.Request())
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
 	}
 
-	resp := &model.ChainWithLinks{Chain: chain}
-	resp.Links = append(resp.Links, model.Link{Rel: "firstEntry", Href: "/entries/" + chain.Base64Decode().FirstEntryHash()})
+	req := &model.Entry{ChainID: c.Param("chainid"), ContentType: contentType}
+	req.Content = base64.StdEncoding.EncodeToString(content)
+	for _, extID := range extIDs {
+		req.ExtIDs = append(req.ExtIDs, base64.StdEncoding.EncodeToString(extID))
+	}
 
-	return api.SuccessResponse(resp, c)
+	return api.submitEntry(req, encoding, c)
 }
 
-// Returns all user's chains
-func (api *API) getChains(c echo.Context) error {
+// readRawEntry reads ExtIDs, content & its MIME type from raw or multipart request body.
+// Body is read up to max entry size, larger entries are rejected without reading them into memory
+func readRawEntry(r *http.Request) ([][]byte, []byte, string, error) {
 
-	chain := &model.Chain{}
+	var extIDs [][]byte
 
-	if c.QueryParam("status") != "" {
-		log.Debug("Validating input data")
-		chain.Status = c.QueryParam("status")
-		// validate Status
-		if err := api.validate.StructPartial(chain, "Status"); err != nil {
-			return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get(echo.HeaderContentType))
+
+	if mediaType != echo.MIMEMultipartForm {
+
+		for _, extID := range r.Header.Values(HeaderExtID) {
+			extIDs = append(extIDs, []byte(extID))
+		}
+
+		content, err := readLimited(r.Body, model.MaxEntrySize)
+		if err != nil {
+			return nil, nil, "", err
+		}
+
+		contentType, err := rawContentType(r.Header.Get(echo.HeaderContentType))
+		if err != nil {
+			return nil, nil, "", err
 		}
+
+		return extIDs, content, contentType, nil
+
 	}
 
-	start, limit, sort, err := api.GetPaginationParams(c)
+	mr, err := r.MultipartReader()
 	if err != nil {
-		return api.ErrorResponse(errors.New(errors.PaginationError, err), c)
+		return nil, nil, "", err
 	}
 
-	resp, total := api.service.GetUserChains(chain, api.user, start, limit, sort)
+	var content []byte
+	var contentType string
+	size := 0
 
-	chains := &model.Chains{Items: resp}
+	for {
 
-	return api.SuccessResponsePagination(chains.ConvertToChainsWithLinks(), total, c)
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, "", err
+		}
 
-}
+		// unknown fields are skipped
+		if part.FormName() != FormExtIDs && part.FormName() != FormContent {
+			continue
+		}
 
-// Search user's chains by external id(s)
-func (api *API) searchChains(c echo.Context) error {
+		data, err := readLimited(part, model.MaxEntrySize-size)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		size += len(data)
 
-	// Open API Chain struct
-	req := &model.Chain{}
+		if part.FormName() == FormExtIDs {
+			extIDs = append(extIDs, data)
+			continue
+		}
+
+		content = data
+		// text fields have no Content-Type, file parts have one
+		contentType, err = rawContentType(part.Header.Get(echo.HeaderContentType))
+		if err != nil {
+			return nil, nil, "", err
+		}
 
-	// bind input data
-	if err := c.Bind(req); err != nil {
-		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
 	}
 
-	log.Debug("Validating input data")
-	req.Status = c.QueryParam("status")
+	return extIDs, content, contentType, nil
 
-	// validate ExtIDs
-	if err := api.validate.StructPartial(req, "ExtIDs", "Status"); err != nil {
-		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
-	}
+}
 
-	start, limit, sort, err := api.GetPaginationParams(c)
+// readLimited reads r entirely, if it's not larger than limit
+func readLimited(r io.Reader, limit int) ([]byte, error) {
+
+	data, err := ioutil.ReadAll(io.LimitReader(r, int64(limit)+1))
 	if err != nil {
-		return api.ErrorResponse(errors.New(errors.PaginationError, err), c)
+		return nil, err
 	}
 
-	resp, total := api.service.SearchUserChains(req, api.user, start, limit, sort)
-
-	chains := &model.Chains{Items: resp}
+	if len(data) > limit {
+		return nil, fmt.Errorf("Entry cannot be larger than 10KB")
+	}
 
-	return api.SuccessResponsePagination(chains.ConvertToChainsWithLinks(), total, c)
+	return data, nil
 
 }
 
-// Returns Factom chain by Chain ID
-func (api *API) getChain(c echo.Context) error {
-
-	req := &model.Chain{ChainID: c.Param("chainid")}
-
-	log.Debug("Validating input data")
+// rawContentType returns MIME type of raw content to store with entry.
+// Empty & generic types are not stored, so the type is sniffed when content is read
+func rawContentType(contentType string) (string, error) {
 
-	// validate ExtIDs, Content
-	if err := api.validate.StructPartial(req, "ChainID"); err != nil {
-		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	if contentType == "" {
+		return "", nil
 	}
 
-	resp, err := api.service.GetChain(req, api.user)
+	mediaType, _, err := mime.ParseMediaType(contentType)
 	if err != nil {
-		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+		return "", fmt.Errorf("Content-Type expected to be MIME type, '%s' received", contentType)
 	}
 
-	return api.SuccessResponse(resp.ConvertToChainWithLinks(), c)
+	// curl sends form content type for --data-binary by default
+	switch mediaType {
+	case echo.MIMEOctetStream, echo.MIMEApplicationForm:
+		return "", nil
+	}
 
-}
+	return contentType, nil
 
-// Creates entry on the Factom blockchain
-func (api *API) createEntry(c echo.Context) error {
+}
 
-	// check user limits
-	if err := api.checkUserLimit(model.QueueActionEntry, c); err != nil {
-		return api.ErrorResponse(errors.New(errors.LimitationError, err), c)
-	}
+// submitEntry validates entry & creates it on behalf of current user, optionally with callback.
+// Created entry is returned in encoding
+func (api *API) submitEntry(req *model.Entry, encoding string, c echo.Context) error {
 
-	// Open API Entry & Callback structs
-	req := &model.Entry{}
 	callback := &model.Callback{}
 
-	// bind input data
-	if err := c.Bind(req); err != nil {
-		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
-	}
-
-	log.Debug("Validating input data")
+	logger(c).Debug("Validating input data")
 
 	// validate ChainID, ExtID (if exists), Content (if exists)
 	if err := api.validate.StructExcept(req, "EntryHash"); err != nil {
 		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
 	}
 
+	if err := api.checkChainAccess(req.GetChain(), c); err != nil {
+		return api.ErrorResponse(errors.New(errors.AccessDeniedError, err), c)
+	}
+
 	// if received callback_url, then validate it
 	if c.QueryParam("callback_url") != "" {
 
 		callback.URL = c.QueryParam("callback_url")
 
-		log.Debug("Validating callback URL ", callback.URL)
+		logger(c).Debug("Validating callback URL ", callback.URL)
 
 		// validate Callback URL
 		if err := api.validate.StructPartial(callback, "URL"); err != nil {
@@ -773,19 +2383,26 @@ func (api *API) createEntry(c echo.Context) error {
 	}
 
 	// Create entry
-	resp, err := api.service.CreateEntry(req, api.user)
+	resp, err := api.svc(c).CreateEntry(req, currentUser(c))
 	if err != nil {
 		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
 	}
 
+	api.audit(c, model.AuditActionEntryCreate, "entry", resp.EntryHash, nil, map[string]string{"chainId": resp.ChainID})
+
 	// if callback needed, create it
 	if callback.URL != "" {
-		err = api.service.CreateCallback(resp.EntryHash, callback.URL, api.user)
+		err = api.svc(c).CreateCallback(resp.EntryHash, callback.URL, currentUser(c))
 		if err != nil {
-			log.Error("Error while creating callback")
+			logger(c).Error("Error while creating callback")
 		}
 	}
 
+	resp, err = resp.ToEncoding(encoding)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
 	return api.SuccessResponse(resp, c)
 }
 
@@ -794,22 +2411,77 @@ func (api *API) getEntry(c echo.Context) error {
 
 	req := &model.Entry{EntryHash: c.Param("entryhash")}
 
-	log.Debug("Validating input data")
+	logger(c).Debug("Validating input data")
 
 	// validate ExtIDs, Content
 	if err := api.validate.StructPartial(req, "EntryHash"); err != nil {
 		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
 	}
 
-	resp, err := api.service.GetEntry(req, api.user)
+	encoding, err := requestEncoding(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	if err := api.checkEntryAccess(req, c); err != nil {
+		return api.ErrorResponse(errors.New(errors.AccessDeniedError, err), c)
+	}
+
+	resp, err := api.svc(c).GetEntry(req, currentUser(c))
 	if err != nil {
 		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
 	}
 
+	resp, err = resp.ToEncoding(encoding)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
 	return api.SuccessResponse(resp, c)
 
 }
 
+// Returns decoded content of Factom entry with its MIME type, stored on raw upload or sniffed.
+// Content is served as sandboxed, so HTML entries can't run scripts on the API origin
+func (api *API) getEntryContent(c echo.Context) error {
+
+	req := &model.Entry{EntryHash: c.Param("entryhash")}
+
+	logger(c).Debug("Validating input data")
+
+	if err := api.validate.StructPartial(req, "EntryHash"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	if err := api.checkEntryAccess(req, c); err != nil {
+		return api.ErrorResponse(errors.New(errors.AccessDeniedError, err), c)
+	}
+
+	resp, err := api.svc(c).GetEntry(req, currentUser(c))
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	content, err := base64.StdEncoding.DecodeString(resp.Content)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	contentType := resp.ContentType
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+
+	header := c.Response().Header()
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Content-Security-Policy", "sandbox")
+	// entries are immutable, so the hash identifies content
+	header.Set("ETag", `"`+resp.EntryHash+`"`)
+
+	return c.Blob(http.StatusOK, contentType, content)
+
+}
+
 // Returns entries of Factom chain
 func (api *API) getChainEntries(c echo.Context) error {
 
@@ -818,13 +2490,17 @@ func (api *API) getChainEntries(c echo.Context) error {
 	req := &model.Entry{ChainID: c.Param("chainid")}
 	req.Status = c.QueryParam("status")
 
-	log.Debug("Validating input data")
+	logger(c).Debug("Validating input data")
 
 	// validate ChainID
 	if err := api.validate.StructPartial(req, "ChainID", "Status"); err != nil {
 		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
 	}
 
+	if err := api.checkChainAccess(req.GetChain(), c); err != nil {
+		return api.ErrorResponse(errors.New(errors.AccessDeniedError, err), c)
+	}
+
 	start, limit, sort, err := api.GetPaginationParams(c)
 	if err != nil {
 		return api.ErrorResponse(errors.New(errors.PaginationError, err), c)
@@ -834,7 +2510,12 @@ func (api *API) getChainEntries(c echo.Context) error {
 		force = true
 	}
 
-	resp, total, err := api.service.GetChainEntries(req, api.user, start, limit, sort, force)
+	encoding, err := requestEncoding(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	resp, total, err := api.svc(c).GetChainEntries(req, currentUser(c), start, limit, sort, force)
 	if err != nil {
 		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
 	}
@@ -842,6 +2523,8 @@ func (api *API) getChainEntries(c echo.Context) error {
 		return api.AcceptedResponse(resp, "Chain is syncing. Please wait for a while and try again. Or add 'force=true' as query param to get partial data.", c)
 	}
 
+	resp = encodeEntries(resp, encoding)
+
 	return api.SuccessResponsePagination(resp, total, c)
 
 }
@@ -854,6 +2537,11 @@ func (api *API) searchChainEntries(c echo.Context) error {
 	// Open API Entry struct
 	req := &model.Entry{}
 
+	encoding, err := requestEncoding(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
 	// bind input data
 	if err := c.Bind(req); err != nil {
 		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
@@ -865,15 +2553,25 @@ func (api *API) searchChainEntries(c echo.Context) error {
 	}
 
 	req.ChainID = c.Param("chainid")
+	req.ContentType = ""
 	req.Status = c.QueryParam("status")
 
-	log.Debug("Validating input data")
+	logger(c).Debug("Validating input data")
+
+	req, err = req.FromEncoding(encoding)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
 
 	// validate ChainID, ExtID
 	if err := api.validate.StructPartial(req, "ChainID", "ExtIDs", "Status"); err != nil {
 		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
 	}
 
+	if err := api.checkChainAccess(req.GetChain(), c); err != nil {
+		return api.ErrorResponse(errors.New(errors.AccessDeniedError, err), c)
+	}
+
 	start, limit, sort, err := api.GetPaginationParams(c)
 	if err != nil {
 		return api.ErrorResponse(errors.New(errors.PaginationError, err), c)
@@ -883,7 +2581,7 @@ func (api *API) searchChainEntries(c echo.Context) error {
 		force = true
 	}
 
-	resp, total, err := api.service.SearchChainEntries(req, api.user, start, limit, sort, force)
+	resp, total, err := api.svc(c).SearchChainEntries(req, currentUser(c), start, limit, sort, force)
 	if err != nil {
 		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
 	}
@@ -891,6 +2589,8 @@ func (api *API) searchChainEntries(c echo.Context) error {
 		return api.AcceptedResponse(resp, "Chain is syncing. Please wait for a while and try again. Or add 'force=true' as query param to get partial data.", c)
 	}
 
+	resp = encodeEntries(resp, encoding)
+
 	return api.SuccessResponsePagination(resp, total, c)
 
 }
@@ -898,7 +2598,7 @@ func (api *API) searchChainEntries(c echo.Context) error {
 // Returns first or last entry of Factom chain
 func (api *API) getChainFirstOrLastEntry(c echo.Context) error {
 
-	log.Debug("Validating first/last item")
+	logger(c).Debug("Validating first/last item")
 
 	var sort string
 
@@ -913,14 +2613,23 @@ func (api *API) getChainFirstOrLastEntry(c echo.Context) error {
 
 	req := &model.Entry{ChainID: c.Param("chainid")}
 
-	log.Debug("Validating input data")
+	logger(c).Debug("Validating input data")
 
 	// validate ChainID
 	if err := api.validate.StructPartial(req, "ChainID"); err != nil {
 		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
 	}
 
-	resp, err := api.service.GetChainFirstOrLastEntry(req, sort, api.user)
+	if err := api.checkChainAccess(req.GetChain(), c); err != nil {
+		return api.ErrorResponse(errors.New(errors.AccessDeniedError, err), c)
+	}
+
+	encoding, err := requestEncoding(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	resp, err := api.svc(c).GetChainFirstOrLastEntry(req, sort, currentUser(c))
 	if err != nil {
 		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
 	}
@@ -928,6 +2637,11 @@ func (api *API) getChainFirstOrLastEntry(c echo.Context) error {
 		return api.AcceptedResponse(resp, "Chain is syncing. Please wait for a while and try again.", c)
 	}
 
+	resp, err = resp.ToEncoding(encoding)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
 	return api.SuccessResponse(resp, c)
 
 }
@@ -937,9 +2651,40 @@ func (api *API) factomd(c echo.Context) error {
 
 	var params interface{}
 
+	method := c.Param("method")
+	user := currentUser(c)
+	policy := api.currentProxy()
+
+	// every proxied call is audited with its outcome, including rejected ones
+	action := model.AuditActionFactomdRead
+	if policy.IsWrite(method) {
+		action = model.AuditActionFactomdWrite
+	}
+	outcome := map[string]string{"outcome": model.AuditOutcomeOK}
+	fail := func(result string, err error) {
+		outcome["outcome"] = result
+		outcome["error"] = err.Error()
+	}
+	defer func() {
+		api.audit(c, action, "factomd", method, nil, outcome)
+	}()
+
+	if !policy.IsAllowed(method) {
+		err := fmt.Errorf("Method '%s' is not allowed via generic factomd interface", method)
+		fail(model.AuditOutcomeDenied, err)
+		return api.ErrorResponse(errors.New(errors.AccessDeniedError, err), c)
+	}
+
+	if policy.IsWrite(method) && (user.APIKey == nil || !user.APIKey.HasScope(model.ScopeFactomdWrite)) {
+		err := fmt.Errorf("Method '%s' requires '%s' scope", method, model.ScopeFactomdWrite)
+		fail(model.AuditOutcomeDenied, err)
+		return api.ErrorResponse(errors.New(errors.AccessDeniedError, err), c)
+	}
+
 	if c.FormValue("params") != "" {
 		err := json.Unmarshal([]byte(c.FormValue("params")), &params)
 		if err != nil {
+			fail(model.AuditOutcomeInvalid, err)
 			return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
 		}
 	}
@@ -950,15 +2695,30 @@ func (api *API) factomd(c echo.Context) error {
 		params = body
 	}
 
-	request := factom.NewJSON2Request(c.Param("method"), 0, params)
+	if err := policy.Validate(method, params); err != nil {
+		fail(model.AuditOutcomeInvalid, err)
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	logger := log.WithField("user", user.Name).WithField("method", method)
+	if user.APIKey != nil {
+		logger = logger.WithField("apiKey", user.APIKey.Name)
+	}
+	logger.Info("Generic factomd request")
+
+	request := factom.NewJSON2Request(method, 0, params)
 
+	_, span := tracing.StartFactomd(c.Request().Context(), method)
 	resp, err := factom.SendFactomdRequest(request)
+	tracing.End(span, err)
 
 	if err != nil {
+		fail(model.AuditOutcomeFailed, err)
 		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
 	}
 
 	if resp.Error != nil {
+		fail(model.AuditOutcomeRPCError, resp.Error)
 		return api.ErrorResponse(errors.New(resp.Error.Code, resp.Error), c)
 	}
 
@@ -968,6 +2728,196 @@ func (api *API) factomd(c echo.Context) error {
 
 // helpers
 
+// keys of JSON objects, which values are never written into audit log
+var auditRedactedKeys = map[string]bool{
+	"accessToken":  true,
+	"secret":       true,
+	"password":     true,
+	"passwordHash": true,
+	"token":        true,
+	"mfaToken":     true,
+	"esAddress":    true,
+}
+
+// audit writes audit log entry on behalf of the current admin or user
+func (api *API) audit(c echo.Context, action string, targetType string, targetID interface{}, before interface{}, after interface{}) {
+
+	entry := &model.AuditLog{
+		ActorType:  model.AuditActorSystem,
+		Action:     action,
+		TargetType: targetType,
+		IP:         c.RealIP(),
+		Before:     auditJSON(before),
+		After:      auditJSON(after),
+	}
+
+	if targetID != nil {
+		entry.TargetID = fmt.Sprint(targetID)
+	}
+
+	if admin := currentAdmin(c); admin != nil {
+		entry.ActorType = model.AuditActorAdmin
+		entry.ActorID = admin.ID
+		entry.ActorName = admin.Username
+	} else if user := currentUser(c); user != nil {
+		entry.ActorType = model.AuditActorUser
+		entry.ActorID = user.ID
+		entry.ActorName = user.Name
+	}
+
+	api.svc(c).Audit(entry)
+
+}
+
+// auditLoginFailed writes failed admin login attempt into audit log
+func (api *API) auditLoginFailed(username string, c echo.Context) {
+
+	api.svc(c).Audit(&model.AuditLog{
+		ActorType:  model.AuditActorAdmin,
+		ActorName:  username,
+		Action:     model.AuditActionLoginFailed,
+		TargetType: "session",
+		IP:         c.RealIP(),
+	})
+
+}
+
+// auditJSON converts value into JSON with secrets redacted
+func auditJSON(v interface{}) *postgres.Jsonb {
+
+	if v == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		log.Error(err)
+		return nil
+	}
+
+	var obj interface{}
+	if err := json.Unmarshal(b, &obj); err != nil || obj == nil {
+		return nil
+	}
+
+	b, err = json.Marshal(redactAuditValue(obj))
+	if err != nil {
+		log.Error(err)
+		return nil
+	}
+
+	return &postgres.Jsonb{RawMessage: b}
+
+}
+
+func redactAuditValue(v interface{}) interface{} {
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, item := range t {
+			if auditRedactedKeys[k] {
+				if item != nil && item != "" {
+					t[k] = config.RedactedValue
+				}
+				continue
+			}
+			t[k] = redactAuditValue(item)
+		}
+	case []interface{}:
+		for i, item := range t {
+			t[i] = redactAuditValue(item)
+		}
+	}
+
+	return v
+
+}
+
+// queryEncoding returns encoding of ExtIDs & content from query, base64 by default
+func queryEncoding(c echo.Context) (string, error) {
+
+	encoding := c.QueryParam("encoding")
+	if encoding == "" {
+		return model.EncodingBase64, nil
+	}
+
+	return encoding, model.ValidateEncoding(encoding)
+
+}
+
+// requestEncoding returns encoding of ExtIDs & content from query or body, base64 by default.
+// Different encodings in query & body are rejected, as values can't be decoded unambiguously
+func requestEncoding(c echo.Context) (string, error) {
+
+	encoding := c.QueryParam("encoding")
+
+	if c.Request().Method == http.MethodPost {
+
+		var value string
+		if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+			if body, err := bodyToJSON(c); err == nil {
+				value, _ = body["encoding"].(string)
+			}
+		} else {
+			value = c.Request().PostFormValue("encoding")
+		}
+
+		if value != "" {
+			if encoding != "" && value != encoding {
+				return "", fmt.Errorf("Encoding expected to be passed once, '%s' in query and '%s' in body received", encoding, value)
+			}
+			encoding = value
+		}
+
+	}
+
+	if encoding == "" {
+		return model.EncodingBase64, nil
+	}
+
+	return encoding, model.ValidateEncoding(encoding)
+
+}
+
+// encodeEntries converts ExtIDs & content of entries to encoding.
+// Entries, which can't be converted, are returned in base64 with encoding field set, so one entry doesn't fail the whole page
+func encodeEntries(entries []*model.Entry, encoding string) []*model.Entry {
+
+	if encoding == model.EncodingBase64 {
+		return entries
+	}
+
+	resp := []*model.Entry{}
+	for _, entry := range entries {
+		encoded, err := entry.ToEncoding(encoding)
+		if err != nil {
+			encoded = &model.Entry{}
+			copier.Copy(encoded, entry)
+			encoded.Encoding = model.EncodingBase64
+		}
+		resp = append(resp, encoded)
+	}
+
+	return resp
+
+}
+
+// encodeChains converts ExtIDs of chains to encoding
+func encodeChains(chains []*model.Chain, encoding string) (*model.Chains, error) {
+
+	resp := &model.Chains{}
+	for _, chain := range chains {
+		encoded, err := chain.ToEncoding(encoding)
+		if err != nil {
+			return nil, err
+		}
+		resp.Items = append(resp.Items, encoded)
+	}
+
+	return resp, nil
+
+}
+
 func bodyToJSON(c echo.Context) (map[string]interface{}, error) {
 
 	s, err := ioutil.ReadAll(c.Request().Body)
@@ -985,16 +2935,6 @@ func bodyToJSON(c echo.Context) (map[string]interface{}, error) {
 	return body, nil
 }
 
-func generateJWTSecret() []byte {
-	letterRunes := []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890")
-	b := make([]rune, 128)
-	rand.Seed(time.Now().UnixNano())
-	for i := range b {
-		b[i] = letterRunes[rand.Intn(len(letterRunes))]
-	}
-	return []byte(string((b)))
-}
-
 func writeCookie(c echo.Context, token string) error {
 	cookie := new(http.Cookie)
 	cookie.Path = "/"
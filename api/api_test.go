@@ -1,24 +1,45 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"os/user"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/DeFacto-Team/Factom-Open-API/config"
+	"github.com/DeFacto-Team/Factom-Open-API/logging"
 	"github.com/DeFacto-Team/Factom-Open-API/model"
+	"github.com/DeFacto-Team/Factom-Open-API/pool"
 	"github.com/DeFacto-Team/Factom-Open-API/service"
 	"github.com/DeFacto-Team/Factom-Open-API/store"
+	"github.com/DeFacto-Team/Factom-Open-API/totp"
+	"github.com/DeFacto-Team/Factom-Open-API/tracing"
 	"github.com/DeFacto-Team/Factom-Open-API/wallet"
 	"github.com/FactomProject/factom"
 	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
 )
 
 func NewTestAPI() *API {
@@ -51,6 +72,11 @@ func TestLogin(t *testing.T) {
 	testAPI := NewTestAPI()
 	e := echo.New()
 
+	// Create the first superadmin from config, if there are no admins yet
+	if err := testAPI.service.BootstrapAdmin(testAPI.conf.Admin.User, testAPI.conf.Admin.Password); err != nil {
+		t.Error(err)
+	}
+
 	// Setup echo context
 	f := make(url.Values)
 	f.Set("user", testAPI.conf.Admin.User)
@@ -67,6 +93,161 @@ func TestLogin(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 	}
 
+	// Password without username doesn't match any admin
+	f.Set("user", "")
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(f.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	c = e.NewContext(req, httptest.NewRecorder())
+
+	assert.Equal(t, echo.ErrUnauthorized, testAPI.login(c))
+	assert.Nil(t, testAPI.service.CheckAdmin("", testAPI.conf.Admin.Password))
+
+}
+
+func TestLoginTOTP(t *testing.T) {
+
+	// Setup with deterministic clock
+	testAPI := NewTestAPI()
+	now := time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC)
+	testAPI.now = func() time.Time { return now }
+
+	// Create test admin with enabled TOTP
+	ta, err := testAPI.service.CreateAdmin(&model.Admin{Username: "test" + strconv.FormatInt(time.Now().UnixNano(), 10), Password: "testpassword", Role: model.AdminRoleViewer})
+	if err != nil {
+		t.Error(err)
+	}
+	secret, err := testAPI.service.EnrollAdminTOTP(ta)
+	if err != nil {
+		t.Error(err)
+	}
+	code, _ := totp.Code(secret, now.Add(-totp.Period))
+	recoveryCodes, err := testAPI.service.EnableAdminTOTP(ta, code, now.Add(-totp.Period))
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Len(t, recoveryCodes, model.AdminRecoveryCodesCount)
+
+	login := func(f url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(f.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		rec := httptest.NewRecorder()
+		testAPI.HTTP.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Step 1: password returns mfaToken instead of session
+	f := make(url.Values)
+	f.Set("user", ta.Username)
+	f.Set("password", "testpassword")
+	rec := login(f)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Result().Cookies())
+
+	resp := map[string]interface{}{}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, true, resp["totpRequired"])
+	mfaToken, _ := resp["mfaToken"].(string)
+
+	// mfaToken is not accepted as session
+	req := httptest.NewRequest(http.MethodGet, "/admin/me", nil)
+	req.AddCookie(&http.Cookie{Name: "token", Value: mfaToken})
+	rec = httptest.NewRecorder()
+	testAPI.HTTP.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Step 2: invalid code is rejected
+	f = make(url.Values)
+	f.Set("mfaToken", mfaToken)
+	f.Set("code", "000000")
+	assert.Equal(t, http.StatusUnauthorized, login(f).Code)
+
+	// Step 2: valid code starts session
+	code, _ = totp.Code(secret, now)
+	f.Set("code", code)
+	rec = login(f)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEmpty(t, rec.Result().Cookies())
+
+	// The same code can't be used twice
+	assert.Equal(t, http.StatusUnauthorized, login(f).Code)
+
+	// Recovery code can be used once
+	f.Set("code", recoveryCodes[0])
+	assert.Equal(t, http.StatusOK, login(f).Code)
+	assert.Equal(t, http.StatusUnauthorized, login(f).Code)
+
+	// Delete test admin
+	testAPI.service.DeleteAdmin(ta)
+
+}
+
+func TestRequireRole(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(adminContextKey, &model.Admin{ID: 1, Role: model.AdminRoleOperator})
+
+	// Assertions
+	if assert.NoError(t, testAPI.requireRole(model.AdminRoleSuperadmin)(testAPI.adminGetSettings)(c)) {
+		t.Logf(rec.Body.String())
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	}
+
+}
+
+func TestAdminGetAdmins(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Assertions
+	if assert.NoError(t, testAPI.adminGetAdmins(c)) {
+		t.Logf(rec.Body.String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "passwordHash")
+	}
+
+}
+
+func TestAdminCreateAdmin(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+
+	// Setup echo context
+	f := make(url.Values)
+	f.Set("username", "test"+strconv.FormatInt(time.Now().UnixNano(), 10))
+	f.Set("password", "testpassword")
+	f.Set("role", model.AdminRoleViewer)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(f.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Assertions
+	if assert.NoError(t, testAPI.adminCreateAdmin(c)) {
+		t.Logf(rec.Body.String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "testpassword")
+	}
+
+	// Check login & delete test admin
+	admin := testAPI.service.CheckAdmin(f.Get("username"), "testpassword")
+	if assert.NotNil(t, admin) {
+		assert.Equal(t, model.AdminRoleViewer, admin.Role)
+		testAPI.service.DeleteAdmin(admin)
+	}
+
 }
 
 func TestAdminIndex(t *testing.T) {
@@ -128,6 +309,138 @@ func TestAdminGetQueue(t *testing.T) {
 
 }
 
+func TestAdminGetSync(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Assertions
+	if assert.NoError(t, testAPI.adminGetSync(c)) {
+		t.Logf(rec.Body.String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp struct {
+			Result *model.SyncProgress `json:"result"`
+		}
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp)) {
+			assert.Equal(t, testAPI.conf.Parser.SyncMode, resp.Result.SyncMode)
+			assert.Equal(t, testAPI.conf.Parser.Index, resp.Result.Index)
+			assert.Contains(t, resp.Result.Chains, "synced")
+		}
+	}
+
+}
+
+func TestAdminPool(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+
+	// pool without workers, so queued chains stay in the queue
+	collector := pool.StartDispatcher(0)
+	defer collector.Stop(context.Background())
+	testAPI.service.SetScheduler(collector)
+
+	// Create test user and chains
+	tu := &model.User{}
+	tu.Name = "Test"
+	tu.AccessToken = tu.GenerateAccessToken(32)
+	tu, err := testAPI.service.CreateUser(tu)
+	if err != nil {
+		t.Error(err)
+	}
+	var chains []*model.Chain
+	for i := 0; i < 2; i++ {
+		tc := &model.Chain{}
+		tc.ExtIDs = []string{strconv.FormatInt(time.Now().UnixNano(), 10)}
+		tc, err = testAPI.service.CreateChain(tc.Base64Encode(), tu)
+		if err != nil {
+			t.Fatal(err)
+		}
+		collector.Work <- pool.Work{ID: tc.ChainID, Job: tc, Service: testAPI.service, Priority: model.ChainPriorityDefault}
+		chains = append(chains, tc)
+	}
+
+	getPool := func() *model.PoolState {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		var resp struct {
+			Result *model.PoolState `json:"result"`
+		}
+		if assert.NoError(t, testAPI.adminGetPool(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		}
+		return resp.Result
+	}
+
+	state := getPool()
+	if assert.Len(t, state.Queue, 2) {
+		assert.Equal(t, chains[0].ChainID, state.Queue[0].ChainID)
+	}
+
+	// the second chain is re-prioritised & goes first
+	f := make(url.Values)
+	f.Set("priority", strconv.Itoa(model.ChainPriorityForced))
+	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(f.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("chainid")
+	c.SetParamValues(chains[1].ChainID)
+
+	if assert.NoError(t, testAPI.adminUpdatePool(c)) {
+		t.Logf(rec.Body.String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	state = getPool()
+	if assert.Len(t, state.Queue, 2) {
+		assert.Equal(t, chains[1].ChainID, state.Queue[0].ChainID)
+		assert.Equal(t, model.ChainPriorityForced, state.Queue[0].Priority)
+	}
+
+	// negative priority is rejected, cancel is used instead
+	f.Set("priority", "-1")
+	req = httptest.NewRequest(http.MethodPut, "/", strings.NewReader(f.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("chainid")
+	c.SetParamValues(chains[1].ChainID)
+
+	if assert.NoError(t, testAPI.adminUpdatePool(c)) {
+		assert.NotEqual(t, http.StatusOK, rec.Code)
+	}
+
+	// sync of the second chain is cancelled
+	req = httptest.NewRequest(http.MethodDelete, "/", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("chainid")
+	c.SetParamValues(chains[1].ChainID)
+
+	if assert.NoError(t, testAPI.adminDeletePool(c)) {
+		t.Logf(rec.Body.String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	state = getPool()
+	if assert.Len(t, state.Queue, 1) {
+		assert.Equal(t, chains[0].ChainID, state.Queue[0].ChainID)
+	}
+	for _, tc := range testAPI.service.GetChainsToSync(1000) {
+		assert.NotEqual(t, chains[1].ChainID, tc.ChainID)
+	}
+
+}
+
 func TestAdminDeleteQueue(t *testing.T) {
 
 	// Setup
@@ -372,7 +685,7 @@ func TestAdminRotateUserToken(t *testing.T) {
 
 }
 
-func TestAdminLogout(t *testing.T) {
+func TestAdminGetUserUsage(t *testing.T) {
 
 	// Setup
 	testAPI := NewTestAPI()
@@ -381,56 +694,155 @@ func TestAdminLogout(t *testing.T) {
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 
+	// Create test user with daily usage period, which started 2 days ago
+	tu := &model.User{}
+	tu.Name = "Test"
+	tu.UsagePeriod = model.UsagePeriodDaily
+	tu.UsageLimit = 10
+	tu, err := testAPI.service.CreateUser(tu)
+	if err != nil {
+		t.Error(err)
+	}
+	start := time.Now().AddDate(0, 0, -2)
+	tu.UsagePeriodStart = &start
+	tu.Usage = 5
+	if err := testAPI.service.UpdateUser(tu); err != nil {
+		t.Error(err)
+	}
+
+	// Reset usage of the expired period
+	if err := testAPI.service.ResetExpiredUsage(); err != nil {
+		t.Error(err)
+	}
+
+	// Setup echo context
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(tu.ID))
+
 	// Assertions
-	if assert.NoError(t, testAPI.adminLogout(c)) {
+	if assert.NoError(t, testAPI.adminGetUserUsage(c)) {
 		t.Logf(rec.Body.String())
 		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "\"usage\":5")
 	}
+	assert.Equal(t, 0, testAPI.service.GetUser(&model.User{ID: tu.ID}).Usage)
+
+	// Delete test user
+	testAPI.service.DeleteUser(tu)
 
 }
 
-func TestAdminGetSettings(t *testing.T) {
+func TestUsageResetWithQueue(t *testing.T) {
 
 	// Setup
 	testAPI := NewTestAPI()
-	e := echo.New()
-	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	rec := httptest.NewRecorder()
-	c := e.NewContext(req, rec)
+	st, err := store.NewStore(testAPI.conf, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create test user with daily usage period, which started 2 days ago
+	tu := &model.User{}
+	tu.Name = "Test"
+	tu.UsagePeriod = model.UsagePeriodDaily
+	tu.UsageLimit = 100
+	tu, err = testAPI.service.CreateUser(tu)
+	if err != nil {
+		t.Error(err)
+	}
+	start := time.Now().AddDate(0, 0, -2)
+	tu.UsagePeriodStart = &start
+	tu.Usage = 5
+	if err := testAPI.service.UpdateUser(tu); err != nil {
+		t.Error(err)
+	}
+
+	// Queue items are created while usage is reset
+	const items = 20
+	queue := make([]*model.Queue, items)
+	var wg sync.WaitGroup
+	for i := range queue {
+		queue[i] = &model.Queue{UserID: tu.ID, Action: model.QueueActionEntry}
+		wg.Add(1)
+		go func(q *model.Queue) {
+			defer wg.Done()
+			assert.NoError(t, st.CreateQueue(q))
+		}(queue[i])
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, testAPI.service.ResetExpiredUsage())
+	}()
+	wg.Wait()
 
 	// Assertions
-	if assert.NoError(t, testAPI.adminGetSettings(c)) {
-		t.Logf(rec.Body.String())
-		assert.Equal(t, http.StatusOK, rec.Code)
+	// the period is closed once & no increment is lost or charged to the closed period twice
+	assert.NoError(t, testAPI.service.ResetExpiredUsage())
+	history := testAPI.service.GetUsageHistory(tu)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, 5+items, history[0].Usage+testAPI.service.GetUser(&model.User{ID: tu.ID}).Usage)
+	}
+
+	// Delete test queue items & user
+	for _, q := range queue {
+		st.DeleteQueue(q)
 	}
+	testAPI.service.DeleteUser(tu)
 
 }
 
-func TestAdminUpdateSettings(t *testing.T) {
+func TestAdvanceChainLatestEntryBlock(t *testing.T) {
 
 	// Setup
 	testAPI := NewTestAPI()
-	e := echo.New()
+	st, err := store.NewStore(testAPI.conf, false)
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	// Setup echo context
-	f := make(url.Values)
-	f.Set("adminUser", "test")
-	f.Set("adminPassword", "test")
-	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(f.Encode()))
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	id := time.Now().UnixNano()
+	hash := func(i int64) string {
+		return fmt.Sprintf("%064x", id+i)
+	}
 
-	rec := httptest.NewRecorder()
-	c := e.NewContext(req, rec)
+	// Create test chain, which was parsed till the entry block of dblock 200
+	tc := &model.Chain{ChainID: hash(0), Status: model.ChainCompleted}
+	if err := st.CreateChain(tc); err != nil {
+		t.Fatal(err)
+	}
+	eblocks := make([]*model.EBlock, 3)
+	for i, height := range []int64{100, 200, 300} {
+		eblocks[i] = &model.EBlock{KeyMR: hash(int64(i) + 1), ChainID: tc.ChainID, BlockSequenceNumber: int64(i), DBHeight: height}
+		if err := st.CreateEBlock(eblocks[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
 
 	// Assertions
-	if assert.NoError(t, testAPI.adminUpdateSettings(c)) {
-		t.Logf(rec.Body.String())
-		assert.Equal(t, http.StatusOK, rec.Code)
+	// checkpoint of chain without parsed blocks is set
+	advanced, err := st.AdvanceChainLatestEntryBlock(tc, eblocks[1])
+	assert.NoError(t, err)
+	assert.True(t, advanced)
+	assert.Equal(t, eblocks[1].KeyMR, st.GetChain(&model.Chain{ChainID: tc.ChainID}).LatestEntryBlock)
+
+	// replayed old dblock doesn't move checkpoint backwards
+	for _, eblock := range eblocks[:2] {
+		advanced, err = st.AdvanceChainLatestEntryBlock(tc, eblock)
+		assert.NoError(t, err)
+		assert.False(t, advanced)
+		assert.Equal(t, eblocks[1].KeyMR, st.GetChain(&model.Chain{ChainID: tc.ChainID}).LatestEntryBlock)
 	}
 
+	// block of later dblock moves checkpoint forward
+	advanced, err = st.AdvanceChainLatestEntryBlock(tc, eblocks[2])
+	assert.NoError(t, err)
+	assert.True(t, advanced)
+	assert.Equal(t, eblocks[2].KeyMR, st.GetChain(&model.Chain{ChainID: tc.ChainID}).LatestEntryBlock)
+
 }
 
-func TestAdminRestartAPI(t *testing.T) {
+func TestAdminGetUserKeys(t *testing.T) {
 
 	// Setup
 	testAPI := NewTestAPI()
@@ -439,55 +851,416 @@ func TestAdminRestartAPI(t *testing.T) {
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 
+	// Create test user
+	tu := &model.User{}
+	tu.Name = "Test"
+	tu.AccessToken = tu.GenerateAccessToken(32)
+	tu, err := testAPI.service.CreateUser(tu)
+	if err != nil {
+		t.Error(err)
+	}
+
+	// Setup echo context
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(tu.ID))
+
 	// Assertions
-	if assert.NoError(t, testAPI.adminRestartAPI(c)) {
+	if assert.NoError(t, testAPI.adminGetUserKeys(c)) {
 		t.Logf(rec.Body.String())
 		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), tu.AccessToken)
 	}
 
+	// Delete test user
+	testAPI.service.DeleteUser(tu)
+
 }
 
-func TestAdminRandomEC(t *testing.T) {
+func TestAdminCreateUserKey(t *testing.T) {
 
 	// Setup
 	testAPI := NewTestAPI()
 	e := echo.New()
-	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	// Create test user
+	tu := &model.User{}
+	tu.Name = "Test"
+	tu.AccessToken = tu.GenerateAccessToken(32)
+	tu, err := testAPI.service.CreateUser(tu)
+	if err != nil {
+		t.Error(err)
+	}
+
+	// Setup echo context
+	expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"CI","expiresAt":"`+expiresAt+`"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(tu.ID))
 
 	// Assertions
-	if assert.NoError(t, testAPI.adminRandomEC(c)) {
+	if assert.NoError(t, testAPI.adminCreateUserKey(c)) {
 		t.Logf(rec.Body.String())
 		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, testAPI.service.GetAPIKeys(tu), 2)
 	}
 
+	// Delete test user
+	testAPI.service.DeleteUser(tu)
+
 }
 
-func TestAdminGetEC(t *testing.T) {
+func TestAdminDeleteUserKey(t *testing.T) {
 
 	// Setup
 	testAPI := NewTestAPI()
 	e := echo.New()
-	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 
-	// Create random EC wallet
-	ecAddress := model.GenerateEC()
+	// Create test user and additional key
+	tu := &model.User{}
+	tu.Name = "Test"
+	tu.AccessToken = tu.GenerateAccessToken(32)
+	tu, err := testAPI.service.CreateUser(tu)
+	if err != nil {
+		t.Error(err)
+	}
+	tk, err := testAPI.service.CreateAPIKey(&model.APIKey{Name: "CI"}, tu)
+	if err != nil {
+		t.Error(err)
+	}
 
 	// Setup echo context
-	c.SetParamNames("esaddress")
-	c.SetParamValues(ecAddress.EsAddress)
+	c.SetParamNames("id", "keyid")
+	c.SetParamValues(strconv.Itoa(tu.ID), strconv.Itoa(tk.ID))
 
 	// Assertions
-	if assert.NoError(t, testAPI.adminGetEC(c)) {
+	if assert.NoError(t, testAPI.adminDeleteUserKey(c)) {
 		t.Logf(rec.Body.String())
 		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Nil(t, testAPI.service.CheckUser(tk.Secret))
+		assert.NotNil(t, testAPI.service.CheckUser(tu.AccessToken))
 	}
 
-}
-
+	// Key of another user or without user can't be revoked
+	tk, err = testAPI.service.CreateAPIKey(&model.APIKey{Name: "CI"}, tu)
+	if err != nil {
+		t.Error(err)
+	}
+	for _, userID := range []int{tu.ID + 1, 0} {
+		assert.Error(t, testAPI.service.RevokeAPIKey(&model.APIKey{ID: tk.ID}, &model.User{ID: userID}))
+	}
+	assert.NotNil(t, testAPI.service.CheckUser(tk.Secret))
+
+	// Delete test user
+	testAPI.service.DeleteUser(tu)
+
+}
+
+func TestAdminLogout(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+
+	// Create test admin & login
+	ta, err := testAPI.service.CreateAdmin(&model.Admin{Username: "test" + strconv.FormatInt(time.Now().UnixNano(), 10), Password: "testpassword", Role: model.AdminRoleViewer})
+	if err != nil {
+		t.Error(err)
+	}
+
+	f := make(url.Values)
+	f.Set("user", ta.Username)
+	f.Set("password", "testpassword")
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(f.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	testAPI.HTTP.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	cookies := rec.Result().Cookies()
+
+	// Request with JWT is authenticated
+	req = httptest.NewRequest(http.MethodGet, "/admin/me", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	rec = httptest.NewRecorder()
+	testAPI.HTTP.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Logout
+	req = httptest.NewRequest(http.MethodGet, "/admin/logout", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	rec = httptest.NewRecorder()
+	testAPI.HTTP.ServeHTTP(rec, req)
+	if assert.Equal(t, http.StatusOK, rec.Code) {
+		t.Logf(rec.Body.String())
+	}
+
+	// The same JWT is rejected after logout
+	req = httptest.NewRequest(http.MethodGet, "/admin/me", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	rec = httptest.NewRecorder()
+	testAPI.HTTP.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Delete test admin
+	testAPI.service.DeleteAdmin(ta)
+
+}
+
+func TestAdminGetSessions(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Create test admin & session
+	ta, err := testAPI.service.CreateAdmin(&model.Admin{Username: "test" + strconv.FormatInt(time.Now().UnixNano(), 10), Password: "testpassword", Role: model.AdminRoleViewer})
+	if err != nil {
+		t.Error(err)
+	}
+	ts, err := testAPI.service.CreateAdminSession(ta, "127.0.0.1", "test")
+	if err != nil {
+		t.Error(err)
+	}
+	c.Set(adminSessionContextKey, ts)
+
+	// Assertions
+	if assert.NoError(t, testAPI.adminGetSessions(c)) {
+		t.Logf(rec.Body.String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "\"current\":true")
+		assert.NotContains(t, rec.Body.String(), ts.JTI)
+	}
+
+	// Delete test admin
+	testAPI.service.DeleteAdmin(ta)
+
+}
+
+func TestAdminDeleteSession(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Create test admin & session
+	ta, err := testAPI.service.CreateAdmin(&model.Admin{Username: "test" + strconv.FormatInt(time.Now().UnixNano(), 10), Password: "testpassword", Role: model.AdminRoleViewer})
+	if err != nil {
+		t.Error(err)
+	}
+	ts, err := testAPI.service.CreateAdminSession(ta, "127.0.0.1", "test")
+	if err != nil {
+		t.Error(err)
+	}
+
+	// Setup echo context
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(ts.ID))
+
+	// Assertions
+	if assert.NoError(t, testAPI.adminDeleteSession(c)) {
+		t.Logf(rec.Body.String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Nil(t, testAPI.service.CheckAdminSession(ts.JTI))
+	}
+
+	// Delete test admin
+	testAPI.service.DeleteAdmin(ta)
+
+}
+
+func TestAdminGetAudit(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+
+	// Create test admin
+	ta, err := testAPI.service.CreateAdmin(&model.Admin{Username: "test" + strconv.FormatInt(time.Now().UnixNano(), 10), Password: "testpassword", Role: model.AdminRoleOperator})
+	if err != nil {
+		t.Error(err)
+	}
+
+	// Create test user on behalf of test admin
+	tu := &model.User{Name: "Test"}
+	tu.AccessToken = tu.GenerateAccessToken(32)
+
+	f := make(url.Values)
+	f.Set("name", tu.Name)
+	f.Set("accessToken", tu.AccessToken)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(f.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	c := e.NewContext(req, httptest.NewRecorder())
+	c.Set(adminContextKey, ta)
+
+	if err := testAPI.adminCreateUser(c); err != nil {
+		t.Error(err)
+	}
+
+	// Setup echo context
+	q := make(url.Values)
+	q.Set("action", model.AuditActionUserCreate)
+	q.Set("actorName", ta.Username)
+	req = httptest.NewRequest(http.MethodGet, "/?"+q.Encode(), nil)
+	rec := httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	// Assertions
+	if assert.NoError(t, testAPI.adminGetAudit(c)) {
+		t.Logf(rec.Body.String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "\"total\":1")
+		assert.Contains(t, rec.Body.String(), "\"accessToken\":\""+config.RedactedValue+"\"")
+		assert.NotContains(t, rec.Body.String(), tu.AccessToken)
+	}
+
+	// Invalid time range
+	req = httptest.NewRequest(http.MethodGet, "/?from=yesterday", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	if assert.NoError(t, testAPI.adminGetAudit(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+
+	// Delete test user & admin
+	testAPI.service.DeleteUser(tu)
+	testAPI.service.DeleteAdmin(ta)
+
+}
+
+func TestAdminGetSettings(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Assertions
+	if assert.NoError(t, testAPI.adminGetSettings(c)) {
+		t.Logf(rec.Body.String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+}
+
+func TestAdminUpdateSettings(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+
+	// Setup echo context
+	f := make(url.Values)
+	f.Set("adminUser", "test")
+	f.Set("adminPassword", "test")
+	f.Set("apiLogLevel", strconv.Itoa(int(log.DebugLevel)))
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(f.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Assertions
+	if assert.NoError(t, testAPI.adminUpdateSettings(c)) {
+		t.Logf(rec.Body.String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, log.DebugLevel, log.GetLevel())
+	}
+
+	// Invalid settings are not saved
+	f.Set("apiLogLevel", "42")
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(f.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	if assert.NoError(t, testAPI.adminUpdateSettings(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		conf, _ := config.NewConfig(testAPI.configFile)
+		assert.Equal(t, int(log.DebugLevel), conf.API.LogLevel)
+	}
+
+}
+
+func TestAdminReloadSettings(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Assertions
+	if assert.NoError(t, testAPI.adminReloadSettings(c)) {
+		t.Logf(rec.Body.String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "\"restartRequired\":false")
+	}
+
+}
+
+func TestAdminRandomEC(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Assertions
+	if assert.NoError(t, testAPI.adminRandomEC(c)) {
+		t.Logf(rec.Body.String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+}
+
+func TestAdminGetEC(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Create random EC wallet
+	ecAddress := model.GenerateEC()
+
+	// Setup echo context
+	c.SetParamNames("esaddress")
+	c.SetParamValues(ecAddress.EsAddress)
+
+	// Assertions
+	if assert.NoError(t, testAPI.adminGetEC(c)) {
+		t.Logf(rec.Body.String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+}
+
 func TestIndex(t *testing.T) {
 
 	// Setup
@@ -519,7 +1292,6 @@ func TestCreateChain(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	testAPI.user = tu
 
 	// Setup echo context
 	f := make(url.Values)
@@ -531,6 +1303,7 @@ func TestCreateChain(t *testing.T) {
 
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
 
 	// Assertions
 	if assert.NoError(t, testAPI.createChain(c)) {
@@ -557,7 +1330,6 @@ func TestGetChains(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	testAPI.user = tu
 
 	tc := &model.Chain{}
 	tc.ExtIDs = []string{strconv.FormatInt(time.Now().UnixNano(), 10)}
@@ -570,6 +1342,7 @@ func TestGetChains(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
 
 	// Assertions
 	if assert.NoError(t, testAPI.getChains(c)) {
@@ -596,7 +1369,6 @@ func TestGetChain(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	testAPI.user = tu
 
 	tc := &model.Chain{}
 	tc.ExtIDs = []string{strconv.FormatInt(time.Now().UnixNano(), 10)}
@@ -609,6 +1381,7 @@ func TestGetChain(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
 
 	c.SetParamNames("chainid")
 	c.SetParamValues(tc.ChainID)
@@ -624,6 +1397,66 @@ func TestGetChain(t *testing.T) {
 
 }
 
+func TestDeleteChain(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+
+	// Create test user and chain
+	tu := &model.User{}
+	tu.Name = "Test"
+	tu.AccessToken = tu.GenerateAccessToken(32)
+	tu, err := testAPI.service.CreateUser(tu)
+	if err != nil {
+		t.Error(err)
+	}
+
+	tc := &model.Chain{}
+	tc.ExtIDs = []string{strconv.FormatInt(time.Now().UnixNano(), 10)}
+	tc, err = testAPI.service.CreateChain(tc.Base64Encode(), tu)
+	if err != nil {
+		t.Error(err)
+	}
+
+	deleteChain := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(userContextKey, tu)
+		c.SetParamNames("chainid")
+		c.SetParamValues(tc.ChainID)
+		assert.NoError(t, testAPI.deleteChain(c))
+		return rec
+	}
+
+	// Assertions
+	rec := deleteChain()
+	t.Logf(rec.Body.String())
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// chain is not in user's chains & not tracked anymore
+	chains, _ := testAPI.service.GetUserChains(&model.Chain{}, tu, 0, 1000, "asc")
+	for _, uc := range chains {
+		assert.NotEqual(t, tc.ChainID, uc.ChainID)
+	}
+	for _, tracked := range testAPI.service.GetTrackedChains(&model.Chain{ChainID: tc.ChainID}) {
+		assert.NotEqual(t, tc.ChainID, tracked.ChainID)
+	}
+
+	// chain is purged by retention policy
+	_, err = testAPI.service.PurgeUntrackedChains(time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+
+	// chain is not bound to user anymore
+	rec = deleteChain()
+	assert.NotEqual(t, http.StatusOK, rec.Code)
+
+	// Delete test user
+	testAPI.service.DeleteUser(tu)
+
+}
+
 func TestSearchChains(t *testing.T) {
 
 	// Setup
@@ -638,7 +1471,6 @@ func TestSearchChains(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	testAPI.user = tu
 
 	tc := &model.Chain{}
 	extId := strconv.FormatInt(time.Now().UnixNano(), 10)
@@ -657,6 +1489,7 @@ func TestSearchChains(t *testing.T) {
 
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
 
 	// Assertions
 	if assert.NoError(t, testAPI.searchChains(c)) {
@@ -683,7 +1516,6 @@ func TestGetChainEntries(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	testAPI.user = tu
 
 	tc := &model.Chain{}
 	tc.ExtIDs = []string{strconv.FormatInt(time.Now().UnixNano(), 10)}
@@ -696,6 +1528,7 @@ func TestGetChainEntries(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
 
 	c.SetParamNames("chainid")
 	c.SetParamValues(tc.ChainID)
@@ -725,7 +1558,6 @@ func TestSearchChainEntries(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	testAPI.user = tu
 
 	tc := &model.Chain{}
 	extId := strconv.FormatInt(time.Now().UnixNano(), 10)
@@ -744,6 +1576,7 @@ func TestSearchChainEntries(t *testing.T) {
 
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
 
 	c.SetParamNames("chainid")
 	c.SetParamValues(tc.ChainID)
@@ -773,7 +1606,6 @@ func TestGetChainFirstOrLastEntry(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	testAPI.user = tu
 
 	tc := &model.Chain{}
 	tc.ExtIDs = []string{strconv.FormatInt(time.Now().UnixNano(), 10)}
@@ -786,6 +1618,7 @@ func TestGetChainFirstOrLastEntry(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
 
 	c.SetParamNames("chainid", "item")
 	c.SetParamValues(tc.ChainID, "first")
@@ -815,7 +1648,6 @@ func TestCreateEntry(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	testAPI.user = tu
 
 	tc := &model.Chain{}
 	extId := strconv.FormatInt(time.Now().UnixNano(), 10)
@@ -834,19 +1666,151 @@ func TestCreateEntry(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(f.Encode()))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
 
+	requestID := logging.NewRequestID()
+	req = req.WithContext(logging.WithRequestID(req.Context(), requestID))
+
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
 
 	// Assertions
 	if assert.NoError(t, testAPI.createEntry(c)) {
 		t.Logf(rec.Body.String())
 		assert.Equal(t, http.StatusOK, rec.Code)
+		// queue item is traced by ID of the request
+		assert.Len(t, testAPI.service.GetQueue(&model.Queue{RequestID: requestID}), 1)
 	}
 
-	// Delete test user
-	testAPI.service.DeleteUser(tu)
+	// content type is not bound from request, it's set for raw entries only
+	extId = base64.StdEncoding.EncodeToString([]byte(strconv.FormatInt(time.Now().UnixNano(), 10)))
+	body := `{"chainId":"` + tc.ChainID + `","extIds":["` + extId + `"],"contentType":"text/html"}`
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
 
-}
+	if assert.NoError(t, testAPI.createEntry(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "contentType")
+	}
+
+	// Delete test user
+	testAPI.service.DeleteUser(tu)
+
+}
+
+func TestEncodeEntries(t *testing.T) {
+
+	text := &model.Entry{EntryHash: "text", ExtIDs: []string{base64.StdEncoding.EncodeToString([]byte("id"))}, Content: base64.StdEncoding.EncodeToString([]byte("hello"))}
+	binary := &model.Entry{EntryHash: "binary", ExtIDs: []string{}, Content: base64.StdEncoding.EncodeToString([]byte{0xff, 0xfe})}
+
+	// entry, which is not valid utf8, is returned in base64 & doesn't fail the list
+	resp := encodeEntries([]*model.Entry{text, binary}, model.EncodingUTF8)
+	if assert.Len(t, resp, 2) {
+		assert.Equal(t, "hello", resp[0].Content)
+		assert.Equal(t, []string{"id"}, []string(resp[0].ExtIDs))
+		assert.Empty(t, resp[0].Encoding)
+		assert.Equal(t, binary.Content, resp[1].Content)
+		assert.Equal(t, model.EncodingBase64, resp[1].Encoding)
+	}
+
+	// any content can be hex encoded
+	resp = encodeEntries([]*model.Entry{binary}, model.EncodingHex)
+	if assert.Len(t, resp, 1) {
+		assert.Equal(t, "fffe", resp[0].Content)
+		assert.Empty(t, resp[0].Encoding)
+	}
+
+}
+
+func TestEntryEncoding(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+
+	// Create test user and chain
+	tu := &model.User{}
+	tu.Name = "Test"
+	tu.AccessToken = tu.GenerateAccessToken(32)
+	tu, err := testAPI.service.CreateUser(tu)
+	if err != nil {
+		t.Error(err)
+	}
+
+	tc := &model.Chain{}
+	tc.ExtIDs = []string{strconv.FormatInt(time.Now().UnixNano(), 10)}
+	tc, err = testAPI.service.CreateChain(tc.Base64Encode(), tu)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	extID := strconv.FormatInt(time.Now().UnixNano(), 10)
+	content := "Factom Open API ✓"
+
+	newContext := func(method string, target string, body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(userContextKey, tu)
+		return c, rec
+	}
+
+	// Create entry with utf8 encoding passed in body
+	body, _ := json.Marshal(map[string]interface{}{"chainId": tc.ChainID, "extIds": []string{extID}, "content": content, "encoding": model.EncodingUTF8})
+	c, rec := newContext(http.MethodPost, "/", string(body))
+
+	var entryHash string
+
+	// Assertions
+	if assert.NoError(t, testAPI.createEntry(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp struct {
+			Result *model.Entry `json:"result"`
+		}
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp)) {
+			assert.Equal(t, content, resp.Result.Content)
+			assert.Equal(t, []string{extID}, []string(resp.Result.ExtIDs))
+			entryHash = resp.Result.EntryHash
+		}
+	}
+
+	// Get entry in hex encoding
+	c, rec = newContext(http.MethodGet, "/?encoding=hex", "")
+	c.SetParamNames("entryhash")
+	c.SetParamValues(entryHash)
+
+	if assert.NoError(t, testAPI.getEntry(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp struct {
+			Result *model.Entry `json:"result"`
+		}
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp)) {
+			assert.Equal(t, hex.EncodeToString([]byte(content)), resp.Result.Content)
+			assert.Equal(t, []string{hex.EncodeToString([]byte(extID))}, []string(resp.Result.ExtIDs))
+		}
+	}
+
+	// Mixed encodings in query & body, values not in declared encoding, unknown encoding are rejected
+	for target, body := range map[string]string{
+		"/?encoding=hex":   string(body),
+		"/":                `{"chainId":"` + tc.ChainID + `","extIds":["not hex"],"encoding":"hex"}`,
+		"/?encoding=utf16": `{"chainId":"` + tc.ChainID + `","extIds":["` + extID + `"]}`,
+	} {
+		c, rec = newContext(http.MethodPost, target, body)
+		if assert.NoError(t, testAPI.createEntry(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	}
+
+	// Delete test user
+	testAPI.service.DeleteUser(tu)
+
+}
 
 func TestGetEntry(t *testing.T) {
 
@@ -862,7 +1826,6 @@ func TestGetEntry(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	testAPI.user = tu
 
 	tc := &model.Chain{}
 	tc.ExtIDs = []string{strconv.FormatInt(time.Now().UnixNano(), 10)}
@@ -875,6 +1838,7 @@ func TestGetEntry(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
 
 	c.SetParamNames("entryhash")
 	c.SetParamValues(tc.Base64Decode().FirstEntryHash())
@@ -890,6 +1854,242 @@ func TestGetEntry(t *testing.T) {
 
 }
 
+func TestGetEntryRestrictedKey(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+
+	// Create test user, chain and key restricted to another chain
+	tu := &model.User{}
+	tu.Name = "Test"
+	tu.AccessToken = tu.GenerateAccessToken(32)
+	tu, err := testAPI.service.CreateUser(tu)
+	if err != nil {
+		t.Error(err)
+	}
+
+	tc := &model.Chain{}
+	tc.ExtIDs = []string{strconv.FormatInt(time.Now().UnixNano(), 10)}
+	tc, err = testAPI.service.CreateChain(tc.Base64Encode(), tu)
+	if err != nil {
+		t.Error(err)
+	}
+
+	tk, err := testAPI.service.CreateAPIKey(&model.APIKey{Name: "Restricted", ChainIDs: []string{strings.Repeat("0", 64)}}, tu)
+	if err != nil {
+		t.Error(err)
+	}
+	ku := testAPI.service.CheckUser(tk.Secret)
+
+	// Entry of another chain & unknown entry are denied before fetching from Factom
+	unknown := strings.Repeat("f", 64)
+	for _, entryHash := range []string{tc.Base64Decode().FirstEntryHash(), unknown} {
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(userContextKey, ku)
+
+		c.SetParamNames("entryhash")
+		c.SetParamValues(entryHash)
+
+		// Assertions
+		if assert.NoError(t, testAPI.getEntry(c)) {
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+		}
+
+	}
+	assert.Nil(t, testAPI.service.GetEntryChain(&model.Entry{EntryHash: unknown}))
+
+	// Delete test user
+	testAPI.service.DeleteUser(tu)
+
+}
+
+func TestGetEntryContent(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+
+	// Create test user and chains with identical content, which is stored once
+	tu := &model.User{}
+	tu.Name = "Test"
+	tu.AccessToken = tu.GenerateAccessToken(32)
+	tu, err := testAPI.service.CreateUser(tu)
+	if err != nil {
+		t.Error(err)
+	}
+
+	content := strings.Repeat("Factom Open API ", 100)
+	var chains []*model.Chain
+	for i := 0; i < 2; i++ {
+		tc := &model.Chain{}
+		tc.ExtIDs = []string{strconv.FormatInt(time.Now().UnixNano(), 10)}
+		tc.Content = content
+		tc, err = testAPI.service.CreateChain(tc.Base64Encode(), tu)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chains = append(chains, tc)
+	}
+
+	for _, tc := range chains {
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(userContextKey, tu)
+
+		c.SetParamNames("entryhash")
+		c.SetParamValues(tc.Base64Decode().FirstEntryHash())
+
+		// Assertions
+		if assert.NoError(t, testAPI.getEntry(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var resp struct {
+				Result *model.Entry `json:"result"`
+			}
+			if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp)) {
+				assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(content)), resp.Result.Content)
+			}
+		}
+
+	}
+
+	// Delete test user
+	testAPI.service.DeleteUser(tu)
+
+}
+
+func TestRawEntry(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+
+	// Create test user and chain
+	tu := &model.User{}
+	tu.Name = "Test"
+	tu.AccessToken = tu.GenerateAccessToken(32)
+	tu, err := testAPI.service.CreateUser(tu)
+	if err != nil {
+		t.Error(err)
+	}
+
+	tc := &model.Chain{}
+	tc.ExtIDs = []string{strconv.FormatInt(time.Now().UnixNano(), 10)}
+	tc, err = testAPI.service.CreateChain(tc.Base64Encode(), tu)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Raw body with ExtIDs in headers
+	content := []byte(`{"name":"Factom Open API"}`)
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(content))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Add(HeaderExtID, "first")
+	req.Header.Add(HeaderExtID, "second")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
+	c.SetParamNames("chainid")
+	c.SetParamValues(tc.ChainID)
+
+	var entryHash string
+
+	// Assertions
+	if assert.NoError(t, testAPI.createRawEntry(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp struct {
+			Result *model.Entry `json:"result"`
+		}
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp)) {
+			assert.Equal(t, base64.StdEncoding.EncodeToString(content), resp.Result.Content)
+			assert.Equal(t, []string{base64.StdEncoding.EncodeToString([]byte("first")), base64.StdEncoding.EncodeToString([]byte("second"))}, []string(resp.Result.ExtIDs))
+			assert.Equal(t, echo.MIMEApplicationJSON, resp.Result.ContentType)
+			entryHash = resp.Result.EntryHash
+		}
+	}
+
+	// Content is served decoded with stored MIME type
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
+	c.SetParamNames("entryhash")
+	c.SetParamValues(entryHash)
+
+	if assert.NoError(t, testAPI.getEntryContent(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, content, rec.Body.Bytes())
+	}
+
+	// Multipart form with binary ExtID & content file
+	binary := []byte{0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a}
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	mw.WriteField(FormExtIDs, string([]byte{0x00, 0xff}))
+	fw, _ := mw.CreateFormFile(FormContent, "image.png")
+	fw.Write(binary)
+	mw.Close()
+
+	req = httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set(echo.HeaderContentType, mw.FormDataContentType())
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
+	c.SetParamNames("chainid")
+	c.SetParamValues(tc.ChainID)
+
+	if assert.NoError(t, testAPI.createRawEntry(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp struct {
+			Result *model.Entry `json:"result"`
+		}
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp)) {
+			assert.Equal(t, base64.StdEncoding.EncodeToString(binary), resp.Result.Content)
+			assert.Equal(t, []string{base64.StdEncoding.EncodeToString([]byte{0x00, 0xff})}, []string(resp.Result.ExtIDs))
+			// generic type of file part is not stored, content type is sniffed
+			assert.Empty(t, resp.Result.ContentType)
+			entryHash = resp.Result.EntryHash
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
+	c.SetParamNames("entryhash")
+	c.SetParamValues(entryHash)
+
+	if assert.NoError(t, testAPI.getEntryContent(c)) {
+		assert.Equal(t, "image/png", rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, binary, rec.Body.Bytes())
+	}
+
+	// Content larger than 10KB is rejected
+	req = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(make([]byte, model.MaxEntrySize+1)))
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
+	c.SetParamNames("chainid")
+	c.SetParamValues(tc.ChainID)
+
+	if assert.NoError(t, testAPI.createRawEntry(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+
+	// Delete test user
+	testAPI.service.DeleteUser(tu)
+
+}
+
 func TestGetUser(t *testing.T) {
 
 	// Setup
@@ -904,12 +2104,12 @@ func TestGetUser(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	testAPI.user = tu
 
 	// Setup echo context
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
 
 	// Assertions
 	if assert.NoError(t, testAPI.getUser(c)) {
@@ -921,3 +2121,461 @@ func TestGetUser(t *testing.T) {
 	testAPI.service.DeleteUser(tu)
 
 }
+
+func TestInvalidKeyNotLogged(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	key := "invalidsecretkeyvalue"
+	req := httptest.NewRequest(http.MethodGet, "/v1/user", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+key)
+	rec := httptest.NewRecorder()
+	testAPI.HTTP.ServeHTTP(rec, req)
+
+	// Assertions
+	// only prefix of the rejected key is logged
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, buf.String(), key[:model.APIKeyPrefixLength])
+	assert.NotContains(t, buf.String(), key)
+
+}
+
+func TestRequireScope(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+
+	// Create test user with read-only key
+	tu := &model.User{}
+	tu.Name = "Test"
+	tu, err := testAPI.service.CreateUser(tu)
+	if err != nil {
+		t.Error(err)
+	}
+	tk, err := testAPI.service.CreateAPIKey(&model.APIKey{Name: "Read-only", Scopes: []string{model.ScopeChainsRead}}, tu)
+	if err != nil {
+		t.Error(err)
+	}
+	tu = testAPI.service.CheckUser(tk.Secret)
+
+	// Setup echo context
+	f := make(url.Values)
+	f.Set("extIds", base64.StdEncoding.EncodeToString([]byte(strconv.FormatInt(time.Now().UnixNano(), 10))))
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(f.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
+
+	// Assertions
+	if assert.NoError(t, testAPI.requireScope(model.ScopeChainsWrite)(testAPI.createChain)(c)) {
+		t.Logf(rec.Body.String())
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	}
+
+	// scope is checked before rate limit, so requests without the scope don't consume write tokens
+	tu.RateLimitWrite = 1
+	if err := testAPI.service.UpdateUser(tu); err != nil {
+		t.Error(err)
+	}
+	for i := 0; i < 2; i++ {
+		req = httptest.NewRequest(http.MethodDelete, "/v1/chains/"+strings.Repeat("f", 64), nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+tk.Secret)
+		rec = httptest.NewRecorder()
+		testAPI.HTTP.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Empty(t, rec.Header().Get("X-RateLimit-Remaining"))
+	}
+
+	// Delete test user
+	testAPI.service.DeleteUser(tu)
+
+}
+
+func TestRateLimit(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+
+	// Create test user with 1 write request per minute
+	tu := &model.User{}
+	tu.Name = "Test"
+	tu.RateLimitWrite = 1
+	tu, err := testAPI.service.CreateUser(tu)
+	if err != nil {
+		t.Error(err)
+	}
+
+	ok := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+
+	// First request is allowed
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
+
+	if assert.NoError(t, testAPI.rateLimit(model.RateLimitGroupWrite)(ok)(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "0", rec.Header().Get("X-RateLimit-Remaining"))
+	}
+
+	// Second request is rejected
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.Set(userContextKey, tu)
+
+	if assert.NoError(t, testAPI.rateLimit(model.RateLimitGroupWrite)(ok)(c)) {
+		t.Logf(rec.Body.String())
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	}
+
+	// Delete test user
+	testAPI.service.DeleteUser(tu)
+
+}
+
+func TestCheckChainAccess(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Create test user with namespace-restricted key
+	namespace := base64.StdEncoding.EncodeToString([]byte("ns-" + strconv.FormatInt(time.Now().UnixNano(), 10)))
+	tu := &model.User{}
+	tu.Name = "Test"
+	tu.APIKey = &model.APIKey{Name: "Restricted", ExtIDNamespaces: []string{namespace}}
+	c.Set(userContextKey, tu)
+
+	allowed := &model.Chain{ExtIDs: []string{namespace, "dGVzdA=="}}
+	allowed.ChainID = allowed.Base64Decode().ID()
+	denied := &model.Chain{ExtIDs: []string{"dGVzdA==", namespace}}
+	denied.ChainID = denied.Base64Decode().ID()
+
+	// Assertions
+	assert.NoError(t, testAPI.checkChainAccess(allowed, c))
+	assert.Error(t, testAPI.checkChainAccess(denied, c))
+	assert.Error(t, testAPI.checkChainAccess(&model.Chain{ChainID: denied.ChainID}, c))
+
+}
+
+func TestFactomd(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(userContextKey, &model.User{Name: "Test"})
+
+	// Setup echo context
+	c.SetParamNames("method")
+	c.SetParamValues("heights")
+
+	// Assertions
+	if assert.NoError(t, testAPI.factomd(c)) {
+		t.Logf(rec.Body.String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+}
+
+func TestFactomdNotAllowed(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+
+	tests := map[string]int{
+		"commit-entry": http.StatusForbidden,  // write method, not allowed by default
+		"unknown":      http.StatusForbidden,  // unknown method
+		"chain-head":   http.StatusBadRequest, // chainid param is required
+	}
+
+	// rejected calls are audited with their outcome
+	audited := map[string][]string{
+		"commit-entry": {model.AuditActionFactomdWrite, model.AuditOutcomeDenied},
+		"unknown":      {model.AuditActionFactomdRead, model.AuditOutcomeDenied},
+		"chain-head":   {model.AuditActionFactomdRead, model.AuditOutcomeInvalid},
+	}
+	from := time.Now().Add(-time.Second)
+
+	for method, code := range tests {
+
+		// Setup echo context
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"chainid":"test"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(userContextKey, &model.User{Name: "Test"})
+		c.SetParamNames("method")
+		c.SetParamValues(method)
+
+		// Assertions
+		if assert.NoError(t, testAPI.factomd(c)) {
+			t.Logf(rec.Body.String())
+			assert.Equal(t, code, rec.Code, method)
+		}
+
+		logs, _ := testAPI.service.GetAuditLogs(&model.AuditLog{Action: audited[method][0], TargetID: method}, &from, nil, 0, 1, "desc")
+		if assert.Len(t, logs, 1, method) && assert.NotNil(t, logs[0].After, method) {
+			assert.Contains(t, string(logs[0].After.RawMessage), `"outcome":"`+audited[method][1]+`"`, method)
+		}
+
+	}
+
+}
+
+func TestMetrics(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+
+	get := func(path string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		testAPI.HTTP.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// metrics are disabled without token
+	testAPI.conf.API.MetricsToken = ""
+	assert.Equal(t, http.StatusNotFound, get("/metrics", "").Code)
+
+	testAPI.conf.API.MetricsToken = "metricstoken"
+
+	// requests are counted by route template
+	assert.Equal(t, http.StatusOK, get("/v1", "").Code)
+	get("/v1/unknown/"+strconv.FormatInt(time.Now().UnixNano(), 10), "")
+
+	// Assertions
+	assert.Equal(t, http.StatusUnauthorized, get("/metrics", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, get("/metrics", "wrongtoken").Code)
+
+	rec := get("/metrics", "metricstoken")
+	if assert.Equal(t, http.StatusOK, rec.Code) {
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentType), "text/plain")
+		assert.Contains(t, rec.Body.String(), "# TYPE foa_http_requests_total counter")
+		assert.Contains(t, rec.Body.String(), `foa_http_requests_total{method="GET",route="/v1",status="200"}`)
+		assert.Contains(t, rec.Body.String(), `foa_http_request_duration_seconds_bucket{method="GET",route="/v1",status="200",le="+Inf"}`)
+		assert.NotContains(t, rec.Body.String(), "/v1/unknown/")
+	}
+
+}
+
+func TestHealthz(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Assertions
+	if assert.NoError(t, testAPI.healthz(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "\"status\":\""+model.HealthOK+"\"")
+	}
+
+}
+
+func TestReadyz(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Assertions
+	if assert.NoError(t, testAPI.readyz(c)) {
+		t.Logf(rec.Body.String())
+
+		readiness := &model.Readiness{}
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), readiness)) {
+			for _, name := range []string{model.HealthCheckDB, model.HealthCheckFactomd, model.HealthCheckWallet, model.HealthCheckQueue, model.HealthCheckParser} {
+				assert.Contains(t, readiness.Checks, name)
+			}
+			// updates parser is not running in tests
+			assert.Equal(t, model.HealthDegraded, readiness.Checks[model.HealthCheckParser].Status)
+			if readiness.IsReady() {
+				assert.Equal(t, http.StatusOK, rec.Code)
+			} else {
+				assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+			}
+		}
+	}
+
+}
+
+func TestRequestID(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+
+	get := func(requestID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1", nil)
+		if requestID != "" {
+			req.Header.Set(echo.HeaderXRequestID, requestID)
+		}
+		rec := httptest.NewRecorder()
+		testAPI.HTTP.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Assertions
+	// ID of the client is kept
+	assert.Equal(t, "client-request.1", get("client-request.1").Header().Get(echo.HeaderXRequestID))
+
+	// ID is generated if not set or invalid
+	generated := get("").Header().Get(echo.HeaderXRequestID)
+	assert.Len(t, generated, 32)
+	assert.NotEqual(t, generated, get("").Header().Get(echo.HeaderXRequestID))
+
+	invalid := get("invalid id\n")
+	assert.Len(t, invalid.Header().Get(echo.HeaderXRequestID), 32)
+
+}
+
+func TestTracing(t *testing.T) {
+
+	// Setup
+	testAPI := NewTestAPI()
+
+	if _, err := tracing.Setup("", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider(sdktrace.NewSimpleSpanProcessor(exporter), sdktrace.ParentBased(sdktrace.AlwaysSample()))
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	get := func(path string, traceparent string) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if traceparent != "" {
+			req.Header.Set("traceparent", traceparent)
+		}
+		testAPI.HTTP.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	attr := func(span tracetest.SpanStub, key string) attribute.Value {
+		for _, kv := range span.Attributes {
+			if string(kv.Key) == key {
+				return kv.Value
+			}
+		}
+		return attribute.Value{}
+	}
+
+	// Assertions
+	// server span continues the trace of the client
+	get("/v1", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		span := spans[0]
+		assert.Equal(t, "GET /v1", span.Name)
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+		assert.Equal(t, "/v1", attr(span, tracing.AttrHTTPRoute).AsString())
+		assert.Equal(t, int64(http.StatusOK), attr(span, tracing.AttrHTTPStatusCode).AsInt64())
+		assert.Len(t, attr(span, tracing.AttrRequestID).AsString(), 32)
+	}
+
+	// unknown paths don't create new span names
+	exporter.Reset()
+	get("/unknown/path", "")
+
+	spans = exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "GET unmatched", spans[0].Name)
+		assert.False(t, spans[0].Parent.IsValid())
+		assert.Equal(t, int64(http.StatusNotFound), attr(spans[0], tracing.AttrHTTPStatusCode).AsInt64())
+	}
+
+	// store & factomd spans are children of the request span
+	tu := &model.User{}
+	tu.Name = "Test"
+	tu.AccessToken = tu.GenerateAccessToken(32)
+	tu, err := testAPI.service.CreateUser(tu)
+	if err != nil {
+		t.Error(err)
+	}
+
+	exporter.Reset()
+	req := httptest.NewRequest(http.MethodGet, "/v1/chains/"+strings.Repeat("f", 64), nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+tu.AccessToken)
+	testAPI.HTTP.ServeHTTP(httptest.NewRecorder(), req)
+
+	children := make(map[string]bool)
+	spans = exporter.GetSpans()
+	for _, request := range spans {
+		if request.Name != "GET /v1/chains/:chainid" {
+			continue
+		}
+		for _, span := range spans {
+			if span.Parent.SpanID() == request.SpanContext.SpanID() {
+				children[span.Name] = true
+			}
+		}
+	}
+	assert.True(t, children["gorm.query"])
+	assert.True(t, children["factomd chain-head"])
+
+	testAPI.service.DeleteUser(tu)
+
+	// spans are exported to OTLP/HTTP endpoint as protobuf
+	var payload coltracepb.ExportTraceServiceRequest
+	var path string
+
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		body, _ := ioutil.ReadAll(r.Body)
+		proto.Unmarshal(body, &payload)
+	}))
+	defer collector.Close()
+
+	otlp, err := tracing.NewOTLPExporter(collector.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otel.SetTracerProvider(tracing.NewProvider(sdktrace.NewSimpleSpanProcessor(otlp), sdktrace.AlwaysSample()))
+
+	get("/v1", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	assert.Equal(t, tracing.OTLPTracesPath, path)
+	if assert.Len(t, payload.ResourceSpans, 1) && assert.Len(t, payload.ResourceSpans[0].ScopeSpans, 1) && assert.Len(t, payload.ResourceSpans[0].ScopeSpans[0].Spans, 1) {
+		span := payload.ResourceSpans[0].ScopeSpans[0].Spans[0]
+		assert.Equal(t, "GET /v1", span.Name)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", hex.EncodeToString(span.TraceId))
+		assert.Equal(t, "00f067aa0ba902b7", hex.EncodeToString(span.ParentSpanId))
+		assert.Equal(t, int32(trace.SpanKindServer), int32(span.Kind))
+	}
+
+	assert.NoError(t, provider.Shutdown(context.Background()))
+
+}
@@ -0,0 +1,483 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/DeFacto-Team/Factom-Open-API/api"
+	"github.com/DeFacto-Team/Factom-Open-API/config"
+	"github.com/DeFacto-Team/Factom-Open-API/model"
+	"github.com/DeFacto-Team/Factom-Open-API/service"
+	"github.com/DeFacto-Team/Factom-Open-API/store"
+	"github.com/go-yaml/yaml"
+)
+
+// command is a subcommand of the binary, e.g. `foa user list`
+type command struct {
+	name  string
+	args  string
+	usage string
+	// minimal number of arguments after the name
+	minArgs int
+	run     func(configFile string, args []string) error
+}
+
+var commands = []*command{
+	{name: "serve", usage: "start Factom Open API (default command)", run: serve},
+	{name: "config print", usage: "print effective config with secrets redacted", run: printConfig},
+	{name: "migrate up", usage: "apply all SQL migrations", run: migrateUp},
+	{name: "migrate down", args: "[n]", usage: "roll back the last n SQL migrations (1 by default)", run: migrateDown},
+	{name: "migrate status", usage: "list SQL migrations and when they were applied", run: migrateStatus},
+	{name: "user create", args: "<name> [usageLimit]", usage: "create user and print its access token", minArgs: 1, run: userCreate},
+	{name: "user list", usage: "list users", run: userList},
+	{name: "user rotate", args: "<id>", usage: "replace default API key of the user and print the new access token", minArgs: 1, run: userRotate},
+	{name: "user delete", args: "<id>", usage: "delete user", minArgs: 1, run: userDelete},
+	{name: "queue list", usage: "list queue of writes to the blockchain", run: queueList},
+	{name: "queue retry", args: "<id|all>", usage: "process failed queue item(s) on the next iteration", minArgs: 1, run: queueRetry},
+	{name: "queue purge", args: "[all]", usage: "delete processed queue items (all items with 'all')", run: queuePurge},
+	{name: "chain resync", args: "<chainid>", usage: "parse all entries of the local chain again", minArgs: 1, run: chainResync},
+	{name: "ec generate", usage: "generate new EC address", run: ecGenerate},
+	{name: "ec balance", args: "[address]", usage: "print balance of EC or Es address (Es address from config by default)", run: ecBalance},
+}
+
+var chainIDRegexp = regexp.MustCompile("^[0-9a-fA-F]{64}$")
+
+// findCommand returns command matching args & remaining arguments
+func findCommand(args []string) (*command, []string) {
+
+	if len(args) == 0 {
+		return commands[0], nil
+	}
+
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) >= len(words) && strings.Join(args[:len(words)], " ") == cmd.name {
+			return cmd, args[len(words):]
+		}
+	}
+
+	return nil, nil
+
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [flags] [command]\n\nCommands:\n", os.Args[0])
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s %s\t%s\n", cmd.name, cmd.args, cmd.usage)
+	}
+	w.Flush()
+	fmt.Fprintf(out, `
+Every config param may be overridden by environment variable (e.g. %s_STORE_HOST)
+or by flag (e.g. -store-host). Flags override environment variables, environment variables override config file.
+
+Flags:
+`, config.EnvPrefix)
+	flag.PrintDefaults()
+}
+
+func serve(configFile string, args []string) error {
+
+	startAPI(configFile)
+	return nil
+
+}
+
+// printConfig prints config merged from config file, environment variables & flags
+func printConfig(configFile string, args []string) error {
+
+	conf, err := config.NewConfig(configFile)
+	if err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(conf.Redacted())
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(string(out))
+	return nil
+
+}
+
+// openStore connects to DB without applying migrations
+func openStore(configFile string) (store.Store, error) {
+
+	conf, err := config.NewConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	conf.ApplyGlobals()
+
+	return store.NewStore(conf, false)
+
+}
+
+// openService creates service without wallet, so it can not write on the blockchain
+func openService(configFile string) (service.Service, func(), error) {
+
+	st, err := openStore(configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return service.NewService(st, nil), func() { st.Close() }, nil
+
+}
+
+// auditCLI writes action made via command line into audit log
+func auditCLI(s service.Service, action string, targetType string, targetID interface{}) {
+
+	s.Audit(&model.AuditLog{
+		ActorType:  model.AuditActorSystem,
+		ActorName:  model.AuditActorCLI,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   fmt.Sprint(targetID),
+	})
+
+}
+
+func migrateUp(configFile string, args []string) error {
+
+	st, err := openStore(configFile)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	n, err := st.Migrate(true, 0)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Applied %d migration(s)\n", n)
+	return nil
+
+}
+
+func migrateDown(configFile string, args []string) error {
+
+	max := 1
+	if len(args) > 0 {
+		var err error
+		if max, err = strconv.Atoi(args[0]); err != nil || max < 1 {
+			return fmt.Errorf("Number of migrations expected to be a positive integer, '%s' received", args[0])
+		}
+	}
+
+	st, err := openStore(configFile)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	n, err := st.Migrate(false, max)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Rolled back %d migration(s)\n", n)
+	return nil
+
+}
+
+func migrateStatus(configFile string, args []string) error {
+
+	st, err := openStore(configFile)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	migrations, err := st.GetMigrations()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MIGRATION\tAPPLIED AT")
+	for _, m := range migrations {
+		fmt.Fprintf(w, "%s\t%s\n", m.ID, formatTime(m.AppliedAt, "pending"))
+	}
+	return w.Flush()
+
+}
+
+func userCreate(configFile string, args []string) error {
+
+	user := &model.User{Name: args[0], Status: 1}
+	user.AccessToken = user.GenerateAccessToken(api.AccessTokenLength)
+
+	if len(args) > 1 {
+		limit, err := strconv.Atoi(args[1])
+		if err != nil || limit < 0 {
+			return fmt.Errorf("Usage limit expected to be a non-negative integer, '%s' received", args[1])
+		}
+		user.UsageLimit = limit
+	}
+
+	s, closeStore, err := openService(configFile)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	user, err = s.CreateUser(user)
+	if err != nil {
+		return err
+	}
+
+	auditCLI(s, model.AuditActionUserCreate, model.AuditActorUser, user.ID)
+
+	fmt.Printf("User '%s' created, id=%d\nAccess token: %s\n", user.Name, user.ID, user.AccessToken)
+	return nil
+
+}
+
+func userList(configFile string, args []string) error {
+
+	s, closeStore, err := openService(configFile)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tSTATUS\tUSAGE\tLIMIT\tPERIOD")
+	for _, u := range s.GetUsers(&model.User{}) {
+		period := u.UsagePeriod
+		if period == model.UsagePeriodLifetime {
+			period = "lifetime"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d\t%s\n", u.ID, u.Name, u.Status, u.Usage, u.UsageLimit, period)
+	}
+	return w.Flush()
+
+}
+
+func userRotate(configFile string, args []string) error {
+
+	s, closeStore, err := openService(configFile)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	user, err := getUserByArg(s, args[0])
+	if err != nil {
+		return err
+	}
+
+	user, err = s.RotateUserToken(user)
+	if err != nil {
+		return err
+	}
+
+	auditCLI(s, model.AuditActionUserRotateToken, model.AuditActorUser, user.ID)
+
+	fmt.Printf("Access token of user '%s' rotated\nAccess token: %s\n", user.Name, user.AccessToken)
+	return nil
+
+}
+
+func userDelete(configFile string, args []string) error {
+
+	s, closeStore, err := openService(configFile)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	user, err := getUserByArg(s, args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := s.DeleteUser(user); err != nil {
+		return err
+	}
+
+	auditCLI(s, model.AuditActionUserDelete, model.AuditActorUser, user.ID)
+
+	fmt.Printf("User '%s' deleted\n", user.Name)
+	return nil
+
+}
+
+func getUserByArg(s service.Service, arg string) (*model.User, error) {
+
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return nil, fmt.Errorf("User ID expected to be an integer, '%s' received", arg)
+	}
+
+	user := s.GetUser(&model.User{ID: id})
+	if user == nil {
+		return nil, fmt.Errorf("User %d not found", id)
+	}
+
+	return user, nil
+
+}
+
+func queueList(configFile string, args []string) error {
+
+	s, closeStore, err := openService(configFile)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tACTION\tCREATED AT\tTRIES\tNEXT TRY AT\tPROCESSED AT\tRESULT\tERROR")
+	for _, q := range s.GetQueue(&model.Queue{}) {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\t%s\t%s\n", q.ID, q.Action, formatTime(&q.CreatedAt, ""), q.TryCount,
+			formatTime(q.NextTryAt, "-"), formatTime(q.ProcessedAt, "-"), q.Result, q.Error)
+	}
+	return w.Flush()
+
+}
+
+func queueRetry(configFile string, args []string) error {
+
+	s, closeStore, err := openService(configFile)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	var items []*model.Queue
+
+	if args[0] == "all" {
+		for _, q := range s.GetQueue(&model.Queue{}) {
+			if q.ProcessedAt == nil && q.NextTryAt != nil {
+				items = append(items, q)
+			}
+		}
+	} else {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("Queue item ID expected to be an integer or 'all', '%s' received", args[0])
+		}
+		items = append(items, &model.Queue{ID: id})
+	}
+
+	for _, q := range items {
+		if err := s.RetryQueue(q); err != nil {
+			return err
+		}
+		auditCLI(s, model.AuditActionQueueRetry, "queue", q.ID)
+	}
+
+	fmt.Printf("%d queue item(s) will be processed on the next iteration\n", len(items))
+	return nil
+
+}
+
+func queuePurge(configFile string, args []string) error {
+
+	all := len(args) > 0 && args[0] == "all"
+
+	s, closeStore, err := openService(configFile)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	n, err := s.PurgeQueue(all)
+	if err != nil {
+		return err
+	}
+
+	auditCLI(s, model.AuditActionQueuePurge, "queue", strings.Join(args, " "))
+
+	fmt.Printf("Deleted %d queue item(s)\n", n)
+	return nil
+
+}
+
+func chainResync(configFile string, args []string) error {
+
+	if !chainIDRegexp.MatchString(args[0]) {
+		return fmt.Errorf("Chain ID expected to be a hex string of 64 chars, '%s' received", args[0])
+	}
+
+	s, closeStore, err := openService(configFile)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	chain := &model.Chain{ChainID: strings.ToLower(args[0])}
+	if err := s.ResyncChain(chain); err != nil {
+		return err
+	}
+
+	auditCLI(s, model.AuditActionChainResync, "chain", chain.ChainID)
+
+	fmt.Printf("Chain %s will be parsed again by running API\n", chain.ChainID)
+	return nil
+
+}
+
+func ecGenerate(configFile string, args []string) error {
+
+	ec := model.GenerateEC()
+	if ec == nil {
+		return fmt.Errorf("EC keypair generation error")
+	}
+
+	fmt.Printf("Es address: %s\nEC address: %s\n", ec.EsAddress, ec.ECAddress)
+	return nil
+
+}
+
+func ecBalance(configFile string, args []string) error {
+
+	conf, err := config.NewConfig(configFile)
+	if err != nil {
+		return err
+	}
+	conf.ApplyGlobals()
+
+	address := conf.Factom.EsAddress
+	if len(args) > 0 {
+		address = args[0]
+	}
+
+	if address == "" {
+		return fmt.Errorf("Address is not set and there is no Es address in config")
+	}
+
+	ec := &model.EC{ECAddress: address}
+	if strings.HasPrefix(address, "Es") {
+		if ec = model.GetEC(address); ec == nil {
+			return fmt.Errorf("Invalid Es address")
+		}
+	}
+
+	ec.GetBalanceFromFactom()
+
+	fmt.Printf("EC address: %s\nBalance: %d EC\n", ec.ECAddress, ec.Balance)
+	return nil
+
+}
+
+func formatTime(t *time.Time, empty string) string {
+
+	if t == nil {
+		return empty
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+
+}
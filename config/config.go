@@ -1,15 +1,42 @@
 package config
 
 import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/DeFacto-Team/Factom-Open-API/content"
+	"github.com/DeFacto-Team/Factom-Open-API/logging"
+	"github.com/DeFacto-Team/Factom-Open-API/proxy"
+	"github.com/FactomProject/factom"
 	"github.com/go-yaml/yaml"
 	"github.com/jinzhu/configor"
 	"github.com/mcuadros/go-defaults"
-	"io/ioutil"
-	"os"
+	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// RedactedValue replaces secrets in redacted config
+	RedactedValue = "[redacted]"
+	// prefix of environment variables overriding config, e.g. FOA_STORE_HOST
+	EnvPrefix = "FOA"
+	// updates parser modes: check chain head of every tracked chain on every new dblock
+	// or read every new dblock & parse only chains changed in it
+	SyncModeChainHead = "chainhead"
+	SyncModeDBlock    = "dblock"
+)
+
+// flags overriding config, registered by BindFlags
+var boundFlags *flag.FlagSet
+
 // App config struct
 type Config struct {
+	// credentials of the first superadmin, used only if there are no admins in DB
 	Admin struct {
 		User     string `default:"" json:"adminUser" form:"adminUser" query:"adminUser"`
 		Password string `default:"" json:"adminPassword" form:"adminPassword" query:"adminPassword"`
@@ -18,6 +45,18 @@ type Config struct {
 		HTTPPort int  `required:"true" default:"8081" json:"apiHTTPPort" form:"apiHTTPPort" query:"apiHTTPPort"`
 		Logging  bool `required:"true" default:"true" json:"apiLogging" form:"apiLogging" query:"apiLogging"`
 		LogLevel int  `required:"true" default:"4" json:"apiLogLevel" form:"apiLogLevel" query:"apiLogLevel"`
+		// json or text
+		LogFormat string `default:"json" json:"apiLogFormat" form:"apiLogFormat" query:"apiLogFormat"`
+		// seconds to complete requests & background jobs on SIGTERM
+		ShutdownTimeout int `default:"30" json:"apiShutdownTimeout" form:"apiShutdownTimeout" query:"apiShutdownTimeout"`
+		// bearer token to scrape /metrics, metrics are disabled if empty
+		MetricsToken string `default:"" json:"apiMetricsToken" form:"apiMetricsToken" query:"apiMetricsToken"`
+	}
+	// requests per minute per user, -1 — no limit
+	RateLimit struct {
+		Read  int `default:"600" json:"rateLimitRead" form:"rateLimitRead" query:"rateLimitRead"`
+		Write int `default:"120" json:"rateLimitWrite" form:"rateLimitWrite" query:"rateLimitWrite"`
+		Proxy int `default:"120" json:"rateLimitProxy" form:"rateLimitProxy" query:"rateLimitProxy"`
 	}
 	Store struct {
 		Host     string `required:"true" default:"foa-db" json:"storeHost" form:"storeHost" query:"storeHost"`
@@ -25,18 +64,61 @@ type Config struct {
 		User     string `required:"true" default:"postgres" json:"storeUser" form:"storeUser" query:"storeUser"`
 		Password string `required:"true" default:"postgres" json:"storePassword" form:"storePassword" query:"storePassword"`
 		DBName   string `required:"true" default:"postgres" json:"storeDBName" form:"storeDBName" query:"storeDBName"`
+		// backend of entries content store: pg or fs
+		ContentBackend string `default:"pg" json:"storeContentBackend" form:"storeContentBackend" query:"storeContentBackend"`
+		// directory of fs content store
+		ContentPath string `default:"" json:"storeContentPath" form:"storeContentPath" query:"storeContentPath"`
 	}
 	Factom struct {
 		URL       string `default:"https://api.factomd.net" json:"factomURL" form:"factomURL" query:"factomURL"`
 		User      string `default:"" json:"factomUser" form:"factomUser" query:"factomUser"`
 		Password  string `default:"" json:"factomPassword" form:"factomPassword" query:"factomPassword"`
 		EsAddress string `default:"" json:"factomEsAddress" form:"factomEsAddress" query:"factomEsAddress"`
+		// factomd methods allowed via generic factomd interface, all read methods by default
+		ProxyMethods []string `json:"factomProxyMethods" form:"factomProxyMethods" query:"factomProxyMethods"`
+	}
+	Parser struct {
+		// chainhead or dblock
+		SyncMode string `default:"chainhead" json:"parserSyncMode" form:"parserSyncMode" query:"parserSyncMode"`
+		// register every chain on the network by reading dblocks from IndexFromHeight & parse their history
+		Index           bool `default:"false" json:"parserIndex" form:"parserIndex" query:"parserIndex"`
+		IndexFromHeight int  `default:"0" json:"parserIndexFromHeight" form:"parserIndexFromHeight" query:"parserIndexFromHeight"`
+		// entries of chains, which are not pinned & not bound to users, are purged after this number of days, 0 — never
+		RetentionDays int `default:"0" json:"parserRetentionDays" form:"parserRetentionDays" query:"parserRetentionDays"`
+	}
+	Tracing struct {
+		// OTLP/HTTP endpoint of OpenTelemetry collector, e.g. http://otel-collector:4318, tracing is disabled if empty
+		Endpoint string `default:"" json:"tracingEndpoint" form:"tracingEndpoint" query:"tracingEndpoint"`
+		// share of new traces to be sampled, 0-1
+		SampleRatio float64 `default:"1" json:"tracingSampleRatio" form:"tracingSampleRatio" query:"tracingSampleRatio"`
 	}
 }
 
 // Create config from configFile
 func NewConfig(configFile string) (*Config, error) {
 
+	config, err := LoadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// environment variables override config file
+	if err := configor.New(&configor.Config{ENVPrefix: EnvPrefix}).Load(config); err != nil {
+		return nil, err
+	}
+
+	// command line flags override everything
+	if err := applyFlags(config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// LoadFile reads configFile over defaults without environment & flags overrides,
+// so it can be changed & saved without persisting overrides into the file
+func LoadFile(configFile string) (*Config, error) {
+
 	config := new(Config)
 	defaults.SetDefaults(config)
 
@@ -48,32 +130,275 @@ func NewConfig(configFile string) (*Config, error) {
 		}
 	}
 
-	if err := configor.Load(config); err != nil {
-		return nil, err
-	}
 	return config, nil
+
 }
 
+// SetChanged sets fields, which differ in oldConf & newConf, to values of newConf
+func (conf *Config) SetChanged(oldConf *Config, newConf *Config) {
+
+	oldValue := reflect.ValueOf(oldConf).Elem()
+	newValue := reflect.ValueOf(newConf).Elem()
+
+	eachField(conf, func(section reflect.StructField, field reflect.StructField, value reflect.Value) {
+		o := oldValue.FieldByName(section.Name).FieldByName(field.Name)
+		n := newValue.FieldByName(section.Name).FieldByName(field.Name)
+		if !reflect.DeepEqual(o.Interface(), n.Interface()) {
+			value.Set(n)
+		}
+	})
+
+}
+
+// BindFlags registers flag for every config field, e.g. -store-host for Store.Host.
+// Flags set in command line override config file & environment variables in every NewConfig call
+func BindFlags(fs *flag.FlagSet) {
+
+	eachField(&Config{}, func(section reflect.StructField, field reflect.StructField, _ reflect.Value) {
+		usage := fmt.Sprintf("overrides %s.%s (env %s)", section.Name, field.Name, envName(section, field))
+		fs.String(flagName(section, field), field.Tag.Get("default"), usage)
+	})
+
+	boundFlags = fs
+
+}
+
+// applyFlags sets config fields from the flags explicitly set in command line
+func applyFlags(conf *Config) error {
+
+	if boundFlags == nil {
+		return nil
+	}
+
+	set := make(map[string]string)
+	boundFlags.Visit(func(f *flag.Flag) {
+		set[f.Name] = f.Value.String()
+	})
+
+	var err error
+	eachField(conf, func(section reflect.StructField, field reflect.StructField, value reflect.Value) {
+		if v, ok := set[flagName(section, field)]; ok && err == nil {
+			if e := setField(value, v); e != nil {
+				err = fmt.Errorf("Invalid value of -%s flag: %s", flagName(section, field), e)
+			}
+		}
+	})
+
+	return err
+
+}
+
+// eachField calls fn for every field of every config section
+func eachField(conf *Config, fn func(section reflect.StructField, field reflect.StructField, value reflect.Value)) {
+
+	v := reflect.ValueOf(conf).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		section := v.Type().Field(i)
+		for j := 0; j < v.Field(i).NumField(); j++ {
+			fn(section, section.Type.Field(j), v.Field(i).Field(j))
+		}
+	}
+
+}
+
+// setField parses value the same way as configor parses environment variables (YAML),
+// lists may also be comma-separated
+func setField(field reflect.Value, value string) error {
+
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String && !strings.HasPrefix(value, "[") {
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+		return nil
+	}
+
+	return yaml.Unmarshal([]byte(value), field.Addr().Interface())
+
+}
+
+func flagName(section reflect.StructField, field reflect.StructField) string {
+	return strings.ToLower(section.Name + "-" + field.Name)
+}
+
+func envName(section reflect.StructField, field reflect.StructField) string {
+	return strings.ToUpper(EnvPrefix + "_" + section.Name + "_" + field.Name)
+}
+
+// UpdateConfig validates new config and atomically replaces configFile with it,
+// so the file is never left half-written
 func UpdateConfig(configFile string, newConf *Config) error {
 
+	if err := newConf.Validate(); err != nil {
+		return err
+	}
+
 	newYaml, err := yaml.Marshal(&newConf)
 	if err != nil {
 		return err
 	}
 
-	// write to file
-	f, err := os.Create("/tmp/newconfig")
+	// temp file should be on the same filesystem to be renamed atomically
+	f, err := ioutil.TempFile(filepath.Dir(configFile), ".config-*.yaml")
 	if err != nil {
 		return err
 	}
+	defer os.Remove(f.Name())
 
-	err = ioutil.WriteFile(configFile, newYaml, 0644)
-	if err != nil {
+	if _, err := f.Write(newYaml); err != nil {
+		f.Close()
 		return err
 	}
 
-	f.Close()
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(configFile); err == nil {
+		mode = info.Mode()
+	}
+
+	if err := os.Chmod(f.Name(), mode); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), configFile)
+
+}
+
+// Validate checks config values before they are saved or applied
+func (conf *Config) Validate() error {
+
+	if conf.API.HTTPPort < 1 || conf.API.HTTPPort > 65535 {
+		return fmt.Errorf("HTTP port expected to be in range 1-65535, %d received", conf.API.HTTPPort)
+	}
+
+	if conf.API.LogLevel < int(log.PanicLevel) || conf.API.LogLevel > int(log.TraceLevel) {
+		return fmt.Errorf("Log level expected to be in range %d-%d, %d received", log.PanicLevel, log.TraceLevel, conf.API.LogLevel)
+	}
+
+	if conf.API.LogFormat != logging.FormatJSON && conf.API.LogFormat != logging.FormatText {
+		return fmt.Errorf("Log format expected to be '%s' or '%s', '%s' received", logging.FormatJSON, logging.FormatText, conf.API.LogFormat)
+	}
+
+	if conf.API.ShutdownTimeout < 1 {
+		return fmt.Errorf("Shutdown timeout expected to be at least 1 second, %d received", conf.API.ShutdownTimeout)
+	}
+
+	for name, limit := range map[string]int{"read": conf.RateLimit.Read, "write": conf.RateLimit.Write, "proxy": conf.RateLimit.Proxy} {
+		if limit < -1 {
+			return fmt.Errorf("Rate limit '%s' expected to be -1 (no limit) or greater, %d received", name, limit)
+		}
+	}
+
+	if conf.Store.Host == "" || conf.Store.User == "" || conf.Store.DBName == "" {
+		return fmt.Errorf("Store host, user & database name are required")
+	}
+
+	if conf.Store.Port < 1 || conf.Store.Port > 65535 {
+		return fmt.Errorf("Store port expected to be in range 1-65535, %d received", conf.Store.Port)
+	}
+
+	if conf.Factom.URL != "" {
+		u, err := url.Parse(conf.Factom.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("Factomd URL expected to be http(s) URL, '%s' received", conf.Factom.URL)
+		}
+	}
+
+	if conf.Factom.EsAddress != "" {
+		if _, err := factom.GetECAddress(conf.Factom.EsAddress); err != nil {
+			return fmt.Errorf("Invalid Es address '%s'", conf.Factom.EsAddress)
+		}
+	}
+
+	if _, err := proxy.NewPolicy(conf.Factom.ProxyMethods); err != nil {
+		return err
+	}
+
+	if conf.Store.ContentBackend != content.BackendPostgres && conf.Store.ContentBackend != content.BackendFS {
+		return fmt.Errorf("Store content backend expected to be '%s' or '%s', '%s' received", content.BackendPostgres, content.BackendFS, conf.Store.ContentBackend)
+	}
+
+	if conf.Store.ContentBackend == content.BackendFS && conf.Store.ContentPath == "" {
+		return fmt.Errorf("Store content path expected to be set for '%s' content backend", content.BackendFS)
+	}
+
+	if conf.Parser.SyncMode != SyncModeChainHead && conf.Parser.SyncMode != SyncModeDBlock {
+		return fmt.Errorf("Parser sync mode expected to be '%s' or '%s', '%s' received", SyncModeChainHead, SyncModeDBlock, conf.Parser.SyncMode)
+	}
+
+	if conf.Parser.IndexFromHeight < 0 {
+		return fmt.Errorf("Parser index height expected to be 0 or greater, %d received", conf.Parser.IndexFromHeight)
+	}
+
+	if conf.Parser.RetentionDays < 0 {
+		return fmt.Errorf("Parser retention days expected to be 0 or greater, %d received", conf.Parser.RetentionDays)
+	}
+
+	if conf.Tracing.Endpoint != "" {
+		u, err := url.Parse(conf.Tracing.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("Tracing endpoint expected to be http(s) URL, '%s' received", conf.Tracing.Endpoint)
+		}
+	}
+
+	if conf.Tracing.SampleRatio <= 0 || conf.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("Tracing sample ratio expected to be in range (0, 1], %v received", conf.Tracing.SampleRatio)
+	}
 
 	return nil
 
 }
+
+// ApplyGlobals sets process-wide log level & factomd node
+func (conf *Config) ApplyGlobals() {
+
+	log.SetLevel(log.Level(conf.API.LogLevel))
+	if err := logging.SetFormat(conf.API.LogFormat); err != nil {
+		log.Error(err)
+	}
+
+	if conf.Factom.URL != "" {
+		factom.SetFactomdServer(conf.Factom.URL)
+	}
+	factom.SetFactomdRpcConfig(conf.Factom.User, conf.Factom.Password)
+
+}
+
+// RestartRequired checks if newConf changes settings, which can not be applied to running API
+func (conf *Config) RestartRequired(newConf *Config) bool {
+
+	return conf.Store != newConf.Store || conf.API.Logging != newConf.API.Logging || conf.Parser != newConf.Parser || conf.Tracing != newConf.Tracing
+
+}
+
+// Redacted returns copy of config with secrets replaced, so it can be logged or shown
+func (conf *Config) Redacted() *Config {
+
+	redacted := *conf
+
+	for _, secret := range []*string{
+		&redacted.Admin.Password,
+		&redacted.API.MetricsToken,
+		&redacted.Store.Password,
+		&redacted.Factom.Password,
+		&redacted.Factom.EsAddress,
+	} {
+		if *secret != "" {
+			*secret = RedactedValue
+		}
+	}
+
+	return &redacted
+
+}
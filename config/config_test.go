@@ -0,0 +1,75 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFile(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "foa-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(configFile, []byte("store:\n  host: db\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("FOA_STORE_PASSWORD", "secret")
+	defer os.Unsetenv("FOA_STORE_PASSWORD")
+
+	// environment overrides effective config only
+	conf, err := NewConfig(configFile)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "db", conf.Store.Host)
+		assert.Equal(t, "secret", conf.Store.Password)
+	}
+
+	fileConf, err := LoadFile(configFile)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "db", fileConf.Store.Host)
+		assert.Equal(t, "postgres", fileConf.Store.Password)
+	}
+
+	// missing file means defaults
+	fileConf, err = LoadFile(filepath.Join(dir, "missing.yaml"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "foa-db", fileConf.Store.Host)
+	}
+
+}
+
+func TestSetChanged(t *testing.T) {
+
+	oldConf := &Config{}
+	oldConf.Store.Password = "from env"
+	oldConf.API.LogLevel = 4
+	oldConf.Factom.ProxyMethods = []string{"heights"}
+
+	newConf := &Config{}
+	newConf.Store.Password = "from env"
+	newConf.API.LogLevel = 5
+	newConf.Factom.ProxyMethods = []string{"heights", "entry"}
+
+	fileConf := &Config{}
+	fileConf.Store.Password = "from file"
+	fileConf.API.LogLevel = 4
+	fileConf.API.HTTPPort = 8081
+
+	fileConf.SetChanged(oldConf, newConf)
+
+	// unchanged fields keep values of the file
+	assert.Equal(t, "from file", fileConf.Store.Password)
+	assert.Equal(t, 8081, fileConf.API.HTTPPort)
+	// changed fields are set
+	assert.Equal(t, 5, fileConf.API.LogLevel)
+	assert.Equal(t, []string{"heights", "entry"}, fileConf.Factom.ProxyMethods)
+
+}
@@ -0,0 +1,91 @@
+package content
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// content is stored in Postgres table
+	BackendPostgres = "pg"
+	// content is stored in files on the local filesystem
+	BackendFS = "fs"
+	// number of hashes checked for references at once by Sweep
+	SweepBatchSize = 1000
+)
+
+// Store keeps content addressed by SHA-256 hash of the content, so identical content is stored once.
+// Content is compressed with zstd by all backends
+type Store interface {
+	// Put saves content & returns its hash, content already saved is not written again
+	Put(data []byte) (string, error)
+	// Get returns content by hashes, unknown hashes are missing in the result
+	Get(hashes []string) (map[string][]byte, error)
+	// Sweep deletes content saved before the time, which is not referenced anymore.
+	// Hashes are passed to referenced in batches, it returns hashes still in use. Returns number of deleted contents
+	Sweep(before time.Time, referenced func(hashes []string) (map[string]bool, error)) (int, error)
+}
+
+// shared encoder & decoder are safe for concurrent EncodeAll & DecodeAll calls
+var (
+	encoder, _ = zstd.NewWriter(nil)
+	decoder, _ = zstd.NewReader(nil)
+)
+
+// New creates content store with backend, path is a directory of fs backend, db is used by pg backend
+func New(backend string, path string, db *gorm.DB) (Store, error) {
+
+	switch backend {
+	case BackendPostgres:
+		return NewPostgres(db), nil
+	case BackendFS:
+		return NewFS(path)
+	}
+
+	return nil, fmt.Errorf("Content backend expected to be '%s' or '%s', '%s' received", BackendPostgres, BackendFS, backend)
+
+}
+
+// Hash returns hex-encoded SHA-256 hash of the content
+func Hash(data []byte) string {
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+
+}
+
+func compress(data []byte) []byte {
+
+	return encoder.EncodeAll(data, make([]byte, 0, len(data)))
+
+}
+
+func decompress(data []byte) ([]byte, error) {
+
+	return decoder.DecodeAll(data, nil)
+
+}
+
+// unreferenced returns hashes, which are not in use according to referenced
+func unreferenced(hashes []string, referenced func(hashes []string) (map[string]bool, error)) ([]string, error) {
+
+	used, err := referenced(hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	for _, hash := range hashes {
+		if !used[hash] {
+			res = append(res, hash)
+		}
+	}
+
+	return res, nil
+
+}
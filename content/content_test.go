@@ -0,0 +1,157 @@
+package content
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+
+	var tests = []struct {
+		name    string
+		backend string
+		path    string
+		err     bool
+	}{
+		{"fs", BackendFS, t.TempDir(), false},
+		{"fs without path", BackendFS, "", true},
+		{"unknown backend", "s3", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := New(tt.backend, tt.path, nil)
+			if tt.err {
+				assert.Error(t, err)
+				assert.Nil(t, store)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, store)
+			}
+		})
+	}
+
+}
+
+func TestFSRoundTrip(t *testing.T) {
+
+	store, err := NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name string
+		data []byte
+	}{
+		{"text", []byte("Hello, Factom!")},
+		{"binary", []byte{0x00, 0xff, 0x10, 0x80, 0x00}},
+		{"empty", []byte{}},
+		{"large", make([]byte, 10240)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := store.Put(tt.data)
+			if assert.NoError(t, err) {
+				assert.Equal(t, Hash(tt.data), hash)
+				res, err := store.Get([]string{hash})
+				if assert.NoError(t, err) && assert.Contains(t, res, hash) {
+					assert.Equal(t, string(tt.data), string(res[hash]))
+				}
+			}
+		})
+	}
+
+	// unknown & malformed hashes are missing in the result
+	res, err := store.Get([]string{Hash([]byte("unknown")), "ab"})
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+
+}
+
+func TestFSDedup(t *testing.T) {
+
+	path := t.TempDir()
+	store, err := NewFS(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("the same content")
+
+	hash1, err := store.Put(data)
+	assert.NoError(t, err)
+	hash2, err := store.Put(data)
+	assert.NoError(t, err)
+	assert.Equal(t, hash1, hash2)
+
+	var files int
+	filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files++
+		}
+		return err
+	})
+	assert.Equal(t, 1, files)
+
+	// stored content is compressed
+	compressed, err := ioutil.ReadFile(store.(*fs).file(hash1))
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, data, compressed)
+	}
+
+}
+
+func TestFSSweep(t *testing.T) {
+
+	store, err := NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	used, _ := store.Put([]byte("used"))
+	unused, _ := store.Put([]byte("unused"))
+	recent, _ := store.Put([]byte("recent"))
+
+	// content saved before the sweep time is swept only
+	old := time.Now().Add(-time.Hour)
+	for _, hash := range []string{used, unused} {
+		if err := os.Chtimes(store.(*fs).file(hash), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var checked []string
+	n, err := store.Sweep(time.Now().Add(-time.Minute), func(hashes []string) (map[string]bool, error) {
+		checked = append(checked, hashes...)
+		return map[string]bool{used: true}, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.ElementsMatch(t, []string{used, unused}, checked)
+
+	res, err := store.Get([]string{used, unused, recent})
+	assert.NoError(t, err)
+	assert.Contains(t, res, used)
+	assert.NotContains(t, res, unused)
+	assert.Contains(t, res, recent)
+
+	// content put again is not swept as recent
+	if err := os.Chtimes(store.(*fs).file(used), old, old); err != nil {
+		t.Fatal(err)
+	}
+	store.Put([]byte("used"))
+	n, err = store.Sweep(time.Now().Add(-time.Minute), func(hashes []string) (map[string]bool, error) {
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+}
@@ -0,0 +1,152 @@
+package content
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// files are spread over subdirectories by the first bytes of hash, e.g. ab/cd/abcd….zst
+type fs struct {
+	path string
+}
+
+// NewFS creates content store keeping compressed content in files under path
+func NewFS(path string) (Store, error) {
+
+	if path == "" {
+		return nil, fmt.Errorf("Content path expected to be set for '%s' backend", BackendFS)
+	}
+
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, err
+	}
+
+	return &fs{path: path}, nil
+
+}
+
+func (s *fs) file(hash string) string {
+
+	return filepath.Join(s.path, hash[0:2], hash[2:4], hash+".zst")
+
+}
+
+func (s *fs) Put(data []byte) (string, error) {
+
+	hash := Hash(data)
+	file := s.file(hash)
+
+	// modification time of existing content is refreshed, so content referenced again is not swept
+	if _, err := os.Stat(file); err == nil {
+		now := time.Now()
+		return hash, os.Chtimes(file, now, now)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return "", err
+	}
+
+	// content is written into temp file & renamed, so readers never see partially written file
+	tmp, err := ioutil.TempFile(filepath.Dir(file), hash+".tmp")
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := tmp.Write(compress(data)); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	return hash, nil
+
+}
+
+func (s *fs) Get(hashes []string) (map[string][]byte, error) {
+
+	res := make(map[string][]byte)
+
+	for _, hash := range hashes {
+
+		if len(hash) < 4 {
+			continue
+		}
+
+		compressed, err := ioutil.ReadFile(s.file(hash))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := decompress(compressed)
+		if err != nil {
+			return nil, err
+		}
+		res[hash] = data
+
+	}
+
+	return res, nil
+
+}
+
+func (s *fs) Sweep(before time.Time, referenced func(hashes []string) (map[string]bool, error)) (int, error) {
+
+	var n int
+	var hashes []string
+
+	sweep := func() error {
+		unused, err := unreferenced(hashes, referenced)
+		if err != nil {
+			return err
+		}
+		for _, hash := range unused {
+			if err := os.Remove(s.file(hash)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			n++
+		}
+		hashes = hashes[:0]
+		return nil
+	}
+
+	err := filepath.Walk(s.path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".zst") || !info.ModTime().Before(before) {
+			return nil
+		}
+		hashes = append(hashes, strings.TrimSuffix(info.Name(), ".zst"))
+		if len(hashes) < SweepBatchSize {
+			return nil
+		}
+		return sweep()
+	})
+	if err != nil {
+		return n, err
+	}
+
+	if len(hashes) > 0 {
+		err = sweep()
+	}
+
+	return n, err
+
+}
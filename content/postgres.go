@@ -0,0 +1,107 @@
+package content
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// row of contents table
+type row struct {
+	Hash      string `gorm:"primary_key"`
+	Data      []byte
+	Size      int
+	CreatedAt time.Time
+}
+
+func (row) TableName() string {
+	return "contents"
+}
+
+type postgres struct {
+	db *gorm.DB
+}
+
+// NewPostgres creates content store keeping compressed content in contents table
+func NewPostgres(db *gorm.DB) Store {
+
+	return &postgres{db: db}
+
+}
+
+func (s *postgres) Put(data []byte) (string, error) {
+
+	hash := Hash(data)
+
+	// created_at of existing content is refreshed, so content referenced again is not swept
+	err := s.db.Exec("INSERT INTO contents (hash, data, size, created_at) VALUES (?, ?, ?, ?) ON CONFLICT (hash) DO UPDATE SET created_at = EXCLUDED.created_at",
+		hash, compress(data), len(data), time.Now().UTC()).Error
+	if err != nil {
+		return "", err
+	}
+
+	return hash, nil
+
+}
+
+func (s *postgres) Get(hashes []string) (map[string][]byte, error) {
+
+	res := make(map[string][]byte)
+	if len(hashes) == 0 {
+		return res, nil
+	}
+
+	rows := []*row{}
+	if err := s.db.Where("hash IN (?)", hashes).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, r := range rows {
+		data, err := decompress(r.Data)
+		if err != nil {
+			return nil, err
+		}
+		res[r.Hash] = data
+	}
+
+	return res, nil
+
+}
+
+func (s *postgres) Sweep(before time.Time, referenced func(hashes []string) (map[string]bool, error)) (int, error) {
+
+	var n int
+	var last string
+
+	for {
+
+		var hashes []string
+		if err := s.db.Model(&row{}).Where("hash > ? AND created_at < ?", last, before).Order("hash").Limit(SweepBatchSize).Pluck("hash", &hashes).Error; err != nil {
+			return n, err
+		}
+
+		if len(hashes) == 0 {
+			return n, nil
+		}
+		last = hashes[len(hashes)-1]
+
+		unused, err := unreferenced(hashes, referenced)
+		if err != nil {
+			return n, err
+		}
+
+		if len(unused) > 0 {
+			res := s.db.Where("hash IN (?) AND created_at < ?", unused, before).Delete(&row{})
+			if res.Error != nil {
+				return n, res.Error
+			}
+			n += int(res.RowsAffected)
+		}
+
+		if len(hashes) < SweepBatchSize {
+			return n, nil
+		}
+
+	}
+
+}
@@ -92,6 +92,12 @@ var doc = `{
                         "description": "Sorting order.\u003cbr /\u003eOne of: **asc** or **desc**\u003cbr /\u003e*Default: desc*",
                         "name": "sort",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of ExtIDs & content: base64 (default), utf8 or hex.",
+                        "name": "encoding",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -146,6 +152,12 @@ var doc = `{
                         "description": "The content of the first entry of the chain.\u003cbr /\u003e**Should be provided as base64 string.**",
                         "name": "content",
                         "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of ExtIDs & content: base64 (default), utf8 or hex.",
+                        "name": "encoding",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -220,6 +232,12 @@ var doc = `{
                         "description": "Sorting order.\u003cbr /\u003eOne of: **asc** or **desc**\u003cbr /\u003e*Default: desc*",
                         "name": "sort",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of ExtIDs & content: base64 (default), utf8 or hex.",
+                        "name": "encoding",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -263,6 +281,56 @@ var doc = `{
                     "application/json"
                 ],
                 "summary": "Get chain",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain ID of the Factom chain.",
+                        "name": "chainId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of ExtIDs & content: base64 (default), utf8 or hex.",
+                        "name": "encoding",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes Factom chain from user's chains. Chain without users is not updated anymore & its entries may be purged by retention policy",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete chain",
                 "parameters": [
                     {
                         "type": "string",
@@ -344,6 +412,12 @@ var doc = `{
                         "description": "Sorting order.\u003cbr /\u003eOne of: **asc** or **desc**\u003cbr /\u003e*Default: desc*",
                         "name": "sort",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of ExtIDs & content: base64 (default), utf8 or hex. Entries, which can't be converted, are returned in base64 with encoding field set.",
+                        "name": "encoding",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -394,6 +468,12 @@ var doc = `{
                         "name": "chainId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of ExtIDs & content: base64 (default), utf8 or hex.",
+                        "name": "encoding",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -444,6 +524,80 @@ var doc = `{
                         "name": "chainId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of ExtIDs & content: base64 (default), utf8 or hex.",
+                        "name": "encoding",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/chains/{chainId}/entries/raw": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates entry in Factom chain from raw content in request body, MIME type of content is stored from Content-Type header. ExtIDs are passed in repeated X-Ext-Id headers. Binary ExtIDs may be passed as extIds fields of multipart form with content field or file.",
+                "consumes": [
+                    "application/octet-stream",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create raw entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain ID of the Factom chain, where to add new entry.",
+                        "name": "chainId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ExtID of the entry, header is repeated for every ExtID.",
+                        "name": "X-Ext-Id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Callback URL",
+                        "name": "callback_url",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of ExtIDs & content: base64 (default), utf8 or hex.",
+                        "name": "encoding",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -525,6 +679,12 @@ var doc = `{
                         "description": "Sorting order.\u003cbr /\u003eOne of: **asc** or **desc**\u003cbr /\u003e*Default: desc*",
                         "name": "sort",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of ExtIDs & content: base64 (default), utf8 or hex. Entries, which can't be converted, are returned in base64 with encoding field set.",
+                        "name": "encoding",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -587,6 +747,12 @@ var doc = `{
                         "description": "The content of the new entry of the chain.\u003cbr /\u003e**Should be provided as base64 string.**",
                         "name": "content",
                         "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of ExtIDs & content: base64 (default), utf8 or hex.",
+                        "name": "encoding",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -637,6 +803,12 @@ var doc = `{
                         "name": "entryHash",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of ExtIDs & content: base64 (default), utf8 or hex.",
+                        "name": "encoding",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -664,6 +836,48 @@ var doc = `{
                 }
             }
         },
+        "/entries/{entryHash}/content": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns decoded content of Factom entry with MIME type stored on raw upload or detected from content",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "summary": "Get entry content",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "EntryHash of the Factom entry.",
+                        "name": "entryHash",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/factomd/{method}": {
             "post": {
                 "security": [
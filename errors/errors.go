@@ -10,11 +10,13 @@ type Error struct {
 }
 
 const (
-	BindDataError   = 1410
-	ValidationError = 1420
-	PaginationError = 1430
-	ServiceError    = 1510
-	LimitationError = 1520
+	BindDataError     = 1410
+	ValidationError   = 1420
+	PaginationError   = 1430
+	AccessDeniedError = 1440
+	RateLimitError    = 1450
+	ServiceError      = 1510
+	LimitationError   = 1520
 )
 
 func (err *Error) Error() string {
@@ -1,8 +1,38 @@
 module github.com/DeFacto-Team/Factom-Open-API
 
-go 1.12
+go 1.23.0
 
 require (
+	github.com/FactomProject/factom v0.0.0-20190708192212-b398e7fb0919
+	github.com/alecthomas/template v0.0.0-20160405071501-a0175ee3bccc
+	github.com/dgrijalva/jwt-go v3.2.0+incompatible
+	github.com/go-yaml/yaml v2.1.0+incompatible
+	github.com/jinzhu/configor v1.0.0
+	github.com/jinzhu/copier v0.0.0-20180308034124-7e38e58719c3
+	github.com/jinzhu/gorm v1.9.4
+	github.com/klauspost/compress v1.18.0
+	github.com/labstack/echo/v4 v4.1.6
+	github.com/lib/pq v1.1.0
+	github.com/liip/sheriff v0.0.0-20190308094614-91aa83a45a3d
+	github.com/mcuadros/go-defaults v1.1.0
+	github.com/prometheus/client_golang v1.20.5
+	github.com/rubenv/sql-migrate v0.0.0-20190327083759-54bad0a9b051
+	github.com/sirupsen/logrus v1.4.1
+	github.com/stretchr/testify v1.10.0
+	github.com/swaggo/echo-swagger v0.0.0-20190329130007-1219b460a043
+	github.com/swaggo/swag v1.5.0
+	go.opentelemetry.io/otel v1.34.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.34.0
+	go.opentelemetry.io/otel/sdk v1.34.0
+	go.opentelemetry.io/otel/trace v1.34.0
+	go.opentelemetry.io/proto/otlp v1.5.0
+	golang.org/x/crypto v0.38.0
+	google.golang.org/protobuf v1.36.6
+	gopkg.in/go-playground/validator.v9 v9.28.0
+)
+
+require (
+	github.com/BurntSushi/toml v0.3.1 // indirect
 	github.com/FactomProject/FactomCode v0.3.5 // indirect
 	github.com/FactomProject/basen v0.0.0-20150613233007-fe3947df716e // indirect
 	github.com/FactomProject/bolt v1.1.0 // indirect
@@ -12,7 +42,6 @@ require (
 	github.com/FactomProject/dynrsrc v0.3.1 // indirect
 	github.com/FactomProject/ed25519 v0.0.0-20150814230546-38002c4fe7b6 // indirect
 	github.com/FactomProject/factoid v0.3.4 // indirect
-	github.com/FactomProject/factom v0.0.0-20190708192212-b398e7fb0919
 	github.com/FactomProject/factomd v6.2.2+incompatible // indirect
 	github.com/FactomProject/fastsha256 v0.2.1 // indirect
 	github.com/FactomProject/fsnotify v0.9.0 // indirect
@@ -27,42 +56,63 @@ require (
 	github.com/FactomProject/netki-go-partner-client v0.0.0-20160324224126-426acb535e66 // indirect
 	github.com/FactomProject/serveridentity v0.0.0-20180611231115-cf42d2aa8deb // indirect
 	github.com/FactomProject/web v0.1.0 // indirect
-	github.com/alecthomas/template v0.0.0-20160405071501-a0175ee3bccc
+	github.com/PuerkitoBio/purell v1.1.1 // indirect
+	github.com/PuerkitoBio/urlesc v0.0.0-20170810143723-de5bf2ad4578 // indirect
+	github.com/beorn7/perks v1.0.1 // indirect
 	github.com/bmizerany/assert v0.0.0-20160611221934-b7ed37b82869 // indirect
 	github.com/boltdb/bolt v1.3.1 // indirect
 	github.com/btcsuitereleases/btcutil v0.0.0-20150612230727-f2b1058a8255 // indirect
+	github.com/cenkalti/backoff/v4 v4.3.0 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/cmars/basen v0.0.0-20150613233007-fe3947df716e // indirect
-	github.com/dgrijalva/jwt-go v3.2.0+incompatible
+	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/erikstmartin/go-testdb v0.0.0-20160219214506-8d10e4a1bae5 // indirect
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/go-openapi/jsonpointer v0.19.0 // indirect
 	github.com/go-openapi/jsonreference v0.19.0 // indirect
 	github.com/go-openapi/spec v0.19.0 // indirect
 	github.com/go-openapi/swag v0.19.0 // indirect
 	github.com/go-playground/locales v0.12.1 // indirect
 	github.com/go-playground/universal-translator v0.16.0 // indirect
-	github.com/go-yaml/yaml v2.1.0+incompatible
 	github.com/gobuffalo/packr v1.25.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/grpc-ecosystem/grpc-gateway v1.16.0 // indirect
+	github.com/grpc-ecosystem/grpc-gateway/v2 v2.25.1 // indirect
 	github.com/hashicorp/go-plugin v1.0.0 // indirect
 	github.com/hashicorp/go-version v1.2.0 // indirect
 	github.com/howeyc/fsnotify v0.9.0 // indirect
-	github.com/jinzhu/configor v1.0.0
-	github.com/jinzhu/copier v0.0.0-20180308034124-7e38e58719c3
-	github.com/jinzhu/gorm v1.9.4
+	github.com/jinzhu/inflection v0.0.0-20180308033659-04140366298a // indirect
 	github.com/jinzhu/now v1.0.0 // indirect
-	github.com/labstack/echo/v4 v4.1.6
+	github.com/labstack/gommon v0.2.9 // indirect
 	github.com/leodido/go-urn v1.1.0 // indirect
-	github.com/lib/pq v1.1.0
-	github.com/liip/sheriff v0.0.0-20190308094614-91aa83a45a3d
 	github.com/mailru/easyjson v0.0.0-20190403194419-1ea4449da983 // indirect
-	github.com/mcuadros/go-defaults v1.1.0
-	github.com/rubenv/sql-migrate v0.0.0-20190327083759-54bad0a9b051
-	github.com/sirupsen/logrus v1.4.1
-	github.com/stretchr/testify v1.3.0
-	github.com/swaggo/echo-swagger v0.0.0-20190329130007-1219b460a043
-	github.com/swaggo/swag v1.5.0
+	github.com/mattn/go-colorable v0.1.2 // indirect
+	github.com/mattn/go-isatty v0.0.8 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/pkg/errors v0.8.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/prometheus/client_model v0.6.1 // indirect
+	github.com/prometheus/common v0.55.0 // indirect
+	github.com/prometheus/procfs v0.15.1 // indirect
+	github.com/swaggo/files v0.0.0-20190110041405-30649e0721f8 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasttemplate v1.0.1 // indirect
 	github.com/ziutek/mymysql v1.5.4 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace v1.34.0 // indirect
+	go.opentelemetry.io/otel/metric v1.34.0 // indirect
+	golang.org/x/net v0.40.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	golang.org/x/text v0.25.0 // indirect
+	golang.org/x/tools v0.21.1-0.20240508182429-e35e4ccd0d2d // indirect
+	google.golang.org/genproto v0.0.0-20250603155806-513f23925822 // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20250528174236-200df99c418a // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250528174236-200df99c418a // indirect
+	google.golang.org/grpc v1.72.1 // indirect
 	gopkg.in/gcfg.v1 v1.2.3 // indirect
-	gopkg.in/go-playground/validator.v9 v9.28.0
 	gopkg.in/gorp.v1 v1.7.2 // indirect
 	gopkg.in/warnings.v0 v0.1.2 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
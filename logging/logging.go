@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// log formats
+	FormatJSON = "json"
+	FormatText = "text"
+
+	// common log fields, so one write can be traced from HTTP request to the blockchain
+	FieldRequestID = "request_id"
+	FieldComponent = "component"
+	FieldMethod    = "method"
+	FieldRoute     = "route"
+	FieldURI       = "uri"
+	FieldStatus    = "status"
+	FieldLatency   = "latency_ms"
+	FieldIP        = "ip"
+	FieldQueueID   = "queue_id"
+	FieldAction    = "action"
+	FieldEntryHash = "entry_hash"
+	FieldChainID   = "chain_id"
+	FieldTraceID   = "trace_id"
+	FieldSpanID    = "span_id"
+)
+
+type contextKey int
+
+const requestIDKey contextKey = 0
+
+// SetFormat sets format of logs: FormatJSON or FormatText
+func SetFormat(format string) error {
+
+	switch format {
+	case FormatJSON:
+		log.SetFormatter(&log.JSONFormatter{})
+	case FormatText:
+		log.SetFormatter(&log.TextFormatter{})
+	default:
+		return fmt.Errorf("Log format expected to be '%s' or '%s', '%s' received", FormatJSON, FormatText, format)
+	}
+
+	return nil
+
+}
+
+// NewRequestID generates random request ID
+func NewRequestID() string {
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+
+}
+
+// WithRequestID returns context carrying request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns request ID from context or empty string
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// FromContext returns logger with request ID & current trace span from context
+func FromContext(ctx context.Context) *log.Entry {
+
+	entry := log.NewEntry(log.StandardLogger())
+	if id := RequestID(ctx); id != "" {
+		entry = entry.WithField(FieldRequestID, id)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		entry = entry.WithFields(log.Fields{FieldTraceID: sc.TraceID().String(), FieldSpanID: sc.SpanID().String()})
+	}
+	return entry
+
+}
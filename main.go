@@ -1,17 +1,26 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
 	"os/user"
+	"syscall"
 	"time"
 
 	"github.com/DeFacto-Team/Factom-Open-API/api"
 	"github.com/DeFacto-Team/Factom-Open-API/config"
+	"github.com/DeFacto-Team/Factom-Open-API/metrics"
 	"github.com/DeFacto-Team/Factom-Open-API/model"
 	"github.com/DeFacto-Team/Factom-Open-API/pool"
 	"github.com/DeFacto-Team/Factom-Open-API/service"
 	"github.com/DeFacto-Team/Factom-Open-API/store"
+	"github.com/DeFacto-Team/Factom-Open-API/supervisor"
+	"github.com/DeFacto-Team/Factom-Open-API/tracing"
 	"github.com/DeFacto-Team/Factom-Open-API/wallet"
 
 	"github.com/FactomProject/factom"
@@ -25,6 +34,18 @@ const (
 	MinutesInBlock = 10
 	// number of background workers to fetch data from chains
 	WorkersCount = 4
+	// delay between refills of history fetching pool queue, while there are chains to sync
+	PoolRefillInterval = 1 * time.Second
+	// delay between attempts to connect to database at start
+	DBRetryInterval = 5 * time.Second
+	// delay between attempts to get current minute from factomd by updates parser
+	FactomdRetryInterval = 10 * time.Second
+	// delay between checks of new dblocks in dblock sync mode & by chains indexer
+	DBlockPollInterval = 1 * time.Minute
+	// chains indexer logs progress every this number of dblocks
+	IndexLogInterval = 1000
+	// number of entries, which content is moved into content store at once
+	ContentMigrationBatch = 1000
 )
 
 // @title Factom Open API
@@ -56,100 +77,196 @@ func main() {
 	configFile := usr.HomeDir + "/.foa/config.yaml"
 
 	flag.StringVar(&configFile, "c", configFile, "config.yaml path")
+	config.BindFlags(flag.CommandLine)
+	flag.Usage = usage
 	flag.Parse()
 
-	startAPI(configFile)
+	cmd, args := findCommand(flag.Args())
+	if cmd == nil || len(args) < cmd.minArgs {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(configFile, args); err != nil {
+		log.Fatal(err)
+	}
 
 }
 
 func startAPI(configFile string) {
 
-	for {
+	var err error
+	var conf *config.Config
 
-		var err error
-		var conf *config.Config
+	if conf, err = config.NewConfig(configFile); err != nil {
+		log.Fatal(err)
+	}
 
-		if conf, err = config.NewConfig(configFile); err != nil {
-			log.Fatal(err)
-		}
+	if err = conf.Validate(); err != nil {
+		log.Fatal(err)
+	}
 
-		// Setup logger
-		log.SetLevel(log.Level(conf.API.LogLevel))
-		log.Info("Starting Factom Open API")
+	// Setup logger & factomd node
+	conf.ApplyGlobals()
 
-		// Create store
-		store, err := store.NewStore(conf, true)
-		if err != nil {
-			log.Error("Database connection FAILED")
-			log.Fatal(err)
-		}
-		defer store.Close()
-		log.Info("Store created successfully")
+	// Measure factomd calls, factom library uses default transport
+	http.DefaultTransport = metrics.InstrumentFactomd(http.DefaultTransport)
 
-		// Create factom
-		if conf.Factom.URL != "" {
-			factom.SetFactomdServer(conf.Factom.URL)
-		}
-		if conf.Factom.User != "" && conf.Factom.Password != "" {
-			factom.SetFactomdRpcConfig(conf.Factom.User, conf.Factom.Password)
-		}
+	// Export spans of HTTP requests, DB queries & factomd calls
+	stopTracing, err := tracing.Setup(conf.Tracing.Endpoint, conf.Tracing.SampleRatio)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-		// Check factomd availability
-		heights, err := factom.GetHeights()
-		if err != nil {
-			log.Warn("FAILED connection to factomd node: ", conf.Factom.URL)
-		} else {
-			log.Info("Using factomd node: ", conf.Factom.URL,
-				" (DBlock=", heights.DirectoryBlockHeight, "/", heights.LeaderHeight,
-				", EntryBlock=", heights.EntryHeight, "/", heights.EntryBlockHeight, ")")
-			if heights.EntryBlockHeight-heights.EntryHeight > 1 {
-				log.Warn("Factomd node is not fully synced! API will not be able to write data on the blockchain or read actual data from the blockchain!")
-			}
-		}
+	log.Info("Starting Factom Open API")
 
-		// initialize wallet
-		wallet, err := wallet.NewWallet(conf)
-		if err != nil {
-			log.Warn(err)
-			log.Warn("You need to setup Es address in order to use API")
+	// Create store, waiting for database if it's not available yet
+	store := connectStore(conf)
+	log.Info("Store created successfully")
+
+	// Check factomd availability
+	heights, err := factom.GetHeights()
+	if err != nil {
+		log.Warn("FAILED connection to factomd node: ", conf.Factom.URL)
+	} else {
+		log.Info("Using factomd node: ", conf.Factom.URL,
+			" (DBlock=", heights.DirectoryBlockHeight, "/", heights.LeaderHeight,
+			", EntryBlock=", heights.EntryHeight, "/", heights.EntryBlockHeight, ")")
+		if heights.EntryBlockHeight-heights.EntryHeight > 1 {
+			log.Warn("Factomd node is not fully synced! API will not be able to write data on the blockchain or read actual data from the blockchain!")
 		}
+	}
+
+	// initialize wallet
+	wallet, err := wallet.NewWallet(conf)
+	if err != nil {
+		log.Warn(err)
+		log.Warn("You need to setup Es address in order to use API")
+	}
+
+	// Create services
+	s := service.NewService(store, wallet)
+	log.Info("Services created successfully")
+
+	// Create the first superadmin from config credentials
+	if err := s.BootstrapAdmin(conf.Admin.User, conf.Admin.Password); err != nil {
+		log.Error(err)
+	}
 
-		// Create services
-		s := service.NewService(store, wallet)
-		log.Info("Services created successfully")
+	// Initialize pool for history fetching chains
+	collector := pool.StartDispatcher(WorkersCount)
+	s.SetScheduler(collector)
+
+	// Initialize single-thread background workers
+	sv := supervisor.New(context.Background())
+	sv.Every("pingDB", 5*time.Second, func(ctx context.Context) { pingDB(store) })
+	sv.Go("migrateContent", func(ctx context.Context) { migrateContent(ctx, s) })
+	sv.Go("fetchUnsyncedChains", func(ctx context.Context) { fetchUnsyncedChains(ctx, s, collector) })
+	if conf.Parser.Index {
+		sv.Go("indexChains", func(ctx context.Context) { indexChains(ctx, s, conf.Parser.IndexFromHeight) })
+	}
+	if conf.Parser.SyncMode == config.SyncModeDBlock {
+		sv.Go("fetchDBlockUpdates", func(ctx context.Context) { fetchDBlockUpdates(ctx, s) })
+	} else {
+		sv.Go("fetchChainUpdates", func(ctx context.Context) { fetchChainUpdates(ctx, s) })
+	}
+	sv.Every("processQueue", 5*time.Second, func(ctx context.Context) { processQueue(ctx, s) })
+	sv.Every("clearQueue", 60*time.Second, func(ctx context.Context) { clearQueue(ctx, s) })
+	sv.Every("completedCallbacks", 30*time.Second, func(ctx context.Context) { completedCallbacks(ctx, s) })
+	sv.Every("resetUsage", 60*time.Second, func(ctx context.Context) { resetUsage(s) })
+	sv.Every("cleanupAdminSessions", 60*time.Minute, func(ctx context.Context) { cleanupAdminSessions(s) })
+	if conf.Parser.RetentionDays > 0 {
+		sv.Every("purgeUntrackedChains", 60*time.Minute, func(ctx context.Context) { purgeUntrackedChains(s, conf.Parser.RetentionDays) })
+	}
+	sv.Every("updateMetrics", 30*time.Second, func(ctx context.Context) { updateMetrics(s) })
 
-		// Initialize pool for history fetching chains
-		collector := pool.StartDispatcher(WorkersCount)
+	// Init REST API
+	api := api.NewAPI(conf, s, configFile)
 
-		// Initialize single-thread background workers
-		die := make(chan bool)
-		go pingDB(store, die)
-		go fetchUnsyncedChains(s, collector, die)
-		go fetchChainUpdates(s, die)
-		go processQueue(s, die)
-		go clearQueue(s, die)
-		go completedCallbacks(s, die)
+	// Reload config without restart on SIGHUP
+	sv.Go("reloadConfig", func(ctx context.Context) { reloadConfig(ctx, api) })
 
-		// Init REST API
-		api := api.NewAPI(conf, s, configFile)
+	// Start REST API
+	log.WithField("port", api.GetAPIInfo().Port).
+		WithField("version", api.GetAPIInfo().Version).
+		WithField("middleware", api.GetAPIInfo().MW).
+		Info("Starting REST API")
 
-		// Start REST API
-		log.WithField("port", api.GetAPIInfo().Port).
-			WithField("version", api.GetAPIInfo().Version).
-			WithField("middleware", api.GetAPIInfo().MW).
-			Info("Starting REST API")
-		err = api.Start()
+	apiErr := make(chan error, 1)
+	go func() {
+		apiErr <- api.Start()
+	}()
 
-		if err != nil {
-			log.Warn("Restarting Factom Open API")
-			close(die)
-		}
+	// Wait for termination signal or API failure
+	sigterm := make(chan os.Signal, 1)
+	signal.Notify(sigterm, syscall.SIGTERM, syscall.SIGINT)
+
+	exitCode := 0
+
+	select {
+	case sig := <-sigterm:
+		log.WithField("signal", sig).Info("Shutting down Factom Open API")
+	case err := <-apiErr:
+		log.Error(err)
+		exitCode = 1
+	}
+
+	timeout := time.Duration(conf.API.ShutdownTimeout) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// stop accepting new requests & complete active ones
+	if err := api.Stop(ctx); err != nil {
+		log.Error(err)
+		exitCode = 1
+	}
+
+	// stop feeding pool & queue, complete in-flight commits
+	if err := sv.Stop(ctx); err != nil {
+		log.Error(err)
+		exitCode = 1
+	}
+
+	// stop pool workers after their current chains
+	if err := collector.Stop(ctx); err != nil {
+		log.Error(err)
+		exitCode = 1
+	}
+
+	// export spans of completed requests & jobs
+	if err := stopTracing(ctx); err != nil {
+		log.Error(err)
+		exitCode = 1
+	}
+
+	store.Close()
+
+	log.Info("Factom Open API stopped")
+	os.Exit(exitCode)
 
+}
+
+func reloadConfig(ctx context.Context, a *api.API) {
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	for {
+		select {
+		case <-sighup:
+			log.Info("SIGHUP received, reloading config")
+			if err := a.ReloadConfig(); err != nil {
+				log.Error("Config reload FAILED: ", err)
+			}
+		case <-ctx.Done():
+			return
+		}
 	}
 
 }
 
-func fetchUnsyncedChains(s service.Service, collector pool.Collector, die chan bool) {
+func fetchUnsyncedChains(ctx context.Context, s service.Service, collector pool.Collector) {
 
 	log.Info("Reseting all unsynced local chains to put it into pool")
 	err := s.ResetChainsParsingAtAPIStart()
@@ -158,150 +275,335 @@ func fetchUnsyncedChains(s service.Service, collector pool.Collector, die chan b
 	}
 
 	for {
-		select {
-		default:
-			log.Info("Fetching unsynced chains: iteration started")
-			t := false
-			chains := s.GetChains(&model.Chain{Synced: &t, WorkerID: -1, SentToPool: &t})
-			for _, c := range chains {
-				s.SetChainSentToPool(c)
-				collector.Work <- pool.Work{ID: c.ChainID, Job: c, Service: s}
+		log.Debug("Fetching unsynced chains: iteration started")
+		// pool queue is kept short, so chains prioritized later don't wait for all chains queued before,
+		// chains return into unsynced chains after each batch of entry blocks & are sent again by priority
+		limit := WorkersCount - collector.Queued()
+		var chains []*model.Chain
+		if limit > 0 {
+			chains = s.GetChainsToSync(limit)
+		}
+		for _, c := range chains {
+			s.SetChainSentToPool(c)
+			select {
+			case collector.Work <- pool.Work{ID: c.ChainID, Job: c, Service: s, Priority: c.SyncPriority}:
+			case <-ctx.Done():
+				// chains sent to pool, but not parsed, are reset at the next start
+				return
 			}
-			time.Sleep(5 * time.Second)
-		case <-die:
+		}
+		// the pool is refilled sooner, if there are more chains to sync
+		interval := 5 * time.Second
+		if limit <= 0 || len(chains) == limit {
+			interval = PoolRefillInterval
+		}
+		if !supervisor.Sleep(ctx, interval) {
 			return
 		}
 	}
 
 }
 
-func fetchChainUpdates(s service.Service, die chan bool) {
+func fetchChainUpdates(ctx context.Context, s service.Service) {
 
 	var currentMinute int    // current minute
 	var currentMinuteEnd int // current minute after parsing ended
 	var currentDBlock int    // current dblock
-	var latestDBlock int     // latest fetched dblock
+	var latestDBlock int     // latest fetched dblock, saved to resume after restart
 	var sleepFor int         // sleep timer
 	var err error
 
+	latestDBlock = s.GetUpdatesHeight()
+	log.Info("Updates parser: latestDBlock=", latestDBlock)
+
 	for {
 
-		select {
-		default:
-			log.Info("Updates parser: Iteration started")
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Info("Updates parser: Iteration started")
 
-			// get current minute & dblock from Factom
+		// get current minute & dblock from Factom
+		currentMinute, currentDBlock, err = getMinuteAndHeight()
+		if err != nil {
+			log.Error("Updates parser: ", err)
+			if !supervisor.Sleep(ctx, FactomdRetryInterval) {
+				return
+			}
+			continue
+		}
+		log.Info("Updates parser: currentMinute=", currentMinute, ", currentDBlock=", currentDBlock)
+
+		// if current dblock <= latest fetched dblock, then elections should occur and need to sleep 1 minute before next try
+		// latestDBlock is restored after restart, so already parsed dblock is not parsed again;
+		// chains failed to parse are checked on the next dblock, parser resumes from their latest parsed entry block
+		for currentDBlock <= latestDBlock {
+			log.Info("Updates parser: Sleeping for 1 minute / currentDBlock=", currentDBlock, ", latestDBlock=", latestDBlock)
+			if !supervisor.Sleep(ctx, 1*time.Minute) {
+				return
+			}
 			currentMinute, currentDBlock, err = getMinuteAndHeight()
 			if err != nil {
+				// the latest known dblock is kept, so the check is retried after the next sleep
+				log.Error("Updates parser: ", err)
+				currentDBlock = latestDBlock
 				continue
 			}
 			log.Info("Updates parser: currentMinute=", currentMinute, ", currentDBlock=", currentDBlock)
+		}
 
-			// if current dblock <= latest fetched dblock, then elections should occur and need to sleep 1 minute before next try
-			// on the first iteration latestDblock = 0, so this code won't run & new updates will be fetched when API started
-			for currentDBlock <= latestDBlock {
-				log.Info("Updates parser: Sleeping for 1 minute / currentDBlock=", currentDBlock, ", latestDBlock=", latestDBlock)
-				time.Sleep(1 * time.Minute)
-				currentMinute, currentDBlock, err = getMinuteAndHeight()
-				log.Info("Updates parser: currentMinute=", currentMinute, ", currentDBlock=", currentDBlock)
+		// if we are here, then latestDBlock > currentDBlock (i.e. new dblock appeared)
+		// parsing chains updates
+		chains := s.GetTrackedChains(&model.Chain{Status: model.ChainCompleted})
+		for _, c := range chains {
+			if ctx.Err() != nil {
+				return
+			}
+			err := s.ParseNewChainEntries(c)
+			if err != nil {
+				log.Error(err)
 			}
+		}
 
-			// if we are here, then latestDBlock > currentDBlock (i.e. new dblock appeared)
-			// parsing chains updates
-			chains := s.GetChains(&model.Chain{Status: model.ChainCompleted})
-			for _, c := range chains {
-				err := s.ParseNewChainEntries(c)
-				if err != nil {
-					log.Error(err)
-				}
+		// updating latest parsed dblock
+		latestDBlock = currentDBlock
+		if err := s.SetUpdatesHeight(latestDBlock); err != nil {
+			log.Error(err)
+		}
+
+		// parsing may spend time, so check current minute
+		currentMinuteEnd, _, err = getMinuteAndHeight()
+		if err != nil {
+			log.Error("Updates parser: ", err)
+			currentMinuteEnd = currentMinute
+		}
+		log.Debug("Updates parser: currentMinute=", currentMinuteEnd)
+
+		// if current minute was {8|9} and becomes {0|1|2|3…}, i.e. new block appeared during the parsing
+		// then no sleep in the end
+		if currentMinuteEnd < currentMinute {
+
+			sleepFor = 0
+
+		} else {
+			// else calculate sleep minutes before next block
+
+			// workaround: if minute == 0, then sleep for 1 minute instead of 11
+			if currentMinute == 0 {
+				currentMinute = 10
 			}
+			// + 1 needed for sleeping at least 1 minute
+			sleepFor = MinutesInBlock - currentMinute + 1
 
-			// updating latest parsed dblock
-			latestDBlock = currentDBlock
+		}
 
-			// parsing may spend time, so check current minute
-			currentMinuteEnd, _, err = getMinuteAndHeight()
-			log.Debug("Updates parser: currentMinute=", currentMinuteEnd)
+		log.Info("Updates parser: Sleeping for ", sleepFor, " minute(s)")
+		if !supervisor.Sleep(ctx, time.Duration(sleepFor)*time.Minute) {
+			return
+		}
 
-			// if current minute was {8|9} and becomes {0|1|2|3…}, i.e. new block appeared during the parsing
-			// then no sleep in the end
-			if currentMinuteEnd < currentMinute {
+	}
+}
 
-				sleepFor = 0
+// fetchDBlockUpdates parses every new dblock once, so only changed chains are requested from factomd.
+// The latest parsed dblock is saved, so dblocks appeared while API was stopped are parsed one by one after restart
+func fetchDBlockUpdates(ctx context.Context, s service.Service) {
 
-			} else {
-				// else calculate sleep minutes before next block
+	latestDBlock := s.GetUpdatesHeight()
+	log.Info("Updates parser: DBlock sync mode, latestDBlock=", latestDBlock)
 
-				// workaround: if minute == 0, then sleep for 1 minute instead of 11
-				if currentMinute == 0 {
-					currentMinute = 10
+	for {
+
+		heights, err := factom.GetHeights()
+		if err != nil {
+			log.Error(err)
+		} else {
+
+			// entry blocks of dblock are available, when factomd has downloaded its entries
+			currentDBlock := int(heights.DirectoryBlockHeight)
+			if int(heights.EntryHeight) < currentDBlock {
+				currentDBlock = int(heights.EntryHeight)
+			}
+
+			// nothing to catch up on the first start, histories of chains are parsed by history parser
+			if latestDBlock == 0 {
+				latestDBlock = currentDBlock
+				if err := s.SetUpdatesHeight(latestDBlock); err != nil {
+					log.Error(err)
 				}
-				// + 1 needed for sleeping at least 1 minute
-				sleepFor = MinutesInBlock - currentMinute + 1
+			}
 
+			// dblock is parsed again on the next iteration if it failed
+			for latestDBlock < currentDBlock {
+				if ctx.Err() != nil {
+					return
+				}
+				if err := s.ParseDBlock(latestDBlock + 1); err != nil {
+					log.Error(err)
+					break
+				}
+				latestDBlock++
+				if err := s.SetUpdatesHeight(latestDBlock); err != nil {
+					log.Error(err)
+				}
 			}
 
-			log.Info("Updates parser: Sleeping for ", sleepFor, " minute(s)")
-			time.Sleep(time.Duration(sleepFor) * time.Minute)
-		case <-die:
+		}
+
+		if !supervisor.Sleep(ctx, DBlockPollInterval) {
 			return
 		}
 
 	}
+
 }
 
-// Get all tasks from queue where processed_at == NULL
-func processQueue(s service.Service, die chan bool) {
+// indexChains registers every chain on the network by reading dblocks from fromHeight, their history is parsed by pool workers.
+// The latest indexed dblock is saved, so indexing is resumed after restart
+func indexChains(ctx context.Context, s service.Service, fromHeight int) {
+
+	nextDBlock := s.GetIndexHeight() + 1
+	if nextDBlock < fromHeight {
+		nextDBlock = fromHeight
+	}
+	log.Info("Chains indexer: Starting from DBlock ", nextDBlock)
+
 	for {
-		select {
-		default:
-			log.Info("Processing queue: iteration started")
-			queue := s.GetQueueToProcess()
-			for _, q := range queue {
-				err := s.ProcessQueue(q)
-				if err != nil {
+
+		heights, err := factom.GetHeights()
+		if err != nil {
+			log.Error(err)
+		} else {
+
+			currentDBlock := int(heights.DirectoryBlockHeight)
+			for nextDBlock <= currentDBlock {
+				if ctx.Err() != nil {
+					return
+				}
+				if _, err := s.IndexDBlock(nextDBlock); err != nil {
+					log.Error(err)
+					break
+				}
+				if err := s.SetIndexHeight(nextDBlock); err != nil {
 					log.Error(err)
 				}
+				if nextDBlock%IndexLogInterval == 0 || nextDBlock == currentDBlock {
+					log.Info("Chains indexer: Indexed DBlock ", nextDBlock, "/", currentDBlock)
+				}
+				nextDBlock++
 			}
-			time.Sleep(5 * time.Second)
-		case <-die:
+
+		}
+
+		if !supervisor.Sleep(ctx, DBlockPollInterval) {
 			return
 		}
+
 	}
+
 }
 
-func clearQueue(s service.Service, die chan bool) {
-	for {
-		select {
-		default:
-			log.Info("Clearing queue: iteration started")
-			queue := s.GetQueueToClear()
-			for _, q := range queue {
-				s.ClearQueue(q)
+// migrateContent moves content of entries saved by previous versions into content store by batches
+func migrateContent(ctx context.Context, s service.Service) {
+
+	var total int
+
+	for ctx.Err() == nil {
+
+		n, err := s.MigrateContent(ContentMigrationBatch)
+		if err != nil {
+			log.Error(err)
+			if !supervisor.Sleep(ctx, DBRetryInterval) {
+				return
 			}
-			time.Sleep(60 * time.Second)
-		case <-die:
+			continue
+		}
+
+		total += n
+		if n < ContentMigrationBatch {
+			break
+		}
+		log.Info("Content migration: ", total, " entries converted")
+
+	}
+
+	if total > 0 {
+		log.Info("Content migration: Completed, ", total, " entries converted")
+	}
+
+}
+
+// Get all tasks from queue where processed_at == NULL.
+// On shutdown the current commit is completed, remaining tasks are left for the next start
+func processQueue(ctx context.Context, s service.Service) {
+	log.Info("Processing queue: iteration started")
+	queue := s.GetQueueToProcess()
+	for _, q := range queue {
+		if ctx.Err() != nil {
 			return
 		}
+		err := s.ProcessQueue(q)
+		if err != nil {
+			log.Error(err)
+		}
 	}
 }
 
-func completedCallbacks(s service.Service, die chan bool) {
-	for {
-		select {
-		default:
-			log.Info("Completed callbacks: iteration started")
-			callbacks := s.GetCallbacks(&model.Callback{})
-			for _, c := range callbacks {
-				log.Debug("Completed callbacks: Entry ", c.EntryHash, " ", c.Entry.Status)
-				if c.Entry.Status == model.EntryCompleted {
-					s.SendCallback(c)
-				}
-			}
-			time.Sleep(30 * time.Second)
-		case <-die:
+func clearQueue(ctx context.Context, s service.Service) {
+	log.Info("Clearing queue: iteration started")
+	queue := s.GetQueueToClear()
+	for _, q := range queue {
+		if ctx.Err() != nil {
+			return
+		}
+		s.ClearQueue(q)
+	}
+}
+
+func completedCallbacks(ctx context.Context, s service.Service) {
+	log.Info("Completed callbacks: iteration started")
+	callbacks := s.GetCallbacks(&model.Callback{})
+	for _, c := range callbacks {
+		if ctx.Err() != nil {
 			return
 		}
+		log.Debug("Completed callbacks: Entry ", c.EntryHash, " ", c.Entry.Status)
+		if c.Entry.Status == model.EntryCompleted {
+			s.SendCallback(c)
+		}
+	}
+}
+
+func resetUsage(s service.Service) {
+	log.Debug("Resetting usage: iteration started")
+	if err := s.ResetExpiredUsage(); err != nil {
+		log.Error(err)
+	}
+}
+
+func cleanupAdminSessions(s service.Service) {
+	log.Debug("Cleaning up admin sessions: iteration started")
+	if err := s.CleanupAdminSessions(); err != nil {
+		log.Error(err)
+	}
+}
+
+func purgeUntrackedChains(s service.Service, days int) {
+	log.Debug("Purging untracked chains: iteration started")
+	n, err := s.PurgeUntrackedChains(time.Now().UTC().AddDate(0, 0, -days))
+	if err != nil {
+		log.Error(err)
+	}
+	if n > 0 {
+		log.Info("Retention policy: ", n, " untracked chain(s) purged")
+	}
+}
+
+func updateMetrics(s service.Service) {
+	log.Debug("Updating metrics: iteration started")
+	if err := s.UpdateMetrics(); err != nil {
+		log.Error(err)
 	}
 }
 
@@ -315,34 +617,46 @@ func getMinuteAndHeight() (int, int, error) {
 
 	resp, err := factom.SendFactomdRequest(request)
 	if err != nil {
-		log.Error(err)
-		return 0, 0, nil
+		return 0, 0, err
+	}
+
+	if resp.Error != nil {
+		return 0, 0, fmt.Errorf("factomd current-minute: %s", resp.Error.Message)
 	}
 
 	if err = json.Unmarshal(resp.JSONResult(), &i); err != nil {
-		log.Error(err)
-		return 0, 0, nil
+		return 0, 0, err
 	}
 
 	m, _ := i.(map[string]interface{})
-	currentMinute = m["minute"].(float64)
-	dBlockHeight = m["directoryblockheight"].(float64)
+	currentMinute, okMinute := m["minute"].(float64)
+	dBlockHeight, okHeight := m["directoryblockheight"].(float64)
+	if !okMinute || !okHeight {
+		return 0, 0, fmt.Errorf("factomd current-minute: unexpected response %s", resp.JSONResult())
+	}
 
 	return int(currentMinute), int(dBlockHeight), nil
 
 }
 
-func pingDB(s store.Store, die chan bool) {
+// connectStore creates store, retrying until database is available
+func connectStore(conf *config.Config) store.Store {
+
 	for {
-		select {
-		default:
-			if err := s.Ping(); err != nil {
-				log.Error("Database connection FAILED")
-				log.Fatal(err)
-			}
-			time.Sleep(5 * time.Second)
-		case <-die:
-			return
+		s, err := store.NewStore(conf, true)
+		if err == nil {
+			return s
 		}
+		log.Error("Database connection FAILED: ", err)
+		log.Info("Retrying database connection in ", DBRetryInterval)
+		time.Sleep(DBRetryInterval)
+	}
+
+}
+
+// pingDB checks database connection. Connection is restored by the driver, so failures are only reported
+func pingDB(s store.Store) {
+	if err := s.Ping(); err != nil {
+		log.Error("Database connection FAILED: ", err)
 	}
 }
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metrics of Factom Open API
+var (
+	// HTTP API
+	HTTPRequests        = NewCounter("http_requests_total", "Number of HTTP requests by route & status.", "method", "route", "status")
+	HTTPRequestDuration = NewHistogram("http_request_duration_seconds", "Latency of HTTP requests by route & status.", nil, "method", "route", "status")
+
+	// queue of writes on the blockchain
+	QueueItems              = NewGauge("queue_items", "Number of queue items by state (pending, retrying, processed).", "state")
+	QueueProcessingDuration = NewHistogram("queue_processing_duration_seconds", "Duration of commit & reveal of queue items by action.", nil, "action")
+	QueueLatency            = NewHistogram("queue_latency_seconds", "Time from queueing to successful commit & reveal by action.", []float64{1, 5, 10, 30, 60, 120, 300, 600, 1800, 3600}, "action")
+	QueueFailures           = NewCounter("queue_failures_total", "Number of failed attempts to process queue items by action & reason.", "action", "reason")
+
+	// chains parser
+	ParserChains       = NewGauge("parser_chains", "Number of local chains by sync state (synced, unsynced).", "state")
+	ParserEntryBlocks  = NewCounter("parser_entry_blocks_total", "Number of parsed entry blocks by mode (history, updates).", "mode")
+	ParserEntries      = NewCounter("parser_entries_total", "Number of parsed entries by mode (history, updates).", "mode")
+	ParserChainsParsed = NewCounter("parser_chains_parsed_total", "Number of chains history parsing jobs by result (synced, failed).", "result")
+
+	// factomd node
+	FactomdRequestDuration = NewHistogram("factomd_request_duration_seconds", "Latency of factomd API calls by method.", nil, "method")
+	FactomdErrors          = NewCounter("factomd_errors_total", "Number of failed factomd API calls by method & reason (network, http, rpc).", "method", "reason")
+
+	// EC address used for writes
+	ECBalance = NewGauge("ec_balance", "Balance of EC address used for writes on the blockchain.", "address")
+
+	// callbacks
+	Callbacks = NewCounter("callbacks_total", "Number of callback deliveries by outcome (delivered, rejected, failed).", "outcome")
+
+	// pool of chains history parsing workers
+	PoolWorkers     = promauto.With(Registry).NewGauge(prometheus.GaugeOpts{Namespace: Namespace, Name: "pool_workers", Help: "Number of chains parsing workers."})
+	PoolWorkersBusy = promauto.With(Registry).NewGauge(prometheus.GaugeOpts{Namespace: Namespace, Name: "pool_workers_busy", Help: "Number of chains parsing workers processing a chain."})
+)
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+// factomdTransport measures factomd JSON-RPC calls made via wrapped transport
+type factomdTransport struct {
+	next http.RoundTripper
+}
+
+// InstrumentFactomd wraps transport, so calls to the configured factomd node are measured.
+// Factom library uses http.DefaultTransport, so it's enough to wrap it at start
+func InstrumentFactomd(next http.RoundTripper) http.RoundTripper {
+	return &factomdTransport{next: next}
+}
+
+func (t *factomdTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	if !isFactomdRequest(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	method := "unknown"
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		var rpc struct {
+			Method string `json:"method"`
+		}
+		if json.Unmarshal(body, &rpc) == nil && rpc.Method != "" {
+			method = rpc.Method
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	FactomdRequestDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		FactomdErrors.WithLabelValues(method, "network").Inc()
+		return resp, err
+	}
+
+	if resp.StatusCode >= 300 {
+		FactomdErrors.WithLabelValues(method, "http").Inc()
+		return resp, nil
+	}
+
+	// JSON-RPC errors are returned with HTTP 200
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		FactomdErrors.WithLabelValues(method, "network").Inc()
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var rpc struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &rpc) == nil && len(rpc.Error) > 0 && string(rpc.Error) != "null" {
+		FactomdErrors.WithLabelValues(method, "rpc").Inc()
+	}
+
+	return resp, nil
+
+}
+
+// isFactomdRequest checks if request is sent to the factomd node from config
+func isFactomdRequest(req *http.Request) bool {
+
+	server := factom.RpcConfig.FactomdServer
+	if !strings.Contains(server, "://") {
+		server = "http://" + server
+	}
+
+	u, err := url.Parse(server)
+	if err != nil {
+		return false
+	}
+
+	return req.URL.Host == u.Host
+
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace is prefix of all metric names
+const Namespace = "foa"
+
+// DefBuckets are histogram buckets in seconds suitable for request latencies
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30}
+
+// Registry keeps all metrics of Factom Open API, so they are exposed by Handler
+var Registry = prometheus.NewRegistry()
+
+// NewCounter creates and registers counter. Name is prefixed with Namespace
+func NewCounter(name string, help string, labels ...string) *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{Namespace: Namespace, Name: name, Help: help}, labels)
+	Registry.MustRegister(c)
+	return c
+}
+
+// NewGauge creates and registers gauge. Name is prefixed with Namespace
+func NewGauge(name string, help string, labels ...string) *prometheus.GaugeVec {
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: Namespace, Name: name, Help: help}, labels)
+	Registry.MustRegister(g)
+	return g
+}
+
+// NewHistogram creates and registers histogram with the given buckets (DefBuckets if nil)
+func NewHistogram(name string, help string, buckets []float64, labels ...string) *prometheus.HistogramVec {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	h := prometheus.NewHistogramVec(prometheus.HistogramOpts{Namespace: Namespace, Name: name, Help: help, Buckets: buckets}, labels)
+	Registry.MustRegister(h)
+	return h
+}
+
+// Handler exposes all registered metrics in Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
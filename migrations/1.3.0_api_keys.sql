@@ -0,0 +1,34 @@
+-- +migrate Up
+CREATE TABLE api_keys(
+    id		 SERIAL,
+    user_id INT4 NOT NULL,
+    name VARCHAR(128) NOT NULL,
+    prefix VARCHAR(8) NOT NULL,
+    secret_hash VARCHAR(64) UNIQUE NOT NULL,
+    scopes _TEXT,
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT api_keys_id_key PRIMARY KEY(id),
+    CONSTRAINT api_keys_user_id_fkey FOREIGN KEY(user_id) REFERENCES users(id)
+);
+
+CREATE INDEX api_keys_user_id_idx ON api_keys(user_id);
+
+-- existing plaintext tokens become hashed 'default' keys of their users
+INSERT INTO api_keys(user_id, name, prefix, secret_hash, created_at, updated_at)
+SELECT id, 'default', LEFT(access_token, 6), encode(sha256(access_token::bytea), 'hex'), NOW(), NOW()
+FROM users WHERE deleted_at IS NULL;
+
+ALTER TABLE users DROP COLUMN access_token;
+
+-- +migrate Down
+-- plaintext tokens can not be restored from hashes, so users receive new random tokens
+ALTER TABLE users ADD COLUMN access_token VARCHAR(128);
+UPDATE users SET access_token = md5(random()::text || id::text);
+ALTER TABLE users ALTER COLUMN access_token SET NOT NULL;
+ALTER TABLE users ADD CONSTRAINT users_access_token_key UNIQUE (access_token);
+
+DROP TABLE api_keys;
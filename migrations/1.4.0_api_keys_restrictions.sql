@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE api_keys ADD COLUMN chain_ids _TEXT;
+ALTER TABLE api_keys ADD COLUMN ext_id_namespaces _TEXT;
+
+-- +migrate Down
+ALTER TABLE api_keys DROP COLUMN chain_ids;
+ALTER TABLE api_keys DROP COLUMN ext_id_namespaces;
@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE users ADD COLUMN rate_limit_read INT4 NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN rate_limit_write INT4 NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN rate_limit_proxy INT4 NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE users DROP COLUMN rate_limit_read;
+ALTER TABLE users DROP COLUMN rate_limit_write;
+ALTER TABLE users DROP COLUMN rate_limit_proxy;
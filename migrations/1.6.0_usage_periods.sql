@@ -0,0 +1,25 @@
+-- +migrate Up
+ALTER TABLE users ADD COLUMN usage_period VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN usage_period_days INT4 NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN usage_period_start TIMESTAMPTZ;
+
+CREATE TABLE usage_history(
+    id		 SERIAL,
+    user_id INT4 NOT NULL,
+    period_start TIMESTAMPTZ,
+    period_end TIMESTAMPTZ NOT NULL,
+    usage INT4 NOT NULL,
+    usage_limit INT4 NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT usage_history_id_key PRIMARY KEY(id),
+    CONSTRAINT usage_history_user_id_fkey FOREIGN KEY(user_id) REFERENCES users(id)
+);
+CREATE INDEX usage_history_user_id_idx ON usage_history(user_id);
+
+-- +migrate Down
+DROP TABLE usage_history;
+ALTER TABLE users DROP COLUMN usage_period;
+ALTER TABLE users DROP COLUMN usage_period_days;
+ALTER TABLE users DROP COLUMN usage_period_start;
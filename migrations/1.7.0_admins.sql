@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE admins(
+    id		 SERIAL,
+    username VARCHAR NOT NULL,
+    password_hash VARCHAR NOT NULL,
+    role VARCHAR NOT NULL,
+    status INT4 NOT NULL DEFAULT 1,
+    last_login_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT admins_id_key PRIMARY KEY(id)
+);
+CREATE UNIQUE INDEX admins_username_key ON admins(username) WHERE deleted_at IS NULL;
+
+-- +migrate Down
+DROP TABLE admins;
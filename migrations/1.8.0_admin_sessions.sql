@@ -0,0 +1,31 @@
+-- +migrate Up
+CREATE TABLE jwt_keys(
+    id		 SERIAL,
+    secret VARCHAR NOT NULL,
+    retired_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT jwt_keys_id_key PRIMARY KEY(id)
+);
+
+CREATE TABLE admin_sessions(
+    id		 SERIAL,
+    jti VARCHAR(64) NOT NULL,
+    admin_id INT4 NOT NULL,
+    ip VARCHAR,
+    user_agent VARCHAR,
+    expires_at TIMESTAMPTZ NOT NULL,
+    last_seen_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT admin_sessions_id_key PRIMARY KEY(id),
+    CONSTRAINT admin_sessions_jti_key UNIQUE(jti),
+    CONSTRAINT admin_sessions_admin_id_fkey FOREIGN KEY(admin_id) REFERENCES admins(id)
+);
+
+-- +migrate Down
+DROP TABLE admin_sessions;
+DROP TABLE jwt_keys;
@@ -0,0 +1,23 @@
+-- +migrate Up
+ALTER TABLE admins ADD COLUMN totp_secret VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE admins ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE admins ADD COLUMN totp_last_step INT8 NOT NULL DEFAULT 0;
+
+CREATE TABLE admin_recovery_codes(
+    id		 SERIAL,
+    admin_id INT4 NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT admin_recovery_codes_id_key PRIMARY KEY(id),
+    CONSTRAINT admin_recovery_codes_admin_id_fkey FOREIGN KEY(admin_id) REFERENCES admins(id)
+);
+CREATE INDEX admin_recovery_codes_admin_id_idx ON admin_recovery_codes(admin_id);
+
+-- +migrate Down
+DROP TABLE admin_recovery_codes;
+ALTER TABLE admins DROP COLUMN totp_secret;
+ALTER TABLE admins DROP COLUMN totp_enabled;
+ALTER TABLE admins DROP COLUMN totp_last_step;
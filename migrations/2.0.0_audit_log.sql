@@ -0,0 +1,36 @@
+-- +migrate Up
+CREATE TABLE audit_log(
+    id		 BIGSERIAL,
+    actor_type VARCHAR NOT NULL,
+    actor_id INT4,
+    actor_name VARCHAR,
+    action VARCHAR NOT NULL,
+    target_type VARCHAR,
+    target_id VARCHAR,
+    ip VARCHAR,
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT audit_log_id_key PRIMARY KEY(id)
+);
+CREATE INDEX audit_log_created_at_idx ON audit_log(created_at);
+CREATE INDEX audit_log_actor_idx ON audit_log(actor_type, actor_id);
+CREATE INDEX audit_log_action_idx ON audit_log(action);
+CREATE INDEX audit_log_target_idx ON audit_log(target_type, target_id);
+
+-- audit log is append-only
+-- +migrate StatementBegin
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE PROCEDURE audit_log_append_only();
+
+-- +migrate Down
+DROP TRIGGER audit_log_append_only ON audit_log;
+DROP FUNCTION audit_log_append_only();
+DROP TABLE audit_log;
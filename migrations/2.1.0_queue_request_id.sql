@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE queue ADD COLUMN request_id VARCHAR;
+
+-- +migrate Down
+ALTER TABLE queue DROP COLUMN request_id;
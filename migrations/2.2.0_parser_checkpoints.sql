@@ -0,0 +1,15 @@
+-- +migrate Up
+-- entry block is parsed when all its entries are saved, blocks parsed before are considered complete
+ALTER TABLE e_blocks ADD COLUMN parsed BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE e_blocks SET parsed=TRUE;
+
+CREATE TABLE sync_state(
+    name VARCHAR(32) NOT NULL,
+    height INT8 NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ,
+    CONSTRAINT sync_state_name_key PRIMARY KEY(name)
+);
+
+-- +migrate Down
+DROP TABLE sync_state;
+ALTER TABLE e_blocks DROP COLUMN parsed;
@@ -0,0 +1,7 @@
+-- +migrate Up
+-- chains with higher priority are synced first, negative priority means sync is cancelled by admin
+ALTER TABLE chains ADD COLUMN sync_priority INT4 NOT NULL DEFAULT 0;
+UPDATE chains SET sync_priority=10 WHERE synced IS FALSE AND EXISTS (SELECT 1 FROM users_chains WHERE users_chains.chain_chain_id = chains.chain_id);
+
+-- +migrate Down
+ALTER TABLE chains DROP COLUMN sync_priority;
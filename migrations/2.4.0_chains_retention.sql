@@ -0,0 +1,13 @@
+-- +migrate Up
+-- chain is tracked (synced & updated), if it's pinned or bound to users
+ALTER TABLE chains ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT FALSE;
+-- time since chain is not tracked, its entries are purged by retention policy
+ALTER TABLE chains ADD COLUMN untracked_at TIMESTAMPTZ;
+-- chains without users were tracked before, e.g. registered by chains indexer
+UPDATE chains SET pinned=TRUE WHERE NOT EXISTS (SELECT 1 FROM users_chains WHERE users_chains.chain_chain_id = chains.chain_id);
+CREATE INDEX chains_untracked_at_idx ON chains(untracked_at);
+
+-- +migrate Down
+DROP INDEX chains_untracked_at_idx;
+ALTER TABLE chains DROP COLUMN untracked_at;
+ALTER TABLE chains DROP COLUMN pinned;
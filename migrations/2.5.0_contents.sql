@@ -0,0 +1,30 @@
+-- +migrate Up
+-- zstd-compressed content addressed by SHA-256 hash of decompressed content, used by pg content backend
+CREATE TABLE contents(
+    hash VARCHAR(64) NOT NULL,
+    data BYTEA NOT NULL,
+    size INT4 NOT NULL,
+    created_at TIMESTAMPTZ,
+    CONSTRAINT contents_hash_key PRIMARY KEY(hash)
+);
+
+-- content of entry is moved into content store, rows with content & without hash are converted by API in batches
+ALTER TABLE entries ADD COLUMN content_hash VARCHAR(64);
+CREATE INDEX entries_content_not_migrated_idx ON entries(entry_hash) WHERE content_hash IS NULL AND content <> '';
+
+-- +migrate Down
+-- content of converted entries can't be restored by SQL (it's zstd-compressed & may be kept in files),
+-- so rollback is refused instead of losing it, while any entry refers to content store
+-- +migrate StatementBegin
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM entries WHERE content_hash <> '') THEN
+        RAISE EXCEPTION 'entries content is kept in content store, rollback would lose it';
+    END IF;
+END;
+$$;
+-- +migrate StatementEnd
+
+DROP INDEX entries_content_not_migrated_idx;
+ALTER TABLE entries DROP COLUMN content_hash;
+DROP TABLE contents;
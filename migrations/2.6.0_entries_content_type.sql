@@ -0,0 +1,6 @@
+-- +migrate Up
+-- MIME type of content uploaded via raw entries endpoint, content type of other entries is sniffed
+ALTER TABLE entries ADD COLUMN content_type VARCHAR(255);
+
+-- +migrate Down
+ALTER TABLE entries DROP COLUMN content_type;
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// admin roles, every role includes permissions of the previous ones
+	AdminRoleViewer     = "viewer"     // read-only access to users, queue & dashboard
+	AdminRoleOperator   = "operator"   // manages users, API keys & queue
+	AdminRoleSuperadmin = "superadmin" // manages settings, admins & restarts API
+)
+
+var adminRoleLevels = map[string]int{
+	AdminRoleViewer:     1,
+	AdminRoleOperator:   2,
+	AdminRoleSuperadmin: 3,
+}
+
+type Admin struct {
+	// gorm.Model without ID
+	CreatedAt time.Time  `json:"createdAt" form:"-" query:"-"`
+	UpdatedAt time.Time  `json:"-" form:"-" query:"-"`
+	DeletedAt *time.Time `json:"-" form:"-" query:"-"`
+	// model
+	ID           int        `json:"id" form:"id" query:"id" gorm:"primary_key;unique;not null"`
+	Username     string     `json:"username" form:"username" query:"username" validate:"required,max=64" gorm:"not null"`
+	PasswordHash string     `json:"-" form:"-" query:"-" gorm:"not null"`
+	Password     string     `json:"password,omitempty" form:"password" query:"-" validate:"omitempty,min=8" sql:"-"` // plaintext password, only accepted as input
+	Role         string     `json:"role" form:"role" query:"role" validate:"required,oneof=viewer operator superadmin" gorm:"not null"`
+	Status       int        `json:"status" form:"status" query:"status" gorm:"not null;default:1"`
+	LastLoginAt  *time.Time `json:"lastLoginAt" form:"-" query:"-"`
+	// two-factor authentication, secret is set on enrolment & TOTP is enabled after the first valid code
+	TOTPSecret   string `json:"-" form:"-" query:"-" gorm:"column:totp_secret;not null"`
+	TOTPEnabled  bool   `json:"totpEnabled" form:"-" query:"-" gorm:"column:totp_enabled;not null"`
+	TOTPLastStep int64  `json:"-" form:"-" query:"-" gorm:"column:totp_last_step;not null"` // step of the last used code, older codes are rejected
+}
+
+// AdminRecoveryCode is one-time code to login without TOTP device
+type AdminRecoveryCode struct {
+	// gorm.Model without ID
+	CreatedAt time.Time  `json:"-" form:"-" query:"-"`
+	UpdatedAt time.Time  `json:"-" form:"-" query:"-"`
+	DeletedAt *time.Time `json:"-" form:"-" query:"-"`
+	// model
+	ID       int        `json:"-" form:"-" query:"-" gorm:"primary_key;unique;not null"`
+	AdminID  int        `json:"-" form:"-" query:"-" gorm:"not null"`
+	CodeHash string     `json:"-" form:"-" query:"-" gorm:"not null"`
+	UsedAt   *time.Time `json:"-" form:"-" query:"-"`
+}
+
+func (AdminRecoveryCode) TableName() string {
+	return "admin_recovery_codes"
+}
+
+const (
+	// number of recovery codes generated on TOTP enabling
+	AdminRecoveryCodesCount = 10
+	// length of recovery code
+	AdminRecoveryCodeLength = 16
+)
+
+// GenerateRecoveryCodes returns plaintext recovery codes & their records to be stored
+func GenerateRecoveryCodes(admin *Admin) ([]string, []*AdminRecoveryCode) {
+
+	codes := make([]string, AdminRecoveryCodesCount)
+	records := make([]*AdminRecoveryCode, AdminRecoveryCodesCount)
+
+	for i := range codes {
+		codes[i] = strings.ToLower(generateSecret(AdminRecoveryCodeLength))
+		records[i] = &AdminRecoveryCode{AdminID: admin.ID, CodeHash: HashRecoveryCode(codes[i])}
+	}
+
+	return codes, records
+
+}
+
+// HashRecoveryCode returns hex-encoded SHA-256 hash of the normalized code.
+// Codes are long random strings, so a fast hash is enough here
+func HashRecoveryCode(code string) string {
+
+	code = strings.ToLower(strings.Replace(strings.TrimSpace(code), "-", "", -1))
+	return HashAPIKeySecret(code)
+
+}
+
+func (Admin) TableName() string {
+	return "admins"
+}
+
+// SetPassword stores bcrypt hash of the plaintext password
+func (admin *Admin) SetPassword(password string) error {
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	admin.PasswordHash = string(hash)
+	admin.Password = ""
+
+	return nil
+
+}
+
+// CheckPassword compares plaintext password with the stored hash
+func (admin *Admin) CheckPassword(password string) bool {
+
+	return bcrypt.CompareHashAndPassword([]byte(admin.PasswordHash), []byte(password)) == nil
+
+}
+
+// HasRole checks if admin's role includes permissions of the role
+func (admin *Admin) HasRole(role string) bool {
+
+	level, ok := adminRoleLevels[admin.Role]
+	return ok && level >= adminRoleLevels[role]
+
+}
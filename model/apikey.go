@@ -0,0 +1,154 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// name of the key created together with the user or by token rotation
+	DefaultAPIKeyName = "default"
+	// length of generated secrets
+	APIKeySecretLength = 32
+	// number of secret chars stored in plaintext to identify the key
+	APIKeyPrefixLength = 6
+	// do not write last usage time to DB more often than once per this interval
+	APIKeyTouchInterval = time.Minute
+
+	// API key scopes
+	ScopeChainsRead   = "chains:read"
+	ScopeChainsWrite  = "chains:write"
+	ScopeEntriesRead  = "entries:read"
+	ScopeEntriesWrite = "entries:write"
+	ScopeFactomdProxy = "factomd:proxy"
+	ScopeFactomdWrite = "factomd:write" // never granted by default
+)
+
+// DefaultScopes are granted to API keys created without explicit scopes
+var DefaultScopes = []string{ScopeChainsRead, ScopeChainsWrite, ScopeEntriesRead, ScopeEntriesWrite, ScopeFactomdProxy}
+
+type APIKey struct {
+	// gorm.Model without ID
+	CreatedAt time.Time  `json:"createdAt" form:"-" query:"-"`
+	UpdatedAt time.Time  `json:"-" form:"-" query:"-"`
+	DeletedAt *time.Time `json:"-" form:"-" query:"-"`
+	// model
+	ID         int            `json:"id" form:"id" query:"id" gorm:"primary_key;unique;not null"`
+	UserID     int            `json:"userId" form:"-" query:"-" gorm:"not null"`
+	Name       string         `json:"name" form:"name" query:"name" validate:"required,max=128" gorm:"not null"`
+	Prefix     string         `json:"prefix" form:"-" query:"-" gorm:"not null"`
+	SecretHash string         `json:"-" form:"-" query:"-" gorm:"unique;not null"`
+	Secret     string         `json:"secret,omitempty" form:"-" query:"-" sql:"-"` // plaintext secret, returned only once after creation
+	Scopes     pq.StringArray `json:"scopes" form:"scopes" query:"scopes" validate:"omitempty,dive,oneof=chains:read chains:write entries:read entries:write factomd:proxy factomd:write"`
+	ExpiresAt  *time.Time     `json:"expiresAt" form:"-" query:"-"`
+	LastUsedAt *time.Time     `json:"lastUsedAt" form:"-" query:"-"`
+	// if set, key has access only to these chains or chains, which first ExtID is one of ExtIDNamespaces (base64)
+	ChainIDs        pq.StringArray `json:"chainIds" form:"chainIds" query:"chainIds" validate:"omitempty,dive,hexadecimal,len=64"`
+	ExtIDNamespaces pq.StringArray `json:"extIdNamespaces" form:"extIdNamespaces" query:"extIdNamespaces" validate:"omitempty,dive,base64"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// SetSecret sets plaintext secret of the key and fills its hash & prefix
+func (key *APIKey) SetSecret(secret string) {
+
+	key.Secret = secret
+	key.SecretHash = HashAPIKeySecret(secret)
+	key.Prefix = secret
+	if len(secret) > APIKeyPrefixLength {
+		key.Prefix = secret[:APIKeyPrefixLength]
+	}
+
+}
+
+// IsExpired checks if key expiration time passed
+func (key *APIKey) IsExpired() bool {
+
+	return key.ExpiresAt != nil && key.ExpiresAt.Before(time.Now())
+
+}
+
+// HasScope checks if key is granted the scope. Keys without explicit scopes have DefaultScopes
+func (key *APIKey) HasScope(scope string) bool {
+
+	scopes := []string(key.Scopes)
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
+
+	return contains(scopes, scope)
+
+}
+
+// IsRestricted checks if key has access only to the limited set of chains
+func (key *APIKey) IsRestricted() bool {
+
+	return len(key.ChainIDs) > 0 || len(key.ExtIDNamespaces) > 0
+
+}
+
+// AllowsChain checks if key has access to the chain.
+// chain.ExtIDs expected to be base64 encoded, as they are stored into local DB
+func (key *APIKey) AllowsChain(chain *Chain) bool {
+
+	if !key.IsRestricted() {
+		return true
+	}
+
+	if contains(key.ChainIDs, chain.ChainID) {
+		return true
+	}
+
+	return len(chain.ExtIDs) > 0 && contains(key.ExtIDNamespaces, chain.ExtIDs[0])
+
+}
+
+// NeedsTouch checks if last usage time of the key is outdated
+func (key *APIKey) NeedsTouch() bool {
+
+	return key.LastUsedAt == nil || time.Since(*key.LastUsedAt) > APIKeyTouchInterval
+
+}
+
+// HashAPIKeySecret returns hex-encoded SHA-256 hash of the secret.
+// Secrets are long random strings, so a fast hash is enough here.
+func HashAPIKeySecret(secret string) string {
+
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+
+}
+
+// generateSecret returns cryptographically random string of n letterRunes
+func generateSecret(n int) string {
+
+	b := make([]rune, n)
+	max := big.NewInt(int64(len(letterRunes)))
+	for i := range b {
+		r, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		b[i] = letterRunes[r.Int64()]
+	}
+	return string(b)
+
+}
+
+func contains(list []string, item string) bool {
+
+	for _, v := range list {
+		if v == item {
+			return true
+		}
+	}
+	return false
+
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm/dialects/postgres"
+)
+
+const (
+	// actors of audited actions
+	AuditActorAdmin  = "admin"
+	AuditActorUser   = "user"
+	AuditActorSystem = "system"
+	// name of the system actor for actions made via command line
+	AuditActorCLI = "cli"
+
+	// audited actions
+	AuditActionLogin           = "login"
+	AuditActionLoginFailed     = "login.failed"
+	AuditActionLogout          = "logout"
+	AuditActionUserCreate      = "user.create"
+	AuditActionUserUpdate      = "user.update"
+	AuditActionUserDelete      = "user.delete"
+	AuditActionUserRotateToken = "user.rotate_token"
+	AuditActionAPIKeyCreate    = "apikey.create"
+	AuditActionAPIKeyRevoke    = "apikey.revoke"
+	AuditActionAdminCreate     = "admin.create"
+	AuditActionAdminUpdate     = "admin.update"
+	AuditActionAdminDelete     = "admin.delete"
+	AuditActionAdminResetTOTP  = "admin.reset_totp"
+	AuditActionTOTPEnable      = "totp.enable"
+	AuditActionTOTPDisable     = "totp.disable"
+	AuditActionTOTPRecovery    = "totp.regenerate_recovery"
+	AuditActionSessionRevoke   = "session.revoke"
+	AuditActionJWTKeyRotate    = "jwt_key.rotate"
+	AuditActionSettingsUpdate  = "settings.update"
+	AuditActionSettingsReload  = "settings.reload"
+	AuditActionQueueDelete     = "queue.delete"
+	AuditActionQueueRetry      = "queue.retry"
+	AuditActionQueuePurge      = "queue.purge"
+	AuditActionChainResync     = "chain.resync"
+	AuditActionChainPrioritize = "chain.prioritize"
+	AuditActionChainCancelSync = "chain.cancel_sync"
+	AuditActionChainCreate     = "chain.create"
+	AuditActionChainDelete     = "chain.delete"
+	AuditActionChainPin        = "chain.pin"
+	AuditActionChainUnpin      = "chain.unpin"
+	AuditActionEntryCreate     = "entry.create"
+	AuditActionFactomdWrite    = "factomd.write"
+	AuditActionFactomdRead     = "factomd.read"
+
+	// outcomes of proxied factomd calls
+	AuditOutcomeOK       = "ok"
+	AuditOutcomeDenied   = "denied"
+	AuditOutcomeInvalid  = "invalid"
+	AuditOutcomeFailed   = "failed"
+	AuditOutcomeRPCError = "rpc_error"
+)
+
+// AuditLog is append-only record of admin or write action, or proxied factomd call
+type AuditLog struct {
+	ID         int64           `json:"id" form:"-" query:"-" gorm:"primary_key;unique;not null"`
+	CreatedAt  time.Time       `json:"createdAt" form:"-" query:"-"`
+	ActorType  string          `json:"actorType" form:"actorType" query:"actorType" gorm:"not null"`
+	ActorID    int             `json:"actorId" form:"actorId" query:"actorId"`
+	ActorName  string          `json:"actorName" form:"actorName" query:"actorName"`
+	Action     string          `json:"action" form:"action" query:"action" gorm:"not null"`
+	TargetType string          `json:"targetType" form:"targetType" query:"targetType"`
+	TargetID   string          `json:"targetId" form:"targetId" query:"targetId"`
+	IP         string          `json:"ip" form:"ip" query:"ip"`
+	Before     *postgres.Jsonb `json:"before" form:"-" query:"-"`
+	After      *postgres.Jsonb `json:"after" form:"-" query:"-"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
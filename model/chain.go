@@ -25,6 +25,9 @@ type Chain struct {
 	Entries            []Entry        `json:"-" form:"-" query:"-" gorm:"foreignkey:chain_id"`
 	WorkerID           int            `json:"-" form:"-" query:"-" gorm:"not null;default:-1"`
 	SentToPool         *bool          `json:"-" form:"-" query:"-" gorm:"not null;default:false"`
+	SyncPriority       int            `json:"-" form:"-" query:"-" gorm:"not null;default:0"`
+	Pinned             *bool          `json:"-" form:"-" query:"-" gorm:"not null;default:false"`
+	UntrackedAt        *time.Time     `json:"-" form:"-" query:"-"`
 	FactomTime         *time.Time     `json:"createdAt"`
 }
 
@@ -41,6 +44,14 @@ const (
 	ChainCompleted  = "completed"
 	ChainProcessing = "processing"
 	ChainQueue      = "queue"
+
+	// history sync priorities, chains with higher priority are synced first
+	ChainPriorityCancelled = -1
+	ChainPriorityDefault   = 0
+	// chain was requested by user
+	ChainPriorityAccessed = 10
+	// chain entries were requested with force=true
+	ChainPriorityForced = 20
 )
 
 func (chain *Chain) ConvertToEntryModel() *Entry {
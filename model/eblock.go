@@ -11,6 +11,7 @@ type EBlock struct {
 	PrevKeyMR           string   `json:"prevKeyMr"`
 	Timestamp           int64    `json:"timestamp"`
 	DBHeight            int64    `json:"dbHeight"`
+	Parsed              bool     `json:"parsed"` // all entries of the block are saved
 	Entries             []*Entry `json:"-" form:"-" query:"-" gorm:"many2many:entries_e_blocks;"`
 }
 
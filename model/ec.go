@@ -1,8 +1,9 @@
 package model
 
 import (
+	"crypto/rand"
+
 	"github.com/FactomProject/factom"
-	"math/rand"
 )
 
 type EC struct {
@@ -26,7 +27,9 @@ func GetEC(EsAddress string) *EC {
 func GenerateEC() *EC {
 
 	key := make([]byte, 32)
-	rand.Read(key)
+	if _, err := rand.Read(key); err != nil {
+		return nil
+	}
 
 	newAddress, err := factom.MakeECAddress(key)
 	if err != nil {
@@ -0,0 +1,206 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/jinzhu/copier"
+)
+
+// Encodings of ExtIDs & content in API requests and responses, data is stored in base64
+const (
+	EncodingBase64 = "base64"
+	EncodingUTF8   = "utf8"
+	EncodingHex    = "hex"
+)
+
+// ValidateEncoding returns error if encoding is not supported
+func ValidateEncoding(encoding string) error {
+
+	switch encoding {
+	case EncodingBase64, EncodingUTF8, EncodingHex:
+		return nil
+	}
+
+	return fmt.Errorf("Encoding expected to be %s, %s or %s, '%s' received", EncodingBase64, EncodingUTF8, EncodingHex, encoding)
+
+}
+
+// decodeValue converts value in encoding to base64
+func decodeValue(value string, encoding string) (string, bool) {
+
+	switch encoding {
+	case EncodingUTF8:
+		if !utf8.ValidString(value) {
+			return "", false
+		}
+		return base64.StdEncoding.EncodeToString([]byte(value)), true
+	case EncodingHex:
+		data, err := hex.DecodeString(value)
+		if err != nil {
+			return "", false
+		}
+		return base64.StdEncoding.EncodeToString(data), true
+	}
+
+	return value, true
+
+}
+
+// encodeValue converts base64 value to encoding
+func encodeValue(value string, encoding string) (string, bool) {
+
+	if encoding == EncodingBase64 {
+		return value, true
+	}
+
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", false
+	}
+
+	switch encoding {
+	case EncodingUTF8:
+		if !utf8.Valid(data) {
+			return "", false
+		}
+		return string(data), true
+	case EncodingHex:
+		return hex.EncodeToString(data), true
+	}
+
+	return value, true
+
+}
+
+// decodeExtIDs converts ExtIDs in encoding to base64
+func decodeExtIDs(extIDs []string, encoding string) ([]string, error) {
+
+	var decoded []string
+
+	for i, extID := range extIDs {
+		value, ok := decodeValue(extID, encoding)
+		if !ok {
+			return nil, fmt.Errorf("extIds[%d] expected to be %s encoded", i, encoding)
+		}
+		decoded = append(decoded, value)
+	}
+
+	return decoded, nil
+
+}
+
+// encodeExtIDs converts base64 ExtIDs to encoding
+func encodeExtIDs(extIDs []string, encoding string) ([]string, bool) {
+
+	var encoded []string
+
+	for _, extID := range extIDs {
+		value, ok := encodeValue(extID, encoding)
+		if !ok {
+			return nil, false
+		}
+		encoded = append(encoded, value)
+	}
+
+	return encoded, true
+
+}
+
+// FromEncoding returns copy of entry with ExtIDs & content converted from encoding to base64
+func (entry *Entry) FromEncoding(encoding string) (*Entry, error) {
+
+	if encoding == EncodingBase64 {
+		return entry, nil
+	}
+
+	decoded := &Entry{}
+	copier.Copy(decoded, entry)
+
+	extIDs, err := decodeExtIDs(entry.ExtIDs, encoding)
+	if err != nil {
+		return nil, err
+	}
+	decoded.ExtIDs = extIDs
+
+	content, ok := decodeValue(entry.Content, encoding)
+	if !ok {
+		return nil, fmt.Errorf("content expected to be %s encoded", encoding)
+	}
+	decoded.Content = content
+
+	return decoded, nil
+
+}
+
+// ToEncoding returns copy of entry with ExtIDs & content converted from base64 to encoding.
+// Binary data can't be converted to utf8, so such entries are rejected instead of being corrupted
+func (entry *Entry) ToEncoding(encoding string) (*Entry, error) {
+
+	if encoding == EncodingBase64 {
+		return entry, nil
+	}
+
+	encoded := &Entry{}
+	copier.Copy(encoded, entry)
+
+	extIDs, ok := encodeExtIDs(entry.ExtIDs, encoding)
+	if ok {
+		encoded.ExtIDs = extIDs
+		encoded.Content, ok = encodeValue(entry.Content, encoding)
+	}
+	if !ok {
+		return nil, fmt.Errorf("Entry %s can't be %s encoded, use %s or %s encoding", entry.EntryHash, encoding, EncodingBase64, EncodingHex)
+	}
+
+	return encoded, nil
+
+}
+
+// FromEncoding returns copy of chain with ExtIDs & content converted from encoding to base64
+func (chain *Chain) FromEncoding(encoding string) (*Chain, error) {
+
+	if encoding == EncodingBase64 {
+		return chain, nil
+	}
+
+	decoded := &Chain{}
+	copier.Copy(decoded, chain)
+
+	extIDs, err := decodeExtIDs(chain.ExtIDs, encoding)
+	if err != nil {
+		return nil, err
+	}
+	decoded.ExtIDs = extIDs
+
+	content, ok := decodeValue(chain.Content, encoding)
+	if !ok {
+		return nil, fmt.Errorf("content expected to be %s encoded", encoding)
+	}
+	decoded.Content = content
+
+	return decoded, nil
+
+}
+
+// ToEncoding returns copy of chain with ExtIDs converted from base64 to encoding
+func (chain *Chain) ToEncoding(encoding string) (*Chain, error) {
+
+	if encoding == EncodingBase64 {
+		return chain, nil
+	}
+
+	encoded := &Chain{}
+	copier.Copy(encoded, chain)
+
+	extIDs, ok := encodeExtIDs(chain.ExtIDs, encoding)
+	if !ok {
+		return nil, fmt.Errorf("Chain %s can't be %s encoded, use %s or %s encoding", chain.ChainID, encoding, EncodingBase64, EncodingHex)
+	}
+	encoded.ExtIDs = extIDs
+
+	return encoded, nil
+
+}
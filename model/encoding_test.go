@@ -0,0 +1,117 @@
+package model
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func TestValidateEncoding(t *testing.T) {
+
+	for _, encoding := range []string{EncodingBase64, EncodingUTF8, EncodingHex} {
+		assert.NoError(t, ValidateEncoding(encoding))
+	}
+	for _, encoding := range []string{"", "utf16", "BASE64"} {
+		assert.Error(t, ValidateEncoding(encoding))
+	}
+
+}
+
+func TestEntryFromEncoding(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		encoding string
+		extIDs   []string
+		content  string
+		expected *Entry
+	}{
+		{"base64 is kept", EncodingBase64, []string{b64("a")}, b64("b"), &Entry{ExtIDs: []string{b64("a")}, Content: b64("b")}},
+		{"utf8", EncodingUTF8, []string{"héllo", ""}, "wörld", &Entry{ExtIDs: []string{b64("héllo"), ""}, Content: b64("wörld")}},
+		{"hex", EncodingHex, []string{"00ff"}, "6869", &Entry{ExtIDs: []string{b64("\x00\xff")}, Content: b64("hi")}},
+		{"hex is case insensitive", EncodingHex, []string{"00FF"}, "", &Entry{ExtIDs: []string{b64("\x00\xff")}, Content: ""}},
+		{"invalid hex extID", EncodingHex, []string{"00", "zz"}, "", nil},
+		{"odd hex content", EncodingHex, nil, "abc", nil},
+		{"invalid utf8 content", EncodingUTF8, nil, "\xff", nil},
+	}
+
+	for _, tt := range tests {
+		entry := &Entry{ChainID: "chain", ExtIDs: tt.extIDs, Content: tt.content}
+		decoded, err := entry.FromEncoding(tt.encoding)
+		if tt.expected == nil {
+			assert.Error(t, err, tt.name)
+			continue
+		}
+		if assert.NoError(t, err, tt.name) {
+			assert.Equal(t, []string(tt.expected.ExtIDs), []string(decoded.ExtIDs), tt.name)
+			assert.Equal(t, tt.expected.Content, decoded.Content, tt.name)
+			assert.Equal(t, "chain", decoded.ChainID, tt.name)
+		}
+	}
+
+}
+
+func TestEntryToEncoding(t *testing.T) {
+
+	entry := &Entry{EntryHash: "hash", ExtIDs: []string{b64("héllo")}, Content: b64("\x00\xff")}
+
+	// source entry is not changed
+	encoded, err := entry.ToEncoding(EncodingHex)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"68c3a96c6c6f"}, []string(encoded.ExtIDs))
+		assert.Equal(t, "00ff", encoded.Content)
+		assert.Equal(t, b64("\x00\xff"), entry.Content)
+	}
+
+	// binary content can't be utf8 encoded
+	_, err = entry.ToEncoding(EncodingUTF8)
+	assert.Error(t, err)
+
+	entry.Content = b64("wörld")
+	encoded, err = entry.ToEncoding(EncodingUTF8)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"héllo"}, []string(encoded.ExtIDs))
+		assert.Equal(t, "wörld", encoded.Content)
+	}
+
+	// round trip
+	for _, encoding := range []string{EncodingBase64, EncodingUTF8, EncodingHex} {
+		encoded, err := entry.ToEncoding(encoding)
+		if assert.NoError(t, err, encoding) {
+			decoded, err := encoded.FromEncoding(encoding)
+			if assert.NoError(t, err, encoding) {
+				assert.Equal(t, entry.ExtIDs, decoded.ExtIDs, encoding)
+				assert.Equal(t, entry.Content, decoded.Content, encoding)
+			}
+		}
+	}
+
+}
+
+func TestChainEncoding(t *testing.T) {
+
+	chain := &Chain{ChainID: "chain", ExtIDs: []string{b64("\x00\xff")}}
+
+	encoded, err := chain.ToEncoding(EncodingHex)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"00ff"}, []string(encoded.ExtIDs))
+	}
+
+	_, err = chain.ToEncoding(EncodingUTF8)
+	assert.Error(t, err)
+
+	decoded, err := (&Chain{ExtIDs: []string{"tag"}, Content: "text"}).FromEncoding(EncodingUTF8)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{b64("tag")}, []string(decoded.ExtIDs))
+		assert.Equal(t, b64("text"), decoded.Content)
+	}
+
+	_, err = (&Chain{ExtIDs: []string{"xyz"}}).FromEncoding(EncodingHex)
+	assert.Error(t, err)
+
+}
@@ -36,9 +36,12 @@ type Entry struct {
 	ChainID     string         `json:"chainId" form:"chainId" query:"chainId" validate:"required,hexadecimal,len=64"`
 	ExtIDs      pq.StringArray `json:"extIds" form:"extIds" query:"extIds" validate:"omitempty,dive,base64"`
 	Content     string         `json:"content" form:"content" query:"content" validate:"omitempty,base64"`
+	ContentHash string         `json:"-" form:"-" query:"-"`
+	ContentType string         `json:"contentType,omitempty" form:"-" query:"-" validate:"omitempty,max=255"`
 	Status      string         `json:"status" form:"status" query:"status" validate:"omitempty,oneof=queue processing completed" gorm:"not null;default:'queue'"`
 	EntryBlocks []*EBlock      `json:"-" form:"-" query:"-" gorm:"many2many:entries_e_blocks;"`
 	FactomTime  *time.Time     `json:"createdAt"`
+	Encoding    string         `json:"encoding,omitempty" form:"-" query:"-" gorm:"-"` // set in lists, if entry can't be converted to the requested encoding
 }
 
 func NewEntryFromFactomModel(fe *factom.Entry) *Entry {
@@ -0,0 +1,57 @@
+package model
+
+const (
+	// status of the single check: ok, degraded (API works partially) or failed (API can't serve requests)
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+	HealthFailed   = "failed"
+
+	// readiness checks
+	HealthCheckDB      = "db"
+	HealthCheckFactomd = "factomd"
+	HealthCheckWallet  = "wallet"
+	HealthCheckQueue   = "queue"
+	HealthCheckParser  = "parser"
+)
+
+// HealthCheck is the result of checking single dependency
+type HealthCheck struct {
+	Status  string                 `json:"status"`
+	Message string                 `json:"message,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Readiness is the result of checking all dependencies. Status is the worst status of checks
+type Readiness struct {
+	Status string                  `json:"status"`
+	Checks map[string]*HealthCheck `json:"checks"`
+}
+
+// NewHealthCheck creates check with status & details
+func NewHealthCheck(status string, message string, details map[string]interface{}) *HealthCheck {
+	return &HealthCheck{Status: status, Message: message, Details: details}
+}
+
+// IsReady checks if API can serve requests, i.e. there are no failed checks
+func (r *Readiness) IsReady() bool {
+	return r.Status != HealthFailed
+}
+
+// AddCheck adds check result & updates overall status
+func (r *Readiness) AddCheck(name string, check *HealthCheck) {
+
+	if r.Checks == nil {
+		r.Checks = make(map[string]*HealthCheck)
+	}
+	r.Checks[name] = check
+
+	switch {
+	case check.Status == HealthFailed:
+		r.Status = HealthFailed
+	case check.Status == HealthDegraded && r.Status != HealthFailed:
+		r.Status = HealthDegraded
+	case r.Status == "":
+		r.Status = HealthOK
+	}
+
+}
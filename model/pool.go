@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+// PoolState reports chains being synced by history fetching pool workers & chains waiting in the pool queue
+type PoolState struct {
+	Workers []*PoolWorker `json:"workers"`
+	// queued chains, ordered by priority
+	Queue []*PoolItem `json:"queue"`
+}
+
+// PoolWorker is a state of pool worker, ChainID is empty if worker is idle
+type PoolWorker struct {
+	ID        int        `json:"id"`
+	ChainID   string     `json:"chainId,omitempty"`
+	Priority  int        `json:"priority"`
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+}
+
+// PoolItem is a chain waiting in the pool queue, also used to set sync priority of the chain
+type PoolItem struct {
+	ChainID  string     `json:"chainId" form:"-" query:"-"`
+	Priority int        `json:"priority" form:"priority" query:"priority"`
+	QueuedAt *time.Time `json:"queuedAt,omitempty" form:"-" query:"-"`
+}
@@ -27,6 +27,7 @@ type Queue struct {
 	ProcessedAt *time.Time `json:"processedAt" form:"processedAt" query:"processedAt"` // time when sent to Factom without error, otherwise null
 	NextTryAt   *time.Time `json:"nextTryAt" form:"nextTryAt" query:"nextTryAt"`       // by default null, set when processing failed to postpone next attempt
 	TryCount    int        `json:"tryCount" form:"tryCount" query:"tryCount"`
+	RequestID   string     `json:"requestId" form:"requestId" query:"requestId"` // ID of HTTP request, which created the item
 }
 
 type QueueParams struct {
@@ -39,19 +40,16 @@ func (Queue) TableName() string {
 	return "queue"
 }
 
+// AfterCreate charges usage of the user atomically, so concurrent requests & usage reset don't overwrite each other
 func (queue *Queue) AfterCreate(db *gorm.DB) {
 
-	user := &User{}
-	db.First(&user, &User{ID: queue.UserID})
-
-	user.Usage++
-
+	n := 1
 	if queue.Action == QueueActionChain {
-		user.Usage++
+		n++
 	}
 
-	if db.Model(&user).Updates(user).RowsAffected == 0 {
-		log.Error("Update usage for user ", user.Name, " failed")
+	if db.Model(&User{}).Where("id = ?", queue.UserID).UpdateColumn("usage", gorm.Expr("usage + ?", n)).RowsAffected == 0 {
+		log.Error("Update usage for user ", queue.UserID, " failed")
 	}
 
 }
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	// admin session & its JWT are valid for this time after login
+	AdminSessionLifetime = 24 * time.Hour
+	// do not write last activity time to DB more often than once per this interval
+	AdminSessionTouchInterval = time.Minute
+	// signing key is replaced with the new one automatically after this time
+	JWTKeyRotationInterval = 30 * 24 * time.Hour
+	// length of generated signing keys & session IDs
+	JWTKeyLength          = 128
+	AdminSessionJTILength = 32
+)
+
+// JWTKey is the key signing admin JWTs. Only the latest not retired key signs new tokens,
+// retired keys still verify tokens issued before rotation until these tokens expire
+type JWTKey struct {
+	// gorm.Model without ID
+	CreatedAt time.Time  `json:"createdAt" form:"-" query:"-"`
+	UpdatedAt time.Time  `json:"-" form:"-" query:"-"`
+	DeletedAt *time.Time `json:"-" form:"-" query:"-"`
+	// model
+	ID        int        `json:"id" form:"-" query:"-" gorm:"primary_key;unique;not null"`
+	Secret    string     `json:"-" form:"-" query:"-" gorm:"not null"`
+	RetiredAt *time.Time `json:"retiredAt" form:"-" query:"-"`
+}
+
+func (JWTKey) TableName() string {
+	return "jwt_keys"
+}
+
+// NewJWTKey generates the new random signing key
+func NewJWTKey() *JWTKey {
+	return &JWTKey{Secret: generateSecret(JWTKeyLength)}
+}
+
+// NeedsRotation checks if the key is too old to sign new tokens
+func (key *JWTKey) NeedsRotation(now time.Time) bool {
+	return now.Sub(key.CreatedAt) > JWTKeyRotationInterval
+}
+
+// AdminSession is the login of admin, identified by `jti` claim of JWT
+type AdminSession struct {
+	// gorm.Model without ID
+	CreatedAt time.Time  `json:"createdAt" form:"-" query:"-"`
+	UpdatedAt time.Time  `json:"-" form:"-" query:"-"`
+	DeletedAt *time.Time `json:"-" form:"-" query:"-"`
+	// model
+	ID         int        `json:"id" form:"id" query:"id" gorm:"primary_key;unique;not null"`
+	JTI        string     `json:"-" form:"-" query:"-" gorm:"column:jti;unique;not null"`
+	AdminID    int        `json:"adminId" form:"-" query:"-" gorm:"not null"`
+	Admin      *Admin     `json:"admin,omitempty" form:"-" query:"-"`
+	IP         string     `json:"ip" form:"-" query:"-"`
+	UserAgent  string     `json:"userAgent" form:"-" query:"-"`
+	ExpiresAt  time.Time  `json:"expiresAt" form:"-" query:"-" gorm:"not null"`
+	LastSeenAt *time.Time `json:"lastSeenAt" form:"-" query:"-"`
+	RevokedAt  *time.Time `json:"-" form:"-" query:"-"`
+	Current    bool       `json:"current" form:"-" query:"-" sql:"-"` // session of the admin making the request
+}
+
+func (AdminSession) TableName() string {
+	return "admin_sessions"
+}
+
+// NewAdminSession creates the new session of admin with random JTI
+func NewAdminSession(admin *Admin, now time.Time) *AdminSession {
+	return &AdminSession{
+		JTI:       generateSecret(AdminSessionJTILength),
+		AdminID:   admin.ID,
+		ExpiresAt: now.Add(AdminSessionLifetime),
+	}
+}
+
+// IsActive checks if session is not revoked and not expired
+func (session *AdminSession) IsActive(now time.Time) bool {
+	return session.RevokedAt == nil && now.Before(session.ExpiresAt)
+}
+
+// NeedsTouch checks if last activity time of the session is outdated
+func (session *AdminSession) NeedsTouch(now time.Time) bool {
+	return session.LastSeenAt == nil || now.Sub(*session.LastSeenAt) > AdminSessionTouchInterval
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	// sync state of the updates parser, height is the latest parsed dblock
+	SyncStateUpdates = "updates"
+	// sync state of the chains indexer, height is the latest dblock, which chains are registered from
+	SyncStateIndex = "index"
+)
+
+// SyncState is a checkpoint of the blockchain parser, so parsing is resumed after restart
+type SyncState struct {
+	Name      string    `json:"name" gorm:"primary_key;unique;not null"`
+	Height    int64     `json:"height"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (SyncState) TableName() string {
+	return "sync_state"
+}
+
+// SyncProgress reports state of the blockchain parsers
+type SyncProgress struct {
+	SyncMode      string `json:"syncMode"`
+	FactomdHeight int64  `json:"factomdHeight"`
+	UpdatesHeight int64  `json:"updatesHeight"`
+	Index         bool   `json:"index"`
+	// -1 if indexing is not started
+	IndexHeight int64 `json:"indexHeight"`
+	// local chains by history parsing state: synced & unsynced
+	Chains map[string]int `json:"chains"`
+}
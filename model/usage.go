@@ -0,0 +1,122 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// usage periods: usage counter is reset at the end of every period.
+	// Empty period means lifetime usage, that is never reset automatically
+	UsagePeriodLifetime = ""
+	UsagePeriodDaily    = "daily"
+	UsagePeriodMonthly  = "monthly"
+	UsagePeriodCustom   = "custom" // period of User.UsagePeriodDays days
+)
+
+// UsageHistory keeps usage of the user for the finished period
+type UsageHistory struct {
+	// gorm.Model without ID
+	CreatedAt time.Time  `json:"createdAt" form:"-" query:"-"`
+	UpdatedAt time.Time  `json:"-" form:"-" query:"-"`
+	DeletedAt *time.Time `json:"-" form:"-" query:"-"`
+	// model
+	ID          int        `json:"id" form:"id" query:"id" gorm:"primary_key;unique;not null"`
+	UserID      int        `json:"userId" form:"-" query:"-" gorm:"not null"`
+	PeriodStart *time.Time `json:"periodStart" form:"-" query:"-"`
+	PeriodEnd   time.Time  `json:"periodEnd" form:"-" query:"-" gorm:"not null"`
+	Usage       int        `json:"usage" form:"-" query:"-" gorm:"not null"`
+	UsageLimit  int        `json:"usageLimit" form:"-" query:"-" gorm:"not null"`
+}
+
+func (UsageHistory) TableName() string {
+	return "usage_history"
+}
+
+// ValidateUsagePeriod checks if custom usage period has the length
+func (user *User) ValidateUsagePeriod() error {
+
+	if user.UsagePeriod == UsagePeriodCustom && user.UsagePeriodDays <= 0 {
+		return fmt.Errorf("Custom usage period requires usagePeriodDays > 0")
+	}
+
+	return nil
+
+}
+
+// UsagePeriodEnd returns the end of the current usage period or nil for lifetime usage
+func (user *User) UsagePeriodEnd() *time.Time {
+
+	if user.UsagePeriod == UsagePeriodLifetime || user.UsagePeriodStart == nil {
+		return nil
+	}
+
+	start := user.UsagePeriodStart.UTC()
+	var end time.Time
+
+	switch user.UsagePeriod {
+	case UsagePeriodDaily:
+		end = time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, time.UTC)
+	case UsagePeriodMonthly:
+		end = time.Date(start.Year(), start.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	case UsagePeriodCustom:
+		if user.UsagePeriodDays <= 0 {
+			return nil
+		}
+		end = start.AddDate(0, 0, user.UsagePeriodDays)
+	default:
+		return nil
+	}
+
+	return &end
+
+}
+
+// UsagePeriodExpired checks if the current usage period is over at the moment
+func (user *User) UsagePeriodExpired(now time.Time) bool {
+
+	end := user.UsagePeriodEnd()
+	return end != nil && !now.Before(*end)
+
+}
+
+// NextUsagePeriodStart returns the start of the usage period containing the moment.
+// Custom periods are counted from the start of the current period, so they don't drift
+func (user *User) NextUsagePeriodStart(now time.Time) time.Time {
+
+	now = now.UTC()
+
+	switch user.UsagePeriod {
+	case UsagePeriodDaily:
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	case UsagePeriodMonthly:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case UsagePeriodCustom:
+		if user.UsagePeriodStart != nil && user.UsagePeriodDays > 0 {
+			start := user.UsagePeriodStart.UTC()
+			for next := start.AddDate(0, 0, user.UsagePeriodDays); !next.After(now); next = next.AddDate(0, 0, user.UsagePeriodDays) {
+				start = next
+			}
+			return start
+		}
+	}
+
+	return now
+
+}
+
+// SetUsageRemaining fills remaining allowance and the end of the current usage period
+func (user *User) SetUsageRemaining() {
+
+	user.UsagePeriodEndsAt = user.UsagePeriodEnd()
+
+	user.UsageRemaining = nil
+	if user.UsageLimit != 0 {
+		remaining := user.UsageLimit - user.Usage
+		if remaining < 0 {
+			remaining = 0
+		}
+		user.UsageRemaining = &remaining
+	}
+
+}
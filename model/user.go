@@ -2,7 +2,6 @@ package model
 
 import (
 	"github.com/liip/sheriff"
-	"math/rand"
 	"time"
 )
 
@@ -12,15 +11,33 @@ type User struct {
 	UpdatedAt time.Time  `json:"-" form:"-" query:"-"`
 	DeletedAt *time.Time `json:"-" form:"-" query:"-"`
 	// model
-	ID          int      `json:"id" form:"id" query:"id" validate:"required" gorm:"primary_key;unique;not null"`
-	Name        string   `json:"name" form:"name" query:"name" validate:"required" gorm:"not null" groups:"api"`
-	AccessToken string   `json:"accessToken" form:"accessToken" query:"accessToken" validate:"required" gorm:"unique;not null" groups:"api"`
-	Usage       int      `json:"usage" form:"usage" query:"usage" groups:"api"`
-	UsageLimit  int      `json:"usageLimit" form:"usageLimit" query:"usageLimit" groups:"api"`
-	Status      int      `json:"status" form:"status" query:"status" gorm:"not null;default:1"`
-	Chains      []*Chain `json:"-" form:"-" query:"-" gorm:"many2many:users_chains;"`
+	ID          int    `json:"id" form:"id" query:"id" validate:"required" gorm:"primary_key;unique;not null"`
+	Name        string `json:"name" form:"name" query:"name" validate:"required" gorm:"not null" groups:"api"`
+	AccessToken string `json:"accessToken,omitempty" form:"accessToken" query:"accessToken" validate:"required" sql:"-" groups:"api"` // plaintext secret of default API key, returned only once
+	Usage       int    `json:"usage" form:"usage" query:"usage" groups:"api"`
+	UsageLimit  int    `json:"usageLimit" form:"usageLimit" query:"usageLimit" groups:"api"`
+	Status      int    `json:"status" form:"status" query:"status" gorm:"not null;default:1"`
+	// usage counter is reset at the end of the period, see UsagePeriod* constants
+	UsagePeriod       string     `json:"usagePeriod" form:"usagePeriod" query:"usagePeriod" validate:"omitempty,oneof=daily monthly custom" gorm:"not null;default:''" groups:"api"`
+	UsagePeriodDays   int        `json:"usagePeriodDays" form:"usagePeriodDays" query:"usagePeriodDays" validate:"min=0" gorm:"not null;default:0" groups:"api"`
+	UsagePeriodStart  *time.Time `json:"usagePeriodStart" form:"-" query:"-" groups:"api"`
+	UsagePeriodEndsAt *time.Time `json:"usagePeriodEndsAt" form:"-" query:"-" sql:"-" groups:"api"`
+	UsageRemaining    *int       `json:"usageRemaining" form:"-" query:"-" sql:"-" groups:"api"` // remaining allowance of the current period, null — no limit
+	// requests per minute for each group of endpoints: 0 — default limit from config, -1 — no limit
+	RateLimitRead  int      `json:"rateLimitRead" form:"rateLimitRead" query:"rateLimitRead" gorm:"not null;default:0" groups:"api"`
+	RateLimitWrite int      `json:"rateLimitWrite" form:"rateLimitWrite" query:"rateLimitWrite" gorm:"not null;default:0" groups:"api"`
+	RateLimitProxy int      `json:"rateLimitProxy" form:"rateLimitProxy" query:"rateLimitProxy" gorm:"not null;default:0" groups:"api"`
+	Chains         []*Chain `json:"-" form:"-" query:"-" gorm:"many2many:users_chains;"`
+	APIKey         *APIKey  `json:"-" form:"-" query:"-" gorm:"-"` // API key used to authenticate the current request
 }
 
+const (
+	// groups of endpoints limited separately
+	RateLimitGroupRead  = "read"
+	RateLimitGroupWrite = "write"
+	RateLimitGroupProxy = "proxy"
+)
+
 type Users struct {
 	Items []*User
 }
@@ -43,11 +60,23 @@ func (user *User) FilterStruct(groups []string) (interface{}, error) {
 
 }
 
-func (user *User) GenerateAccessToken(n int) string {
-	b := make([]rune, n)
-	rand.Seed(time.Now().UnixNano())
-	for i := range b {
-		b[i] = letterRunes[rand.Intn(len(letterRunes))]
+// GetRateLimit returns user's requests per minute limit for the group of endpoints
+// or 0 if default limit should be used
+func (user *User) GetRateLimit(group string) int {
+
+	switch group {
+	case RateLimitGroupRead:
+		return user.RateLimitRead
+	case RateLimitGroupWrite:
+		return user.RateLimitWrite
+	case RateLimitGroupProxy:
+		return user.RateLimitProxy
 	}
-	return string(b)
+
+	return 0
+
+}
+
+func (user *User) GenerateAccessToken(n int) string {
+	return generateSecret(n)
 }
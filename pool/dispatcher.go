@@ -1,6 +1,12 @@
 package pool
 
 import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/DeFacto-Team/Factom-Open-API/metrics"
+	"github.com/DeFacto-Team/Factom-Open-API/model"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -9,42 +15,105 @@ var WorkerChannel = make(chan chan Work)
 type Collector struct {
 	Work chan Work
 	End  chan bool
+	// closed when all workers are stopped after End signal
+	Done chan bool
+	// work waiting for workers, shared by copies of collector
+	queue *queue
 }
 
 func StartDispatcher(workerCount int) Collector {
 	var i int
-	var workers []Worker
+	var workers []*Worker
+	var wg sync.WaitGroup
 	input := make(chan Work) // channel to recieve work
 	end := make(chan bool)   // channel to spin down workers
-	collector := Collector{Work: input, End: end}
+	done := make(chan bool)  // channel to report workers are stopped
+	queue := newQueue(workerCount)
+	collector := Collector{Work: input, End: end, Done: done, queue: queue}
+
+	metrics.PoolWorkers.Set(float64(workerCount))
+	metrics.PoolWorkersBusy.Set(0)
 
 	for i < workerCount {
 		i++
 		log.Info("Worker start: ", i)
-		worker := Worker{
+		worker := &Worker{
 			ID:            i,
 			Channel:       make(chan Work),
 			WorkerChannel: WorkerChannel,
-			End:           make(chan bool)}
+			End:           make(chan bool),
+			queue:         queue,
+			wg:            &wg}
 		worker.Start()
 		workers = append(workers, worker) // store worker
 	}
 
+	stop := func() {
+		for _, w := range workers {
+			w.Stop() // stop worker
+		}
+		wg.Wait() // wait for the current jobs
+		close(done)
+	}
+
 	// start collector
 	go func() {
+		var available []chan Work // workers waiting for work
 		for {
+			// dispatch work with the highest priority to available workers
+			for len(available) > 0 {
+				work, ok := queue.pop()
+				if !ok {
+					break
+				}
+				available[0] <- work
+				available = available[1:]
+			}
 			select {
 			case <-end:
-				for _, w := range workers {
-					w.Stop() // stop worker
-				}
+				stop()
 				return
 			case work := <-input:
-				worker := <-WorkerChannel // wait for available channel
-				worker <- work            // dispatch work to worker
+				queue.push(work)
+			case worker := <-WorkerChannel:
+				available = append(available, worker)
 			}
 		}
 	}()
 
 	return collector
 }
+
+// Queued returns number of work items waiting for workers
+func (c Collector) Queued() int {
+	return c.queue.len()
+}
+
+// Prioritize changes priority of queued or processed chain, returns false if chain is not in the pool
+func (c Collector) Prioritize(chainID string, priority int) bool {
+	return c.queue.prioritize(chainID, priority)
+}
+
+// Cancel removes chain from the queue, returns false if chain is not queued
+func (c Collector) Cancel(chainID string) bool {
+	return c.queue.cancel(chainID)
+}
+
+// State returns chains processed by workers & chains waiting in the queue
+func (c Collector) State() *model.PoolState {
+	return c.queue.state()
+}
+
+// Stop signals workers to stop after their current jobs and waits until they are stopped or ctx is done
+func (c Collector) Stop(ctx context.Context) error {
+
+	close(c.End)
+
+	select {
+	case <-c.Done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("Pool workers were not stopped in time: %s", ctx.Err())
+	}
+
+}
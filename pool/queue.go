@@ -0,0 +1,220 @@
+package pool
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DeFacto-Team/Factom-Open-API/model"
+)
+
+// queue keeps work waiting for workers ordered by priority, work with equal priority is processed in FIFO order.
+// It also tracks work processed by workers to report pool state
+type queue struct {
+	mu      sync.Mutex
+	items   workHeap
+	queued  map[string]*item
+	running map[int]*item
+	workers int
+	seq     uint64
+}
+
+type item struct {
+	work      Work
+	seq       uint64
+	queuedAt  time.Time
+	startedAt time.Time
+	// index of the item in heap
+	index int
+}
+
+func newQueue(workers int) *queue {
+
+	return &queue{
+		queued:  make(map[string]*item),
+		running: make(map[int]*item),
+		workers: workers,
+	}
+
+}
+
+// push adds work into queue, priority of already queued work is raised instead of adding it twice
+func (q *queue) push(work Work) {
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if it, ok := q.queued[work.ID]; ok {
+		if work.Priority > it.work.Priority {
+			it.work.Priority = work.Priority
+			heap.Fix(&q.items, it.index)
+		}
+		return
+	}
+
+	q.seq++
+	it := &item{work: work, seq: q.seq, queuedAt: time.Now().UTC()}
+	q.queued[work.ID] = it
+	heap.Push(&q.items, it)
+
+}
+
+// pop removes work with the highest priority from queue
+func (q *queue) pop() (Work, bool) {
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.items.Len() == 0 {
+		return Work{}, false
+	}
+
+	it := heap.Pop(&q.items).(*item)
+	delete(q.queued, it.work.ID)
+
+	return it.work, true
+
+}
+
+// start marks work as processed by worker
+func (q *queue) start(workerID int, work Work) {
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.running[workerID] = &item{work: work, startedAt: time.Now().UTC()}
+
+}
+
+// finish marks worker as idle
+func (q *queue) finish(workerID int) {
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.running, workerID)
+
+}
+
+func (q *queue) len() int {
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.items.Len()
+
+}
+
+// prioritize changes priority of queued or processed work, returns false if work is not found
+func (q *queue) prioritize(id string, priority int) bool {
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	found := false
+
+	if it, ok := q.queued[id]; ok {
+		it.work.Priority = priority
+		heap.Fix(&q.items, it.index)
+		found = true
+	}
+
+	for _, it := range q.running {
+		if it.work.ID == id {
+			it.work.Priority = priority
+			found = true
+		}
+	}
+
+	return found
+
+}
+
+// cancel removes work from queue, returns false if work is not queued
+func (q *queue) cancel(id string) bool {
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	it, ok := q.queued[id]
+	if !ok {
+		return false
+	}
+
+	heap.Remove(&q.items, it.index)
+	delete(q.queued, id)
+
+	return true
+
+}
+
+func (q *queue) state() *model.PoolState {
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	state := &model.PoolState{
+		Workers: make([]*model.PoolWorker, 0, q.workers),
+		Queue:   make([]*model.PoolItem, 0, q.items.Len()),
+	}
+
+	for id := 1; id <= q.workers; id++ {
+		worker := &model.PoolWorker{ID: id}
+		if it, ok := q.running[id]; ok {
+			startedAt := it.startedAt
+			worker.ChainID = it.work.ID
+			worker.Priority = it.work.Priority
+			worker.StartedAt = &startedAt
+		}
+		state.Workers = append(state.Workers, worker)
+	}
+
+	// queued work is listed in order of processing, heap itself is not sorted
+	items := make([]*item, q.items.Len())
+	copy(items, q.items)
+	sort.Slice(items, func(i, j int) bool { return higher(items[i], items[j]) })
+	for _, it := range items {
+		queuedAt := it.queuedAt
+		state.Queue = append(state.Queue, &model.PoolItem{ChainID: it.work.ID, Priority: it.work.Priority, QueuedAt: &queuedAt})
+	}
+
+	return state
+
+}
+
+// higher reports if item a should be processed before b
+func higher(a, b *item) bool {
+	if a.work.Priority != b.work.Priority {
+		return a.work.Priority > b.work.Priority
+	}
+	return a.seq < b.seq
+}
+
+// workHeap implements heap.Interface, the top is work with the highest priority, queued first
+type workHeap []*item
+
+func (h workHeap) Len() int { return len(h) }
+
+func (h workHeap) Less(i, j int) bool { return higher(h[i], h[j]) }
+
+func (h workHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *workHeap) Push(x interface{}) {
+	it := x.(*item)
+	it.index = len(*h)
+	*h = append(*h, it)
+}
+
+func (h *workHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	it := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return it
+}
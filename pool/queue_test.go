@@ -0,0 +1,84 @@
+package pool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func popAll(q *queue) []string {
+
+	var ids []string
+	for {
+		work, ok := q.pop()
+		if !ok {
+			return ids
+		}
+		ids = append(ids, work.ID)
+	}
+
+}
+
+func TestQueueOrder(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		pushed   []Work
+		expected []string
+	}{
+		{"empty", nil, nil},
+		{"fifo on equal priority", []Work{{ID: "a"}, {ID: "b"}, {ID: "c"}}, []string{"a", "b", "c"}},
+		{"higher priority first", []Work{{ID: "a"}, {ID: "b", Priority: 20}, {ID: "c", Priority: 10}}, []string{"b", "c", "a"}},
+		{"duplicate is not added", []Work{{ID: "a"}, {ID: "b"}, {ID: "a"}}, []string{"a", "b"}},
+		{"duplicate raises priority", []Work{{ID: "a"}, {ID: "b"}, {ID: "b", Priority: 10}}, []string{"b", "a"}},
+		{"duplicate doesn't lower priority", []Work{{ID: "a", Priority: 10}, {ID: "b", Priority: 5}, {ID: "a"}}, []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		q := newQueue(1)
+		for _, work := range tt.pushed {
+			q.push(work)
+		}
+		assert.Equal(t, tt.expected, popAll(q), tt.name)
+		assert.Equal(t, 0, q.len(), tt.name)
+	}
+
+}
+
+func TestQueuePrioritizeAndCancel(t *testing.T) {
+
+	q := newQueue(2)
+	for _, id := range []string{"a", "b", "c", "d"} {
+		q.push(Work{ID: id})
+	}
+
+	assert.True(t, q.prioritize("c", 10))
+	assert.True(t, q.cancel("b"))
+	assert.False(t, q.cancel("b"))
+	assert.False(t, q.prioritize("unknown", 10))
+
+	// running work is not queued, but its priority is reported
+	q.start(1, Work{ID: "e"})
+	assert.True(t, q.prioritize("e", 20))
+	assert.False(t, q.cancel("e"))
+
+	state := q.state()
+	if assert.Len(t, state.Workers, 2) {
+		assert.Equal(t, "e", state.Workers[0].ChainID)
+		assert.Equal(t, 20, state.Workers[0].Priority)
+		assert.NotNil(t, state.Workers[0].StartedAt)
+		assert.Empty(t, state.Workers[1].ChainID)
+	}
+
+	// queue is listed in order of processing
+	var listed []string
+	for _, it := range state.Queue {
+		listed = append(listed, it.ChainID)
+	}
+	assert.Equal(t, []string{"c", "a", "d"}, listed)
+	assert.Equal(t, listed, popAll(q))
+
+	q.finish(1)
+	assert.Empty(t, q.state().Workers[0].ChainID)
+
+}
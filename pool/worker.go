@@ -1,15 +1,26 @@
 package pool
 
 import (
+	"sync"
+
+	"github.com/DeFacto-Team/Factom-Open-API/metrics"
 	"github.com/DeFacto-Team/Factom-Open-API/model"
 	"github.com/DeFacto-Team/Factom-Open-API/service"
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// number of entry blocks parsed by worker at once, then the chain is returned to unsynced chains,
+	// so large chains don't block the others & the pool picks chains by priority again
+	SliceBlocks = 100
+)
+
 type Work struct {
 	ID      string
 	Job     *model.Chain
 	Service service.Service
+	// work with higher priority is dispatched first
+	Priority int
 }
 
 type Worker struct {
@@ -17,15 +28,26 @@ type Worker struct {
 	WorkerChannel chan chan Work
 	Channel       chan Work
 	End           chan bool
+	queue         *queue
+	wg            *sync.WaitGroup
 }
 
 func (w *Worker) Start() {
+	w.wg.Add(1)
 	go func() {
+		defer w.wg.Done()
 		for {
-			w.WorkerChannel <- w.Channel
+			// register as available or stop
+			select {
+			case w.WorkerChannel <- w.Channel:
+			case <-w.End:
+				return
+			}
 			select {
 			case job := <-w.Channel:
+				w.queue.start(w.ID, job)
 				doWork(job.Job, job.Service, w.ID)
+				w.queue.finish(w.ID)
 			case <-w.End:
 				return
 			}
@@ -35,14 +57,24 @@ func (w *Worker) Start() {
 
 func (w *Worker) Stop() {
 	log.Info("Worker ", w.ID, " stopped")
-	w.End <- true
+	close(w.End)
 }
 
 func doWork(chain *model.Chain, service service.Service, id int) {
 	log.Info("Worker ", id, ", processing ", chain.ChainID)
-	err := service.ParseAllChainEntries(chain, id)
+	metrics.PoolWorkersBusy.Add(1)
+	defer metrics.PoolWorkersBusy.Add(-1)
+	synced, err := service.ParseAllChainEntries(chain, id, SliceBlocks)
 	if err != nil {
 		log.Error(err)
 		service.ResetChainParsing(chain)
+		metrics.ParserChainsParsed.WithLabelValues("failed").Inc()
+		return
+	}
+	if !synced {
+		// parsing is resumed from the checkpoint, when the chain is sent into pool again
+		service.ResetChainParsing(chain)
+		return
 	}
+	metrics.ParserChainsParsed.WithLabelValues("synced").Inc()
 }
@@ -0,0 +1,183 @@
+package proxy
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Method describes factomd API method available via generic factomd interface
+type Method struct {
+	// Write methods change the state of the blockchain or spend node resources on the network
+	Write bool
+	// Validate checks params of the request, nil means method has no params
+	Validate func(params map[string]interface{}) error
+}
+
+// Methods contains all factomd API methods known by proxy
+var Methods = map[string]Method{
+	// read methods
+	"ablock-by-height":      {Validate: requireHeight("height")},
+	"ack":                   {Validate: all(requireHex("hash", 64), requireString("chainid"))},
+	"admin-block":           {Validate: requireHex("keymr", 64)},
+	"chain-head":            {Validate: requireHex("chainid", 64)},
+	"current-minute":        {},
+	"dblock-by-height":      {Validate: requireHeight("height")},
+	"directory-block":       {Validate: requireHex("keymr", 64)},
+	"directory-block-head":  {},
+	"ecblock-by-height":     {Validate: requireHeight("height")},
+	"entry":                 {Validate: requireHex("hash", 64)},
+	"entry-ack":             {Validate: all(requireHex("txid", 64), requireHex("chainid", 64))},
+	"entry-block":           {Validate: requireHex("keymr", 64)},
+	"entry-credit-balance":  {Validate: requireString("address")},
+	"entry-credit-block":    {Validate: requireHex("keymr", 64)},
+	"entry-credit-rate":     {},
+	"factoid-ack":           {Validate: requireHex("txid", 64)},
+	"factoid-balance":       {Validate: requireString("address")},
+	"factoid-block":         {Validate: requireHex("keymr", 64)},
+	"fblock-by-height":      {Validate: requireHeight("height")},
+	"heights":               {},
+	"multiple-ec-balances":  {Validate: requireStrings("addresses")},
+	"multiple-fct-balances": {Validate: requireStrings("addresses")},
+	"pending-entries":       {},
+	"pending-transactions":  {},
+	"properties":            {},
+	"raw-data":              {Validate: requireHex("hash", 64)},
+	"receipt":               {Validate: requireHex("hash", 64)},
+	"transaction":           {Validate: requireHex("hash", 64)},
+	// write methods
+	"commit-chain":     {Write: true, Validate: requireHex("message", 0)},
+	"commit-entry":     {Write: true, Validate: requireHex("message", 0)},
+	"reveal-chain":     {Write: true, Validate: requireHex("entry", 0)},
+	"reveal-entry":     {Write: true, Validate: requireHex("entry", 0)},
+	"factoid-submit":   {Write: true, Validate: requireHex("transaction", 0)},
+	"send-raw-message": {Write: true, Validate: requireHex("message", 0)},
+}
+
+// DefaultMethods returns all read methods, that are allowed if allow-list is not configured
+func DefaultMethods() []string {
+
+	var methods []string
+	for name, method := range Methods {
+		if !method.Write {
+			methods = append(methods, name)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+
+}
+
+// Policy is the allow-list of factomd methods
+type Policy struct {
+	allowed map[string]bool
+}
+
+// NewPolicy creates allow-list from method names. Empty list means DefaultMethods
+func NewPolicy(methods []string) (*Policy, error) {
+
+	if len(methods) == 0 {
+		methods = DefaultMethods()
+	}
+
+	p := &Policy{allowed: make(map[string]bool)}
+	for _, name := range methods {
+		if _, ok := Methods[name]; !ok {
+			return nil, fmt.Errorf("Unknown factomd method '%s' in allow-list", name)
+		}
+		p.allowed[name] = true
+	}
+
+	return p, nil
+
+}
+
+// IsAllowed checks if method is in allow-list
+func (p *Policy) IsAllowed(method string) bool {
+	return p.allowed[method]
+}
+
+// IsWrite checks if method changes the state of the blockchain
+func (p *Policy) IsWrite(method string) bool {
+	return Methods[method].Write
+}
+
+// Validate checks params of the method
+func (p *Policy) Validate(method string, params interface{}) error {
+
+	m, ok := Methods[method]
+	if !ok {
+		return fmt.Errorf("Unknown factomd method '%s'", method)
+	}
+
+	if m.Validate == nil {
+		return nil
+	}
+
+	paramsMap, _ := params.(map[string]interface{})
+	return m.Validate(paramsMap)
+
+}
+
+// helpers
+
+var hexRegexp = regexp.MustCompile("^[0-9a-fA-F]+$")
+
+func all(validators ...func(map[string]interface{}) error) func(map[string]interface{}) error {
+	return func(params map[string]interface{}) error {
+		for _, v := range validators {
+			if err := v(params); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func requireString(name string) func(map[string]interface{}) error {
+	return func(params map[string]interface{}) error {
+		if s, ok := params[name].(string); !ok || s == "" {
+			return fmt.Errorf("'%s' param is required", name)
+		}
+		return nil
+	}
+}
+
+func requireStrings(name string) func(map[string]interface{}) error {
+	return func(params map[string]interface{}) error {
+		list, ok := params[name].([]interface{})
+		if !ok || len(list) == 0 {
+			return fmt.Errorf("'%s' param expected to be a non-empty array", name)
+		}
+		for _, item := range list {
+			if s, ok := item.(string); !ok || s == "" {
+				return fmt.Errorf("'%s' param expected to contain strings only", name)
+			}
+		}
+		return nil
+	}
+}
+
+// requireHex checks if param is hex string of the given length (0 — any length)
+func requireHex(name string, length int) func(map[string]interface{}) error {
+	return func(params map[string]interface{}) error {
+		s, ok := params[name].(string)
+		if !ok || !hexRegexp.MatchString(s) || (length > 0 && len(s) != length) {
+			if length > 0 {
+				return fmt.Errorf("'%s' param expected to be a hex string of %d chars", name, length)
+			}
+			return fmt.Errorf("'%s' param expected to be a hex string", name)
+		}
+		return nil
+	}
+}
+
+func requireHeight(name string) func(map[string]interface{}) error {
+	return func(params map[string]interface{}) error {
+		h, ok := params[name].(float64)
+		if !ok || h < 0 || h != float64(int64(h)) {
+			return fmt.Errorf("'%s' param expected to be a non-negative integer", name)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,79 @@
+package proxy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPolicy(t *testing.T) {
+
+	// default allow-list contains read methods only
+	p, err := NewPolicy(nil)
+	if assert.NoError(t, err) {
+		assert.True(t, p.IsAllowed("heights"))
+		assert.False(t, p.IsAllowed("commit-entry"))
+		assert.False(t, p.IsAllowed("unknown"))
+	}
+
+	p, err = NewPolicy([]string{"commit-entry"})
+	if assert.NoError(t, err) {
+		assert.True(t, p.IsAllowed("commit-entry"))
+		assert.True(t, p.IsWrite("commit-entry"))
+		assert.False(t, p.IsAllowed("heights"))
+	}
+
+	_, err = NewPolicy([]string{"heights", "unknown"})
+	assert.Error(t, err)
+
+}
+
+func TestValidate(t *testing.T) {
+
+	hash := strings.Repeat("a", 64)
+
+	tests := []struct {
+		method string
+		params interface{}
+		valid  bool
+	}{
+		// no params
+		{"heights", nil, true},
+		{"heights", map[string]interface{}{"any": 1}, true},
+		// hex of fixed length
+		{"entry", map[string]interface{}{"hash": hash}, true},
+		{"entry", map[string]interface{}{"hash": strings.ToUpper(hash)}, true},
+		{"entry", map[string]interface{}{"hash": hash[1:]}, false},
+		{"entry", map[string]interface{}{"hash": strings.Repeat("z", 64)}, false},
+		{"entry", map[string]interface{}{"hash": 1}, false},
+		{"entry", nil, false},
+		{"entry", []interface{}{hash}, false},
+		// hex of any length
+		{"commit-entry", map[string]interface{}{"message": "00ff"}, true},
+		{"commit-entry", map[string]interface{}{"message": ""}, false},
+		// all validators are applied
+		{"entry-ack", map[string]interface{}{"txid": hash, "chainid": hash}, true},
+		{"entry-ack", map[string]interface{}{"txid": hash}, false},
+		// non-negative integer height
+		{"dblock-by-height", map[string]interface{}{"height": float64(0)}, true},
+		{"dblock-by-height", map[string]interface{}{"height": float64(-1)}, false},
+		{"dblock-by-height", map[string]interface{}{"height": 1.5}, false},
+		{"dblock-by-height", map[string]interface{}{"height": "1"}, false},
+		// strings
+		{"entry-credit-balance", map[string]interface{}{"address": "EC1"}, true},
+		{"entry-credit-balance", map[string]interface{}{"address": ""}, false},
+		{"multiple-ec-balances", map[string]interface{}{"addresses": []interface{}{"EC1", "EC2"}}, true},
+		{"multiple-ec-balances", map[string]interface{}{"addresses": []interface{}{}}, false},
+		{"multiple-ec-balances", map[string]interface{}{"addresses": []interface{}{"EC1", 2}}, false},
+		// unknown method
+		{"unknown", nil, false},
+	}
+
+	p, _ := NewPolicy(nil)
+	for _, tt := range tests {
+		err := p.Validate(tt.method, tt.params)
+		assert.Equal(t, tt.valid, err == nil, "%s %v: %v", tt.method, tt.params, err)
+	}
+
+}
@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// limits are configured as number of requests per this period
+	Period = time.Minute
+	// buckets that were not used for this time are removed from memory
+	idleTimeout = 10 * time.Minute
+)
+
+// Result of taking a token from the bucket
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// time until the bucket is full again
+	Reset time.Duration
+	// time until the next token is available, set only if request is not allowed
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter keeps token buckets in memory. Every bucket holds up to `limit` tokens
+// and is refilled at the rate of `limit` tokens per Period.
+type Limiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// New creates limiter using system clock
+func New() *Limiter {
+	return NewWithClock(time.Now)
+}
+
+// NewWithClock creates limiter using custom clock
+func NewWithClock(now func() time.Time) *Limiter {
+	return &Limiter{buckets: make(map[string]*bucket), now: now, lastSweep: now()}
+}
+
+// Allow takes a token from the bucket of the key with the limit of requests per Period
+func (l *Limiter) Allow(key string, limit int) Result {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	rate := float64(limit) / Period.Seconds() // tokens per second
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit), last: now}
+		l.buckets[key] = b
+	}
+
+	// refill the bucket
+	b.tokens = math.Min(float64(limit), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	res := Result{Limit: limit}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((float64(limit) - b.tokens) / rate)
+
+	l.sweep(now)
+
+	return res
+
+}
+
+// sweep removes idle buckets, so memory is not growing with the number of users
+func (l *Limiter) sweep(now time.Time) {
+
+	if now.Sub(l.lastSweep) < idleTimeout {
+		return
+	}
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > idleTimeout {
+			delete(l.buckets, key)
+		}
+	}
+
+	l.lastSweep = now
+
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s)) * time.Second
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type step struct {
+	name       string
+	advance    time.Duration
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration
+}
+
+func TestAllow(t *testing.T) {
+
+	now := time.Unix(0, 0)
+	l := NewWithClock(func() time.Time { return now })
+
+	// 60 requests per minute = 1 token per second, the whole bucket is taken at once
+	var tests []step
+	for i := 59; i >= 0; i-- {
+		tests = append(tests, step{"burst", 0, true, i, 0})
+	}
+	tests = append(tests, []step{
+		{"empty bucket", 0, false, 0, time.Second},
+		{"half refilled", 500 * time.Millisecond, false, 0, time.Second},
+		{"refilled token", 500 * time.Millisecond, true, 0, 0},
+		{"bucket is not overfilled", time.Hour, true, 59, 0},
+	}...)
+
+	for _, tt := range tests {
+		now = now.Add(tt.advance)
+		res := l.Allow("user", 60)
+		assert.Equal(t, tt.allowed, res.Allowed, tt.name)
+		assert.Equal(t, tt.remaining, res.Remaining, tt.name)
+		assert.Equal(t, tt.retryAfter, res.RetryAfter, tt.name)
+		assert.Equal(t, 60, res.Limit, tt.name)
+	}
+
+}
+
+func TestAllowKeys(t *testing.T) {
+
+	now := time.Unix(0, 0)
+	l := NewWithClock(func() time.Time { return now })
+
+	// buckets of different keys are independent
+	assert.True(t, l.Allow("a", 1).Allowed)
+	assert.False(t, l.Allow("a", 1).Allowed)
+	assert.True(t, l.Allow("b", 1).Allowed)
+
+	// reset is the time until the bucket is full
+	res := l.Allow("c", 2)
+	assert.Equal(t, 30*time.Second, res.Reset)
+
+}
+
+func TestSweep(t *testing.T) {
+
+	now := time.Unix(0, 0)
+	l := NewWithClock(func() time.Time { return now })
+
+	l.Allow("idle", 10)
+	now = now.Add(idleTimeout + time.Second)
+	l.Allow("active", 10)
+
+	assert.Len(t, l.buckets, 1)
+	assert.Contains(t, l.buckets, "active")
+
+}
@@ -2,41 +2,89 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/DeFacto-Team/Factom-Open-API/logging"
+	"github.com/DeFacto-Team/Factom-Open-API/metrics"
 	"github.com/DeFacto-Team/Factom-Open-API/model"
 	"github.com/DeFacto-Team/Factom-Open-API/store"
+	"github.com/DeFacto-Team/Factom-Open-API/totp"
+	"github.com/DeFacto-Team/Factom-Open-API/tracing"
 	"github.com/DeFacto-Team/Factom-Open-API/wallet"
 	"github.com/FactomProject/factom"
 	"github.com/jinzhu/copier"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Service is an interface with all core functions
 type Service interface {
+	WithContext(ctx context.Context) Service
+
 	GetUser(user *model.User) *model.User
 	GetUsers(user *model.User) []*model.User
 	CreateUser(user *model.User) (*model.User, error)
 	CheckUser(token string) *model.User
 	UpdateUser(user *model.User) error
 	DeleteUser(user *model.User) error
+	RotateUserToken(user *model.User) (*model.User, error)
+	ResetExpiredUsage() error
+	GetUsageHistory(user *model.User) []*model.UsageHistory
+
+	GetAdmin(admin *model.Admin) *model.Admin
+	GetAdmins(admin *model.Admin) []*model.Admin
+	CreateAdmin(admin *model.Admin) (*model.Admin, error)
+	CheckAdmin(username string, password string) *model.Admin
+	UpdateAdmin(admin *model.Admin) error
+	DeleteAdmin(admin *model.Admin) error
+	BootstrapAdmin(username string, password string) error
+	EnrollAdminTOTP(admin *model.Admin) (string, error)
+	EnableAdminTOTP(admin *model.Admin, code string, now time.Time) ([]string, error)
+	DisableAdminTOTP(admin *model.Admin) error
+	VerifyAdminTOTP(admin *model.Admin, code string, now time.Time) bool
+	RegenerateAdminRecoveryCodes(admin *model.Admin) ([]string, error)
+
+	GetSigningJWTKey() (*model.JWTKey, error)
+	GetJWTKey(id int) *model.JWTKey
+	RotateJWTKey() (*model.JWTKey, error)
+	CreateAdminSession(admin *model.Admin, ip string, userAgent string) (*model.AdminSession, error)
+	CheckAdminSession(jti string) *model.AdminSession
+	GetAdminSessions() []*model.AdminSession
+	RevokeAdminSession(session *model.AdminSession) error
+	CleanupAdminSessions() error
+
+	GetAPIKeys(user *model.User) []*model.APIKey
+	CreateAPIKey(key *model.APIKey, user *model.User) (*model.APIKey, error)
+	RevokeAPIKey(key *model.APIKey, user *model.User) error
 
 	GetChain(chain *model.Chain, user *model.User) (*model.Chain, error)
 	GetChains(chain *model.Chain) []*model.Chain
+	GetEntryChain(entry *model.Entry) *model.Chain
+	GetTrackedChains(chain *model.Chain) []*model.Chain
 	GetUserChains(chain *model.Chain, user *model.User, start int, limit int, sort string) ([]*model.Chain, int)
 	SearchUserChains(chain *model.Chain, user *model.User, start int, limit int, sort string) ([]*model.Chain, int)
+	SearchChains(chain *model.Chain, user *model.User, start int, limit int, sort string) ([]*model.Chain, int)
+	GetChainsToSync(limit int) []*model.Chain
 	SetChainSentToPool(chain *model.Chain) error
 	ResetChainParsing(chain *model.Chain) error
 	ResetChainsParsingAtAPIStart() error
 	CreateChain(chain *model.Chain, user *model.User) (*model.Chain, error)
+	UnbindChain(chain *model.Chain, user *model.User) error
+	PinChain(chain *model.Chain, pinned bool) error
+	PurgeUntrackedChains(before time.Time) (int, error)
 	GetChainEntries(entry *model.Entry, user *model.User, start int, limit int, sort string, force bool) ([]*model.Entry, int, error)
 	SearchChainEntries(entry *model.Entry, user *model.User, start int, limit int, sort string, force bool) ([]*model.Entry, int, error)
 	GetChainFirstOrLastEntry(entry *model.Entry, sort string, user *model.User) (*model.Entry, error)
 
 	GetEntry(entry *model.Entry, user *model.User) (*model.Entry, error)
 	CreateEntry(entry *model.Entry, user *model.User) (*model.Entry, error)
+	MigrateContent(limit int) (int, error)
 
 	GetQueue(queue *model.Queue) []*model.Queue
 	GetQueueToProcess() []*model.Queue
@@ -44,25 +92,163 @@ type Service interface {
 	ProcessQueue(queue *model.Queue) error
 	ClearQueue(queue *model.Queue) error
 	DeleteQueue(queue *model.Queue) error
+	RetryQueue(queue *model.Queue) error
+	PurgeQueue(all bool) (int, error)
+	ResyncChain(chain *model.Chain) error
+	SetChainSyncPriority(chain *model.Chain, priority int) error
+	CancelChainSync(chain *model.Chain) error
 
-	ParseAllChainEntries(chain *model.Chain, workerID int) error
+	ParseAllChainEntries(chain *model.Chain, workerID int, maxBlocks int) (bool, error)
 	ParseNewChainEntries(chain *model.Chain) error
+	ParseDBlock(height int) error
+	IndexDBlock(height int) (int, error)
+
+	Audit(entry *model.AuditLog)
+	GetAuditLogs(entry *model.AuditLog, from *time.Time, to *time.Time, start int, limit int, sort string) ([]*model.AuditLog, int)
+	SetWallet(wallet wallet.Wallet)
+	SetScheduler(scheduler Scheduler)
+	GetPoolState() *model.PoolState
 
 	GetCallback(callback *model.Callback) *model.Callback
 	GetCallbacks(callback *model.Callback) []*model.Callback
 	CreateCallback(entryHash string, url string, user *model.User) error
 	SendCallback(callback *model.Callback) error
+
+	UpdateMetrics() error
+	CheckReadiness() *model.Readiness
+	GetUpdatesHeight() int
+	SetUpdatesHeight(height int) error
+	GetIndexHeight() int
+	SetIndexHeight(height int) error
+	GetSyncProgress() *model.SyncProgress
+}
+
+// Scheduler is a queue of chains waiting for history sync, implemented by history fetching pool
+type Scheduler interface {
+	// Prioritize changes priority of the queued or processed chain, returns false if chain is not in the pool
+	Prioritize(chainID string, priority int) bool
+	// Cancel removes chain from the queue, returns false if chain is not queued
+	Cancel(chainID string) bool
+	State() *model.PoolState
 }
 
+// chains of admin, entry credit & factoid blocks, which have no entries
+var systemChains = map[string]bool{
+	"000000000000000000000000000000000000000000000000000000000000000a": true,
+	"000000000000000000000000000000000000000000000000000000000000000c": true,
+	"000000000000000000000000000000000000000000000000000000000000000f": true,
+}
+
+// bcrypt hash of random string, used to compare passwords of non-existing admins
+const dummyPasswordHash = "$2a$10$XI2LD0VaRsCUlRwGbhDVKuUJzufiM9ST3OxTBWxCjfrVSQ1Onazde"
+
+const (
+	// number of chains purged by retention policy at once
+	PurgeBatchSize = 100
+	// content saved more recently is not purged, as its entry may be not created yet
+	PurgeContentDelay = time.Hour
+	// readiness is degraded if factomd entry blocks are behind dblocks more than this
+	ReadyMaxSyncGap = 1
+	// readiness is degraded if the oldest unprocessed queue item is older
+	ReadyMaxQueueAge = 10 * time.Minute
+	// readiness is degraded if updates parser is behind factomd more than this number of dblocks
+	ReadyMaxParserLag = 2
+)
+
 // NewService initializes service with store & wallet as ServiceContext
 func NewService(store store.Store, wallet wallet.Wallet) Service {
-	return &Context{store: store, wallet: wallet}
+	return &Context{store: store, state: &state{wallet: wallet}, logger: log.NewEntry(log.StandardLogger()), ctx: context.Background()}
 }
 
 // Context keeps store & wallet instances
 type Context struct {
 	store  store.Store
-	wallet wallet.Wallet
+	state  *state
+	logger *log.Entry
+	// ID of HTTP request served by this context, saved into queue to trace writes
+	requestID string
+	// parent of factomd calls spans
+	ctx context.Context
+}
+
+// state is shared by all contexts created by WithContext
+type state struct {
+	wallet   wallet.Wallet
+	walletMu sync.RWMutex
+	// history fetching pool, nil until pool is started
+	scheduler   Scheduler
+	schedulerMu sync.RWMutex
+	// latest dblock height processed by updates parser
+	updatesHeight int64
+}
+
+// WithContext returns service, which logs & queue items carry request ID from ctx
+// and factomd calls are traced as children of span from ctx
+func (c *Context) WithContext(ctx context.Context) Service {
+
+	return c.withContext(ctx)
+
+}
+
+func (c *Context) withContext(ctx context.Context) *Context {
+
+	return &Context{
+		store:     c.store.WithContext(ctx),
+		state:     c.state,
+		logger:    logging.FromContext(ctx),
+		requestID: logging.RequestID(ctx),
+		ctx:       ctx,
+	}
+
+}
+
+// SetWallet replaces wallet used to write data on the blockchain, nil disables writing
+func (c *Context) SetWallet(wallet wallet.Wallet) {
+
+	c.state.walletMu.Lock()
+	defer c.state.walletMu.Unlock()
+
+	c.state.wallet = wallet
+
+}
+
+func (c *Context) getWallet() wallet.Wallet {
+
+	c.state.walletMu.RLock()
+	defer c.state.walletMu.RUnlock()
+
+	return c.state.wallet
+
+}
+
+// SetScheduler sets history fetching pool, which is notified about chains sync priority changes
+func (c *Context) SetScheduler(scheduler Scheduler) {
+
+	c.state.schedulerMu.Lock()
+	defer c.state.schedulerMu.Unlock()
+
+	c.state.scheduler = scheduler
+
+}
+
+func (c *Context) getScheduler() Scheduler {
+
+	c.state.schedulerMu.RLock()
+	defer c.state.schedulerMu.RUnlock()
+
+	return c.state.scheduler
+
+}
+
+// GetPoolState returns state of history fetching pool, empty if pool is not started
+func (c *Context) GetPoolState() *model.PoolState {
+
+	if scheduler := c.getScheduler(); scheduler != nil {
+		return scheduler.State()
+	}
+
+	return &model.PoolState{Workers: []*model.PoolWorker{}, Queue: []*model.PoolItem{}}
+
 }
 
 // GetUser is generic function to get user from db
@@ -79,89 +265,597 @@ func (c *Context) GetUsers(user *model.User) []*model.User {
 
 }
 
-// CreateUser is generic function to create user into DB
+// CreateUser creates user into DB. If user.AccessToken is set, it becomes the secret of user's default API key
 func (c *Context) CreateUser(user *model.User) (*model.User, error) {
 
+	token := user.AccessToken
+
+	if err := user.ValidateUsagePeriod(); err != nil {
+		return nil, err
+	}
+	if user.UsagePeriod != model.UsagePeriodLifetime {
+		start := user.NextUsagePeriodStart(time.Now())
+		user.UsagePeriodStart = &start
+	}
+
 	resp, err := c.store.CreateUser(user)
 	if err != nil {
 		return nil, err
 	}
 
+	if token != "" {
+		key := &model.APIKey{UserID: resp.ID, Name: model.DefaultAPIKeyName}
+		key.SetSecret(token)
+		if _, err := c.store.CreateAPIKey(key); err != nil {
+			return nil, err
+		}
+		resp.AccessToken = token
+	}
+
 	return resp, nil
 }
 
-// CheckUser returns only enabled users by their access token
+// CheckUser returns only enabled users by the secret of their valid API key.
+// The key itself is returned in user.APIKey
 func (c *Context) CheckUser(token string) *model.User {
-	return c.store.GetUser(&model.User{AccessToken: token, Status: 1})
+
+	key := c.store.GetAPIKey(&model.APIKey{SecretHash: model.HashAPIKeySecret(token)})
+	if key == nil || key.IsExpired() {
+		return nil
+	}
+
+	user := c.store.GetUser(&model.User{ID: key.UserID, Status: 1})
+	if user == nil {
+		return nil
+	}
+	user.APIKey = key
+
+	// usage counter is reset here as well as by background worker,
+	// so limits of the new period are applied immediately
+	if user.UsagePeriodExpired(time.Now()) {
+		if resp, err := c.store.ResetUserUsage(user, time.Now()); err != nil {
+			c.logger.Error(err)
+		} else {
+			resp.APIKey = key
+			user = resp
+		}
+	}
+
+	if key.NeedsTouch() {
+		now := time.Now()
+		if err := c.store.UpdateAPIKey(&model.APIKey{ID: key.ID, LastUsedAt: &now}); err != nil {
+			c.logger.Error(err)
+		}
+	}
+
+	return user
 }
 
-// UpdateUser is generic function to update user into DB
+// UpdateUser is generic function to update user into DB.
+// If usage period of the user is changed, the new period starts now
 func (c *Context) UpdateUser(user *model.User) error {
 
+	if err := user.ValidateUsagePeriod(); err != nil {
+		return err
+	}
+
+	if localUser := c.store.GetUser(&model.User{ID: user.ID}); localUser != nil {
+		if localUser.UsagePeriod != user.UsagePeriod || localUser.UsagePeriodDays != user.UsagePeriodDays {
+			user.UsagePeriodStart = nil
+			start := user.NextUsagePeriodStart(time.Now())
+			user.UsagePeriodStart = &start
+		}
+	}
+
 	err := c.store.UpdateUser(user)
 	if err != nil {
-		return err
+		return err
+	}
+
+	return nil
+
+}
+
+// UpdateUser is generic function to delete user from DB
+func (c *Context) DeleteUser(user *model.User) error {
+
+	err := c.store.DeleteUser(user)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RotateUserToken revokes default API key of the user and creates the new one instead.
+// Plaintext secret of the new key is returned in user.AccessToken
+func (c *Context) RotateUserToken(user *model.User) (*model.User, error) {
+
+	for _, key := range c.store.GetAPIKeys(&model.APIKey{UserID: user.ID, Name: model.DefaultAPIKeyName}) {
+		if err := c.store.DeleteAPIKey(key); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := c.CreateAPIKey(&model.APIKey{Name: model.DefaultAPIKeyName}, user)
+	if err != nil {
+		return nil, err
+	}
+
+	user.AccessToken = key.Secret
+
+	return user, nil
+
+}
+
+// ResetExpiredUsage resets usage counters of all users, which usage period is over
+func (c *Context) ResetExpiredUsage() error {
+
+	now := time.Now()
+
+	for _, user := range c.store.GetUsers(&model.User{}) {
+		if !user.UsagePeriodExpired(now) {
+			continue
+		}
+		if _, err := c.store.ResetUserUsage(user, now); err != nil {
+			return err
+		}
+		c.logger.Info("Usage of user ", user.Name, " is reset for the new ", user.UsagePeriod, " period")
+	}
+
+	return nil
+
+}
+
+// GetUsageHistory returns usage of the user for the finished periods, the latest first
+func (c *Context) GetUsageHistory(user *model.User) []*model.UsageHistory {
+
+	return c.store.GetUsageHistory(&model.UsageHistory{UserID: user.ID})
+
+}
+
+// GetAdmin is generic function to get admin from db
+func (c *Context) GetAdmin(admin *model.Admin) *model.Admin {
+
+	return c.store.GetAdmin(admin)
+
+}
+
+// GetAdmins is generic function to get items from admins db
+func (c *Context) GetAdmins(admin *model.Admin) []*model.Admin {
+
+	return c.store.GetAdmins(admin)
+
+}
+
+// CreateAdmin stores admin with the hash of admin.Password into DB
+func (c *Context) CreateAdmin(admin *model.Admin) (*model.Admin, error) {
+
+	if admin.Password == "" {
+		return nil, fmt.Errorf("Password is required")
+	}
+
+	if c.store.GetAdmin(&model.Admin{Username: admin.Username}) != nil {
+		return nil, fmt.Errorf("Admin '%s' already exists", admin.Username)
+	}
+
+	if err := admin.SetPassword(admin.Password); err != nil {
+		return nil, err
+	}
+
+	return c.store.CreateAdmin(admin)
+
+}
+
+// CheckAdmin returns enabled admin by username & password
+func (c *Context) CheckAdmin(username string, password string) *model.Admin {
+
+	var admin *model.Admin
+	// empty username would be skipped by gorm and match any enabled admin
+	if username != "" {
+		admin = c.store.GetEnabledAdmin(username)
+	}
+	if admin == nil {
+		// compare with the dummy hash anyway, so response time doesn't reveal existing usernames
+		dummy := &model.Admin{PasswordHash: dummyPasswordHash}
+		dummy.CheckPassword(password)
+		return nil
+	}
+
+	if !admin.CheckPassword(password) {
+		return nil
+	}
+
+	now := time.Now()
+	if err := c.store.UpdateAdmin(&model.Admin{ID: admin.ID, Status: admin.Status, LastLoginAt: &now}); err != nil {
+		c.logger.Error(err)
+	}
+	admin.LastLoginAt = &now
+
+	return admin
+
+}
+
+// UpdateAdmin updates admin into DB. Password is changed only if admin.Password is set
+func (c *Context) UpdateAdmin(admin *model.Admin) error {
+
+	revoke := admin.Password != "" || admin.Status == 0
+
+	if admin.Password != "" {
+		if err := admin.SetPassword(admin.Password); err != nil {
+			return err
+		}
+	}
+
+	if err := c.store.UpdateAdmin(admin); err != nil {
+		return err
+	}
+
+	// password change or disabling logs admin out everywhere
+	if revoke {
+		return c.store.RevokeAdminSessions(&model.AdminSession{AdminID: admin.ID}, time.Now())
+	}
+
+	return nil
+
+}
+
+// DeleteAdmin deletes admin from DB and revokes admin's sessions
+func (c *Context) DeleteAdmin(admin *model.Admin) error {
+
+	if admin.ID == 0 {
+		return fmt.Errorf("Admin ID is required")
+	}
+
+	if err := c.store.RevokeAdminSessions(&model.AdminSession{AdminID: admin.ID}, time.Now()); err != nil {
+		return err
+	}
+
+	return c.store.DeleteAdmin(admin)
+
+}
+
+// BootstrapAdmin creates superadmin from config credentials, if there are no admins in DB yet.
+// After that config credentials are not used for login anymore
+func (c *Context) BootstrapAdmin(username string, password string) error {
+
+	if len(c.store.GetAdmins(&model.Admin{})) > 0 {
+		return nil
+	}
+
+	if username == "" || password == "" {
+		c.logger.Warn("No admins found. Set admin user & password in config to create the first superadmin")
+		return nil
+	}
+
+	_, err := c.CreateAdmin(&model.Admin{Username: username, Password: password, Role: model.AdminRoleSuperadmin})
+	if err != nil {
+		return err
+	}
+
+	c.logger.Info("Superadmin '", username, "' created from config credentials")
+
+	return nil
+
+}
+
+// EnrollAdminTOTP generates the new TOTP secret of admin. TOTP is not required for login
+// until it's enabled by the first valid code, so enrolment may be restarted any time
+func (c *Context) EnrollAdminTOTP(admin *model.Admin) (string, error) {
+
+	if admin.TOTPEnabled {
+		return "", fmt.Errorf("Two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", err
+	}
+
+	admin.TOTPSecret = secret
+	admin.TOTPLastStep = 0
+
+	if err := c.store.UpdateAdminTOTP(admin); err != nil {
+		return "", err
+	}
+
+	return secret, nil
+
+}
+
+// EnableAdminTOTP enables TOTP of enrolled admin if the code is valid and returns plaintext recovery codes
+func (c *Context) EnableAdminTOTP(admin *model.Admin, code string, now time.Time) ([]string, error) {
+
+	if admin.TOTPEnabled {
+		return nil, fmt.Errorf("Two-factor authentication is already enabled")
+	}
+
+	if admin.TOTPSecret == "" {
+		return nil, fmt.Errorf("Two-factor authentication is not enrolled")
+	}
+
+	step, ok := totp.Validate(admin.TOTPSecret, code, now)
+	if !ok {
+		return nil, fmt.Errorf("Invalid two-factor authentication code")
+	}
+
+	admin.TOTPEnabled = true
+	admin.TOTPLastStep = step
+
+	if err := c.store.UpdateAdminTOTP(admin); err != nil {
+		return nil, err
+	}
+
+	return c.RegenerateAdminRecoveryCodes(admin)
+
+}
+
+// DisableAdminTOTP removes TOTP secret & recovery codes of admin
+func (c *Context) DisableAdminTOTP(admin *model.Admin) error {
+
+	admin.TOTPSecret = ""
+	admin.TOTPEnabled = false
+	admin.TOTPLastStep = 0
+
+	if err := c.store.UpdateAdminTOTP(admin); err != nil {
+		return err
+	}
+
+	return c.store.ReplaceAdminRecoveryCodes(admin, nil)
+
+}
+
+// VerifyAdminTOTP checks TOTP code or unused recovery code of admin. Every code is accepted only once
+func (c *Context) VerifyAdminTOTP(admin *model.Admin, code string, now time.Time) bool {
+
+	if !admin.TOTPEnabled {
+		return false
+	}
+
+	if step, ok := totp.Validate(admin.TOTPSecret, code, now); ok {
+		return c.store.UseAdminTOTPStep(admin, step)
+	}
+
+	return c.store.UseAdminRecoveryCode(admin, model.HashRecoveryCode(code), now)
+
+}
+
+// RegenerateAdminRecoveryCodes replaces recovery codes of admin with the new ones.
+// Only hashes are stored, plaintext codes are returned once
+func (c *Context) RegenerateAdminRecoveryCodes(admin *model.Admin) ([]string, error) {
+
+	codes, records := model.GenerateRecoveryCodes(admin)
+
+	if err := c.store.ReplaceAdminRecoveryCodes(admin, records); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+
+}
+
+// GetSigningJWTKey returns the latest not retired JWT key, the first key is created if there are no keys yet
+func (c *Context) GetSigningJWTKey() (*model.JWTKey, error) {
+
+	for _, key := range c.store.GetJWTKeys(&model.JWTKey{}) {
+		if key.RetiredAt == nil {
+			return key, nil
+		}
+	}
+
+	return c.RotateJWTKey()
+
+}
+
+// GetJWTKey returns JWT key by ID, including retired keys, which are not deleted yet
+func (c *Context) GetJWTKey(id int) *model.JWTKey {
+
+	if id == 0 {
+		return nil
+	}
+
+	return c.store.GetJWTKey(&model.JWTKey{ID: id})
+
+}
+
+// RotateJWTKey creates the new signing key and retires previous ones.
+// Tokens signed by retired keys are valid until they expire
+func (c *Context) RotateJWTKey() (*model.JWTKey, error) {
+
+	key, err := c.store.CreateJWTKey(model.NewJWTKey())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.store.RetireJWTKeys(key, time.Now()); err != nil {
+		return nil, err
+	}
+
+	c.logger.Info("JWT signing key rotated")
+
+	return key, nil
+
+}
+
+// CreateAdminSession starts the new session of admin
+func (c *Context) CreateAdminSession(admin *model.Admin, ip string, userAgent string) (*model.AdminSession, error) {
+
+	now := time.Now()
+
+	session := model.NewAdminSession(admin, now)
+	session.IP = ip
+	session.UserAgent = userAgent
+	session.LastSeenAt = &now
+
+	return c.store.CreateAdminSession(session)
+
+}
+
+// CheckAdminSession returns active session by JTI and touches its last activity time
+func (c *Context) CheckAdminSession(jti string) *model.AdminSession {
+
+	if jti == "" {
+		return nil
+	}
+
+	now := time.Now()
+
+	session := c.store.GetAdminSession(&model.AdminSession{JTI: jti})
+	if session == nil || !session.IsActive(now) {
+		return nil
+	}
+
+	if session.NeedsTouch(now) {
+		if err := c.store.UpdateAdminSession(&model.AdminSession{ID: session.ID, LastSeenAt: &now}); err != nil {
+			c.logger.Error(err)
+		}
+		session.LastSeenAt = &now
+	}
+
+	return session
+
+}
+
+// GetAdminSessions returns all active sessions of all admins
+func (c *Context) GetAdminSessions() []*model.AdminSession {
+
+	return c.store.GetActiveAdminSessions(&model.AdminSession{}, time.Now())
+
+}
+
+// RevokeAdminSession invalidates the session, so its JWT is not accepted anymore
+func (c *Context) RevokeAdminSession(session *model.AdminSession) error {
+
+	if session.ID == 0 {
+		return fmt.Errorf("Session ID is required")
+	}
+
+	return c.store.RevokeAdminSessions(&model.AdminSession{ID: session.ID}, time.Now())
+
+}
+
+// CleanupAdminSessions deletes expired sessions & keys, which can't verify any valid token,
+// and rotates the signing key if it's too old
+func (c *Context) CleanupAdminSessions() error {
+
+	now := time.Now()
+	expired := now.Add(-model.AdminSessionLifetime)
+
+	if err := c.store.DeleteAdminSessionsExpiredBefore(now); err != nil {
+		return err
+	}
+
+	if err := c.store.DeleteJWTKeysRetiredBefore(expired); err != nil {
+		return err
+	}
+
+	key, err := c.GetSigningJWTKey()
+	if err != nil {
+		return err
+	}
+
+	if key.NeedsRotation(now) {
+		if _, err := c.RotateJWTKey(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+
+}
+
+// GetAPIKeys returns all active API keys of the user
+func (c *Context) GetAPIKeys(user *model.User) []*model.APIKey {
+
+	return c.store.GetAPIKeys(&model.APIKey{UserID: user.ID})
+
+}
+
+// CreateAPIKey generates secret for the new API key of the user.
+// Plaintext secret is returned in key.Secret and never stored
+func (c *Context) CreateAPIKey(key *model.APIKey, user *model.User) (*model.APIKey, error) {
+
+	key.ID = 0
+	key.UserID = user.ID
+	key.SetSecret(user.GenerateAccessToken(model.APIKeySecretLength))
+
+	resp, err := c.store.CreateAPIKey(key)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return resp, nil
 
 }
 
-// UpdateUser is generic function to delete user from DB
-func (c *Context) DeleteUser(user *model.User) error {
+// RevokeAPIKey deletes API key of the user
+func (c *Context) RevokeAPIKey(key *model.APIKey, user *model.User) error {
 
-	err := c.store.DeleteUser(user)
-	if err != nil {
-		return err
+	if key.ID == 0 {
+		return fmt.Errorf("API key ID is required")
 	}
 
-	return nil
+	if user.ID == 0 {
+		return fmt.Errorf("User ID is required")
+	}
+
+	localKey := c.store.GetUserAPIKey(key.ID, user.ID)
+	if localKey == nil {
+		return fmt.Errorf("API key %d not found", key.ID)
+	}
+
+	return c.store.DeleteAPIKey(localKey)
+
 }
 
 // GetChain is high-level function, that run by api.GetChain()
 func (c *Context) GetChain(chain *model.Chain, user *model.User) (*model.Chain, error) {
 
-	log.Debug("Search for chain into local DB")
+	c.logger.Debug("Search for chain into local DB")
 
 	// search for chain.ChainID into local DB
 	localChain := c.store.GetChain(chain)
 
 	if localChain != nil {
-		log.Debug("Chain " + chain.ChainID + " found into local DB")
+		c.logger.Debug("Chain " + chain.ChainID + " found into local DB")
 
-		log.Debug("Force binding chain ", chain.ChainID, " to user ", user.Name)
+		c.logger.Debug("Force binding chain ", chain.ChainID, " to user ", user.Name)
 		err := c.store.BindChainToUser(chain, user)
 		if err != nil {
-			log.Error(err)
+			c.logger.Error(err)
 		}
 
+		c.prioritizeChain(localChain, model.ChainPriorityAccessed)
+
 		// localChain already base64 encoded
 		return localChain, nil
 	}
 
-	log.Debug("Chain " + chain.ChainID + " not found into local DB")
-	log.Debug("Search for chain on the blockchain")
+	c.logger.Debug("Chain " + chain.ChainID + " not found into local DB")
+	c.logger.Debug("Search for chain on the blockchain")
 
-	if chain.Exists() {
+	if c.chainExists(chain) {
 		chain = chain.Base64Encode()
-		log.Debug("Chain " + chain.ChainID + " found on the blockchain")
+		c.logger.Debug("Chain " + chain.ChainID + " found on the blockchain")
 
-		log.Debug("Getting chain status from the blockchain")
-		chain.Status, chain.LatestEntryBlock = chain.GetStatusFromFactom()
+		c.logger.Debug("Getting chain status from the blockchain")
+		chain.Status, chain.LatestEntryBlock = c.chainStatus(chain)
 
-		log.Debug("Creating chain into local DB")
+		c.logger.Debug("Creating chain into local DB")
 		err := c.store.CreateChain(chain)
 		if err != nil {
-			log.Error(err)
+			c.logger.Error(err)
 		}
 
 		// If we are here, so no errors occured and we force bind chain to API user
-		log.Debug("Force binding chain ", chain.ChainID, " to user ", user.Name)
+		c.logger.Debug("Force binding chain ", chain.ChainID, " to user ", user.Name)
 		err = c.store.BindChainToUser(chain, user)
 		if err != nil {
-			log.Error(err)
+			c.logger.Error(err)
 		}
 
+		c.prioritizeChain(chain, model.ChainPriorityAccessed)
+
 		return chain, nil
 	}
 
@@ -175,6 +869,20 @@ func (c *Context) GetChains(chain *model.Chain) []*model.Chain {
 
 }
 
+// GetEntryChain returns chain of entry from local DB without fetching entry from Factom
+func (c *Context) GetEntryChain(entry *model.Entry) *model.Chain {
+
+	return c.store.GetEntryChain(entry)
+
+}
+
+// GetTrackedChains returns chains, which are pinned or bound to users, so they're updated by updates parser
+func (c *Context) GetTrackedChains(chain *model.Chain) []*model.Chain {
+
+	return c.store.GetTrackedChains(chain)
+
+}
+
 // GetUserChains is high-level function, that run by api.GetChains()
 func (c *Context) GetUserChains(chain *model.Chain, user *model.User, start int, limit int, sort string) ([]*model.Chain, int) {
 
@@ -189,6 +897,20 @@ func (c *Context) SearchUserChains(chain *model.Chain, user *model.User, start i
 
 }
 
+// SearchChains is high-level function, that run by api.SearchChains() if chains indexer is enabled
+func (c *Context) SearchChains(chain *model.Chain, user *model.User, start int, limit int, sort string) ([]*model.Chain, int) {
+
+	return c.store.SearchChains(chain, user, start, limit, sort)
+
+}
+
+// GetChainsToSync returns not synced chains to be sent to history parser, chains bound to users go first
+func (c *Context) GetChainsToSync(limit int) []*model.Chain {
+
+	return c.store.GetChainsToSync(limit)
+
+}
+
 // SetChainSentToPool marks chain as sent into history fetching pool for not sending it into pool more than once
 func (c *Context) SetChainSentToPool(chain *model.Chain) error {
 
@@ -205,6 +927,24 @@ func (c *Context) ResetChainParsing(chain *model.Chain) error {
 
 }
 
+// prioritizeChain raises history sync priority of not synced chain requested by user
+func (c *Context) prioritizeChain(chain *model.Chain, priority int) {
+
+	raised, err := c.store.RaiseChainSyncPriority(chain, priority)
+	if err != nil {
+		c.logger.Error(err)
+		return
+	}
+
+	if raised {
+		c.logger.Debug("Chain ", chain.ChainID, " sync priority raised to ", priority)
+		if scheduler := c.getScheduler(); scheduler != nil {
+			scheduler.Prioritize(chain.ChainID, priority)
+		}
+	}
+
+}
+
 // ResetChainsParsingAtAPIStart resets WorkerID & SentToPool params of unsynced chains on API start, to let them finish syncing
 func (c *Context) ResetChainsParsingAtAPIStart() error {
 
@@ -218,7 +958,7 @@ func (c *Context) CreateChain(chain *model.Chain, user *model.User) (*model.Chai
 
 	chain = chain.Base64Decode()
 
-	log.Debug("Checking if first entry of chain fits into 10KB")
+	c.logger.Debug("Checking if first entry of chain fits into 10KB")
 	_, err := chain.ConvertToEntryModel().Fit10KB()
 	if err != nil {
 		return nil, err
@@ -229,8 +969,8 @@ func (c *Context) CreateChain(chain *model.Chain, user *model.User) (*model.Chai
 	chain.Status = model.ChainQueue
 
 	// check if chain exists on Factom
-	if chain.Exists() == true {
-		log.Error("Chain " + chain.ChainID + " already exists on Factom")
+	if c.chainExists(chain) == true {
+		c.logger.Error("Chain " + chain.ChainID + " already exists on Factom")
 		return nil, fmt.Errorf("Chain " + chain.ChainID + " exists")
 	}
 
@@ -238,50 +978,115 @@ func (c *Context) CreateChain(chain *model.Chain, user *model.User) (*model.Chai
 	localChain := c.store.GetChain(&model.Chain{ChainID: chain.ChainID})
 
 	if localChain != nil {
-		log.Error("Chain " + chain.ChainID + " already into local DB")
+		c.logger.Error("Chain " + chain.ChainID + " already into local DB")
 		return nil, fmt.Errorf("Chain " + chain.ChainID + " exists")
 	}
 
-	log.Debug("Chain ", chain.ChainID, " not found both on Factom & into local DB")
+	c.logger.Debug("Chain ", chain.ChainID, " not found both on Factom & into local DB")
 
 	// new chain & entry into local DB will be created with FactomTime=NOW()
 	timeNow := time.Now().UTC().Round(time.Second)
 	chain.FactomTime = &timeNow
 
-	log.Debug("Creating chain into local DB")
+	c.logger.Debug("Creating chain into local DB")
 	err = c.store.CreateChain(chain.Base64Encode())
 	if err != nil {
-		log.Error(err)
+		c.logger.Error(err)
 	}
 
-	log.Debug("Creating entry into local DB")
+	c.logger.Debug("Creating entry into local DB")
 	err = c.store.CreateEntry(chain.ConvertToEntryModel().Base64Encode())
 	if err != nil {
-		log.Error(err)
+		c.logger.Error(err)
 	}
 
 	err = c.addToQueue(chain.ConvertToQueueParams(), model.QueueActionChain, user)
 	if err != nil {
-		log.Error(err)
+		c.logger.Error(err)
 	}
 
 	// If we are here, so no errors occured and we force bind chain to API user
-	log.Debug("Force binding chain ", chain.ChainID, " to user ", user.Name)
+	c.logger.Debug("Force binding chain ", chain.ChainID, " to user ", user.Name)
 	err = c.store.BindChainToUser(chain, user)
 	if err != nil {
-		log.Error(err)
+		c.logger.Error(err)
 	}
 
 	return chain.Base64Encode(), nil
 
 }
 
+// UnbindChain is high-level function, that run by api.DeleteChain().
+// Chain, which is not pinned & has no users anymore, is not updated & its entries are purged by retention policy
+func (c *Context) UnbindChain(chain *model.Chain, user *model.User) error {
+
+	if c.store.GetChain(&model.Chain{ChainID: chain.ChainID}) == nil {
+		return fmt.Errorf("Chain %s not found", chain.ChainID)
+	}
+
+	c.logger.Debug("Unbinding chain ", chain.ChainID, " from user ", user.Name)
+
+	return c.store.UnbindChainFromUser(chain, user)
+
+}
+
+// PinChain pins chain, so it's tracked even if it has no users, or unpins it (accessible via Admin endpoint)
+func (c *Context) PinChain(chain *model.Chain, pinned bool) error {
+
+	if c.store.GetChain(&model.Chain{ChainID: chain.ChainID}) == nil {
+		return fmt.Errorf("Chain %s not found", chain.ChainID)
+	}
+
+	return c.store.SetChainPinned(chain, pinned)
+
+}
+
+// PurgeUntrackedChains deletes entries of chains, which are not tracked since before. Entry blocks are kept,
+// so chain history is synced again if chain is tracked again. Content of deleted entries is deleted from content store,
+// if it's not referenced by other entries. Returns number of purged chains
+func (c *Context) PurgeUntrackedChains(before time.Time) (int, error) {
+
+	var n int
+
+	for {
+
+		chains := c.store.GetChainsToPurge(before, PurgeBatchSize)
+
+		for _, chain := range chains {
+			entries, err := c.store.PurgeChainEntries(chain)
+			if err != nil {
+				return n, err
+			}
+			c.logger.Info("Retention policy: Chain ", chain.ChainID, " purged, ", entries, " entries deleted")
+			n++
+		}
+
+		if len(chains) < PurgeBatchSize {
+			break
+		}
+
+	}
+
+	if n == 0 {
+		return n, nil
+	}
+
+	contents, err := c.store.PurgeUnreferencedContent(time.Now().UTC().Add(-PurgeContentDelay))
+	if err != nil {
+		return n, err
+	}
+	c.logger.Info("Retention policy: ", contents, " unreferenced content(s) deleted")
+
+	return n, nil
+
+}
+
 // GetChainEntries is high-level function, that run by api.GetChainEntries()
 func (c *Context) GetChainEntries(entry *model.Entry, user *model.User, start int, limit int, sort string, force bool) ([]*model.Entry, int, error) {
 
 	flagJustCreated := false
 
-	log.Debug("Search for chain into local DB")
+	c.logger.Debug("Search for chain into local DB")
 
 	// search for chain.ChainID into local DB
 	chain := entry.GetChain()
@@ -289,36 +1094,43 @@ func (c *Context) GetChainEntries(entry *model.Entry, user *model.User, start in
 
 	if localChain == nil {
 
-		log.Debug("Chain " + chain.ChainID + " not found into local DB")
-		log.Debug("Search for chain on the blockchain")
+		c.logger.Debug("Chain " + chain.ChainID + " not found into local DB")
+		c.logger.Debug("Search for chain on the blockchain")
 
-		if chain.Exists() {
+		if c.chainExists(chain) {
 			chain = chain.Base64Encode()
-			log.Debug("Chain " + chain.ChainID + " found on the blockchain")
+			c.logger.Debug("Chain " + chain.ChainID + " found on the blockchain")
 
-			log.Debug("Getting chain status from the blockchain")
-			chain.Status, chain.LatestEntryBlock = chain.GetStatusFromFactom()
+			c.logger.Debug("Getting chain status from the blockchain")
+			chain.Status, chain.LatestEntryBlock = c.chainStatus(chain)
 
-			log.Debug("Creating chain into local DB")
+			c.logger.Debug("Creating chain into local DB")
 			err := c.store.CreateChain(chain)
 			if err != nil {
-				log.Error(err)
+				c.logger.Error(err)
 			}
 
 			flagJustCreated = true
 
 		} else {
-			log.Debug("Chain " + chain.ChainID + " not found on the blockchain")
+			c.logger.Debug("Chain " + chain.ChainID + " not found on the blockchain")
 			return nil, 0, fmt.Errorf("Chain " + chain.ChainID + " not found")
 		}
 
 	}
 
 	// If we are here, so no errors occured and we force bind chain to API user
-	log.Debug("Force binding chain ", chain.ChainID, " to user ", user.Name)
+	c.logger.Debug("Force binding chain ", chain.ChainID, " to user ", user.Name)
 	err := c.store.BindChainToUser(chain, user)
 	if err != nil {
-		log.Error(err)
+		c.logger.Error(err)
+	}
+
+	// chains, which entries are requested with force=true, are synced first
+	if force {
+		c.prioritizeChain(chain, model.ChainPriorityForced)
+	} else {
+		c.prioritizeChain(chain, model.ChainPriorityAccessed)
 	}
 
 	// if force=true not passed
@@ -329,9 +1141,7 @@ func (c *Context) GetChainEntries(entry *model.Entry, user *model.User, start in
 		}
 	}
 
-	result, total := c.store.GetChainEntries(entry.GetChain(), entry, start, limit, sort)
-
-	return result, total, nil
+	return c.store.GetChainEntries(entry.GetChain(), entry, start, limit, sort)
 
 }
 
@@ -340,7 +1150,7 @@ func (c *Context) SearchChainEntries(entry *model.Entry, user *model.User, start
 
 	flagJustCreated := false
 
-	log.Debug("Search for chain into local DB")
+	c.logger.Debug("Search for chain into local DB")
 
 	chain := entry.GetChain()
 
@@ -349,36 +1159,43 @@ func (c *Context) SearchChainEntries(entry *model.Entry, user *model.User, start
 
 	if localChain == nil {
 
-		log.Debug("Chain " + chain.ChainID + " not found into local DB")
-		log.Debug("Search for chain on the blockchain")
+		c.logger.Debug("Chain " + chain.ChainID + " not found into local DB")
+		c.logger.Debug("Search for chain on the blockchain")
 
-		if chain.Exists() {
+		if c.chainExists(chain) {
 			chain = chain.Base64Encode()
-			log.Debug("Chain " + chain.ChainID + " found on the blockchain")
+			c.logger.Debug("Chain " + chain.ChainID + " found on the blockchain")
 
-			log.Debug("Getting chain status from the blockchain")
-			chain.Status, chain.LatestEntryBlock = chain.GetStatusFromFactom()
+			c.logger.Debug("Getting chain status from the blockchain")
+			chain.Status, chain.LatestEntryBlock = c.chainStatus(chain)
 
-			log.Debug("Creating chain into local DB")
+			c.logger.Debug("Creating chain into local DB")
 			err := c.store.CreateChain(chain)
 			if err != nil {
-				log.Error(err)
+				c.logger.Error(err)
 			}
 
 			flagJustCreated = true
 
 		} else {
-			log.Debug("Chain " + chain.ChainID + " not found on the blockchain")
+			c.logger.Debug("Chain " + chain.ChainID + " not found on the blockchain")
 			return nil, 0, fmt.Errorf("Chain " + chain.ChainID + " not found")
 		}
 
 	}
 
 	// If we are here, so no errors occured and we force bind chain to API user
-	log.Debug("Force binding chain ", chain.ChainID, " to user ", user.Name)
+	c.logger.Debug("Force binding chain ", chain.ChainID, " to user ", user.Name)
 	err := c.store.BindChainToUser(chain, user)
 	if err != nil {
-		log.Error(err)
+		c.logger.Error(err)
+	}
+
+	// chains, which entries are requested with force=true, are synced first
+	if force {
+		c.prioritizeChain(chain, model.ChainPriorityForced)
+	} else {
+		c.prioritizeChain(chain, model.ChainPriorityAccessed)
 	}
 
 	// if force=true not passed
@@ -389,9 +1206,7 @@ func (c *Context) SearchChainEntries(entry *model.Entry, user *model.User, start
 		}
 	}
 
-	result, total := c.store.SearchChainEntries(entry.GetChain(), entry, start, limit, sort)
-
-	return result, total, nil
+	return c.store.SearchChainEntries(entry.GetChain(), entry, start, limit, sort)
 
 }
 
@@ -400,7 +1215,7 @@ func (c *Context) GetChainFirstOrLastEntry(entry *model.Entry, sort string, user
 
 	flagJustCreated := false
 
-	log.Debug("Search for chain into local DB")
+	c.logger.Debug("Search for chain into local DB")
 
 	// search for chain.ChainID into local DB
 	chain := entry.GetChain()
@@ -408,38 +1223,40 @@ func (c *Context) GetChainFirstOrLastEntry(entry *model.Entry, sort string, user
 
 	if localChain == nil {
 
-		log.Debug("Chain " + chain.ChainID + " not found into local DB")
-		log.Debug("Search for chain on the blockchain")
+		c.logger.Debug("Chain " + chain.ChainID + " not found into local DB")
+		c.logger.Debug("Search for chain on the blockchain")
 
-		if chain.Exists() {
+		if c.chainExists(chain) {
 			chain = chain.Base64Encode()
-			log.Debug("Chain " + chain.ChainID + " found on the blockchain")
+			c.logger.Debug("Chain " + chain.ChainID + " found on the blockchain")
 
-			log.Debug("Getting chain status from the blockchain")
-			chain.Status, chain.LatestEntryBlock = chain.GetStatusFromFactom()
+			c.logger.Debug("Getting chain status from the blockchain")
+			chain.Status, chain.LatestEntryBlock = c.chainStatus(chain)
 
-			log.Debug("Creating chain into local DB")
+			c.logger.Debug("Creating chain into local DB")
 			err := c.store.CreateChain(chain)
 			if err != nil {
-				log.Error(err)
+				c.logger.Error(err)
 			}
 
 			flagJustCreated = true
 
 		} else {
-			log.Debug("Chain " + chain.ChainID + " not found on the blockchain")
+			c.logger.Debug("Chain " + chain.ChainID + " not found on the blockchain")
 			return nil, fmt.Errorf("Chain " + chain.ChainID + " not found")
 		}
 
 	}
 
 	// If we are here, so no errors occured and we force bind chain to API user
-	log.Debug("Force binding chain ", chain.ChainID, " to user ", user.Name)
+	c.logger.Debug("Force binding chain ", chain.ChainID, " to user ", user.Name)
 	err := c.store.BindChainToUser(chain, user)
 	if err != nil {
-		log.Error(err)
+		c.logger.Error(err)
 	}
 
+	c.prioritizeChain(chain, model.ChainPriorityAccessed)
+
 	// check if chain just created or not fully synced yet
 	if flagJustCreated == true {
 		return nil, nil
@@ -458,66 +1275,76 @@ func (c *Context) GetChainFirstOrLastEntry(entry *model.Entry, sort string, user
 		}
 	}
 
-	result := c.store.GetEntry(entry, sort)
-
-	return result, nil
+	return c.store.GetEntry(entry, sort)
 
 }
 
 // GetEntry is high-level function, that run by api.GetEntry()
 func (c *Context) GetEntry(entry *model.Entry, user *model.User) (*model.Entry, error) {
 
-	log.Debug("Search for entry into local DB")
+	c.logger.Debug("Search for entry into local DB")
 
 	// search for chain.ChainID into local DB
-	localentry := c.store.GetEntry(entry, "")
+	localentry, err := c.store.GetEntry(entry, "")
+	if err != nil {
+		c.logger.Error(err)
+		return nil, err
+	}
 
 	if localentry != nil {
-		log.Debug("Entry " + entry.EntryHash + " found into local DB")
+		c.logger.Debug("Entry " + entry.EntryHash + " found into local DB")
 
-		log.Debug("Force binding chain ", localentry.ChainID, " to user ", user.Name)
+		c.logger.Debug("Force binding chain ", localentry.ChainID, " to user ", user.Name)
 		err := c.store.BindChainToUser(localentry.GetChain(), user)
 		if err != nil {
-			log.Error(err)
+			c.logger.Error(err)
 		}
 		// localentry already base64 encoded
 		return localentry, nil
 	}
 
-	log.Debug("Entry " + entry.EntryHash + " not found into local DB")
-	log.Debug("Search for entry on the blockchain")
+	c.logger.Debug("Entry " + entry.EntryHash + " not found into local DB")
+	c.logger.Debug("Search for entry on the blockchain")
 
-	resp, err := entry.FillModelFromFactom()
+	var resp *model.Entry
+	err = c.factomd("entry", func() (err error) {
+		resp, err = entry.FillModelFromFactom()
+		return err
+	}, attribute.String(tracing.AttrEntryHash, entry.EntryHash))
 
 	if err == nil {
-		log.Debug("Entry " + entry.EntryHash + " found on Factom")
-		resp.Status = resp.GetStatusFromFactom()
+		c.logger.Debug("Entry " + entry.EntryHash + " found on Factom")
+		resp.Status = c.entryStatus(resp)
 
 		// search for chain.ChainID into local DB
 		localChain := c.store.GetChain(resp.GetChain())
 
 		if localChain == nil {
-			log.Debug("Chain " + resp.ChainID + " not found into local DB")
-			log.Debug("Creating chain into local DB")
+			c.logger.Debug("Chain " + resp.ChainID + " not found into local DB")
+			c.logger.Debug("Creating chain into local DB")
 
 			chain := resp.GetChain()
-			chain.Status, chain.LatestEntryBlock = chain.GetStatusFromFactom()
+			chain.Status, chain.LatestEntryBlock = c.chainStatus(chain)
 
 			// here we add existing Factom chain into local DB with factomTime = null
 			err = c.store.CreateChain(chain)
 			if err != nil {
-				log.Error(err)
+				c.logger.Error(err)
 			}
 
 		}
 
-		log.Debug("Creating entry into local DB")
+		c.logger.Debug("Creating entry into local DB")
 
 		// get entry timestamp from Factom ONLY IF ENTRY STATUS IS COMPLETED
 		if resp.Status == model.EntryCompleted {
-			factomTime, err := resp.GetTimeFromFactom()
+			var factomTime int64
+			err := c.factomd("entry-ack", func() (err error) {
+				factomTime, err = resp.GetTimeFromFactom()
+				return err
+			}, attribute.String(tracing.AttrEntryHash, resp.EntryHash))
 			if err != nil {
-				log.Error(err)
+				c.logger.Error(err)
 			} else {
 				t := time.Unix(factomTime, 0).UTC()
 				resp.FactomTime = &t
@@ -526,13 +1353,13 @@ func (c *Context) GetEntry(entry *model.Entry, user *model.User) (*model.Entry,
 
 		err = c.store.CreateEntry(resp.Base64Encode())
 		if err != nil {
-			log.Error(err)
+			c.logger.Error(err)
 		}
 
-		log.Debug("Force binding chain ", resp.ChainID, " to user ", user.Name)
+		c.logger.Debug("Force binding chain ", resp.ChainID, " to user ", user.Name)
 		err = c.store.BindChainToUser(resp.GetChain(), user)
 		if err != nil {
-			log.Error(err)
+			c.logger.Error(err)
 		}
 
 		return resp.Base64Encode(), nil
@@ -547,10 +1374,10 @@ func (c *Context) CreateEntry(entry *model.Entry, user *model.User) (*model.Entr
 
 	entry = entry.Base64Decode()
 
-	log.Debug("Checking if entry fits into 10KB")
+	c.logger.Debug("Checking if entry fits into 10KB")
 	_, err := entry.Fit10KB()
 	if err != nil {
-		log.Error(err)
+		c.logger.Error(err)
 		return nil, fmt.Errorf(err.Error())
 	}
 
@@ -559,30 +1386,34 @@ func (c *Context) CreateEntry(entry *model.Entry, user *model.User) (*model.Entr
 	localChain := c.store.GetChain(entry.GetChain())
 	if localChain == nil {
 
-		log.Debug("Chain " + entry.ChainID + " not found into local DB")
-		log.Debug("Checking if chain exists on Factom")
+		c.logger.Debug("Chain " + entry.ChainID + " not found into local DB")
+		c.logger.Debug("Checking if chain exists on Factom")
 
-		if !entry.GetChain().Exists() {
-			log.Error("Chain " + entry.ChainID + " not found on Factom")
+		if !c.chainExists(entry.GetChain()) {
+			c.logger.Error("Chain " + entry.ChainID + " not found on Factom")
 			return nil, fmt.Errorf("Chain " + entry.ChainID + " not found")
 		}
 
-		log.Debug("Creating chain into local DB")
+		c.logger.Debug("Creating chain into local DB")
 
 		chain := entry.GetChain()
-		chain.Status, chain.LatestEntryBlock = chain.GetStatusFromFactom()
+		chain.Status, chain.LatestEntryBlock = c.chainStatus(chain)
 		err = c.store.CreateChain(chain)
 		if err != nil {
-			log.Error(err)
+			c.logger.Error(err)
 		}
 
 	}
 
-	localEntry := c.store.GetEntry(&model.Entry{EntryHash: entry.EntryHash}, "")
+	localEntry, err := c.store.GetEntry(&model.Entry{EntryHash: entry.EntryHash}, "")
+	if err != nil {
+		c.logger.Error(err)
+		return nil, err
+	}
 
 	if localEntry == nil {
-		log.Debug("Entry " + entry.EntryHash + " not found into local DB")
-		log.Debug("Creating entry into local DB")
+		c.logger.Debug("Entry " + entry.EntryHash + " not found into local DB")
+		c.logger.Debug("Creating entry into local DB")
 
 		// new entry status queue, factomTime NOW()
 		entry.Status = model.EntryQueue
@@ -591,11 +1422,11 @@ func (c *Context) CreateEntry(entry *model.Entry, user *model.User) (*model.Entr
 
 		err = c.store.CreateEntry(entry.Base64Encode())
 		if err != nil {
-			log.Error(err)
+			c.logger.Error(err)
 			return nil, fmt.Errorf(err.Error())
 		}
 	} else {
-		log.Debug("Entry " + entry.EntryHash + " found into local DB")
+		c.logger.Debug("Entry " + entry.EntryHash + " found into local DB")
 		// use entry status from local db
 		entry.Status = localEntry.Status
 		entry.FactomTime = localEntry.FactomTime
@@ -603,14 +1434,14 @@ func (c *Context) CreateEntry(entry *model.Entry, user *model.User) (*model.Entr
 
 	err = c.addToQueue(entry.ConvertToQueueParams(), model.QueueActionEntry, user)
 	if err != nil {
-		log.Error(err)
+		c.logger.Error(err)
 	}
 
 	// If we are here, so no errors occured and we force bind chain to API user
-	log.Debug("Force binding chain ", entry.ChainID, " to user ", user.Name)
+	c.logger.Debug("Force binding chain ", entry.ChainID, " to user ", user.Name)
 	err = c.store.BindChainToUser(entry.GetChain(), user)
 	if err != nil {
-		log.Error(err)
+		c.logger.Error(err)
 	}
 
 	return entry.Base64Encode(), nil
@@ -619,7 +1450,7 @@ func (c *Context) CreateEntry(entry *model.Entry, user *model.User) (*model.Entr
 // addToQueue checks if task already exists into queue db and if not, then adds the task into queue db
 func (c *Context) addToQueue(params *model.QueueParams, action string, user *model.User) error {
 
-	log.Debug("Adding to queue: " + action)
+	c.logger.Debug("Adding to queue: " + action)
 
 	queue := &model.Queue{}
 	queue.Params, _ = json.Marshal(params)
@@ -629,6 +1460,7 @@ func (c *Context) addToQueue(params *model.QueueParams, action string, user *mod
 	localQueue := c.store.GetQueueItem(queue)
 
 	if localQueue == nil {
+		queue.RequestID = c.requestID
 		err := c.store.CreateQueue(queue)
 		if err != nil {
 			return err
@@ -663,7 +1495,17 @@ func (c *Context) GetQueueToClear() []*model.Queue {
 // ProcessQueue processes write task from queue: makes factomd commit+reveal request and update queue item according to response (success or error)
 func (c *Context) ProcessQueue(queue *model.Queue) error {
 
-	if c.wallet == nil {
+	// logs are traced by ID of the request, which created the queue item
+	ctx, span := tracing.Start(logging.WithRequestID(context.Background(), queue.RequestID), "queue "+queue.Action, trace.SpanKindConsumer,
+		attribute.String(tracing.AttrRequestID, queue.RequestID),
+	)
+	defer span.End()
+	c = c.withContext(ctx)
+	logger := c.logger.WithFields(log.Fields{logging.FieldQueueID: queue.ID, logging.FieldAction: queue.Action})
+
+	wallet := c.getWallet()
+	if wallet == nil {
+		metrics.QueueFailures.WithLabelValues(queue.Action, "no_wallet").Inc()
 		err := fmt.Errorf("Unable to write data on the blockchain! Es address not set in config or invalid.")
 		return err
 	}
@@ -671,20 +1513,26 @@ func (c *Context) ProcessQueue(queue *model.Queue) error {
 	params := &model.QueueParams{}
 	err := json.Unmarshal(queue.Params, &params)
 	if err != nil {
+		metrics.QueueFailures.WithLabelValues(queue.Action, "invalid_params").Inc()
 		return err
 	}
 
-	debugMessage := fmt.Sprintf("Queue processing: ID=%d, action=%s, try=%d", queue.ID, queue.Action, queue.TryCount)
+	debugMessage := fmt.Sprintf("Queue processing: try=%d", queue.TryCount)
 
 	var processingIsSuccess bool
 	var resp string
 
+	start := time.Now()
+
 	switch queue.Action {
 	case model.QueueActionChain:
-		log.Debug(debugMessage)
+		logger.Debug(debugMessage)
 		chain := &model.Chain{}
 		copier.Copy(chain, params)
-		resp, err = c.wallet.CommitRevealChain(chain.ConvertToFactomModel())
+		err = c.factomd("commit-reveal-chain", func() (err error) {
+			resp, err = wallet.CommitRevealChain(chain.ConvertToFactomModel())
+			return err
+		}, attribute.String(tracing.AttrChainID, chain.ChainID))
 		if err != nil {
 			processingIsSuccess = false
 		} else {
@@ -699,10 +1547,13 @@ func (c *Context) ProcessQueue(queue *model.Queue) error {
 			}
 		}
 	case model.QueueActionEntry:
-		log.Debug(debugMessage)
+		logger.Debug(debugMessage)
 		entry := &model.Entry{}
 		copier.Copy(entry, params)
-		resp, err = c.wallet.CommitRevealEntry(entry.ConvertToFactomModel())
+		err = c.factomd("commit-reveal-entry", func() (err error) {
+			resp, err = wallet.CommitRevealEntry(entry.ConvertToFactomModel())
+			return err
+		}, attribute.String(tracing.AttrChainID, entry.ChainID))
 		if err != nil {
 			processingIsSuccess = false
 		} else {
@@ -713,12 +1564,16 @@ func (c *Context) ProcessQueue(queue *model.Queue) error {
 			}
 		}
 	default:
+		metrics.QueueFailures.WithLabelValues(queue.Action, "unknown_action").Inc()
 		err := fmt.Errorf("Queue processing: action=%s not implemented", queue.Action)
 		return err
 	}
 
+	metrics.QueueProcessingDuration.WithLabelValues(queue.Action).Observe(time.Since(start).Seconds())
+
 	if processingIsSuccess == true {
-		log.Info("Queue processing: create " + queue.Action + " success " + resp)
+		logger.WithField(logging.FieldEntryHash, resp).Info("Queue processing: create " + queue.Action + " success")
+		metrics.QueueLatency.WithLabelValues(queue.Action).Observe(time.Since(queue.CreatedAt).Seconds())
 		queue.Result = resp
 		processedAt := time.Now()
 		queue.ProcessedAt = &processedAt
@@ -729,110 +1584,370 @@ func (c *Context) ProcessQueue(queue *model.Queue) error {
 			c.SendCallback(callback)
 		}
 	} else {
-		log.Error("Queue processing: create " + queue.Action + " FAILED")
+		logger.WithField("error", err.Error()).Error("Queue processing: create " + queue.Action + " FAILED")
+		metrics.QueueFailures.WithLabelValues(queue.Action, "factomd").Inc()
 		queue.TryCount++
 		queue.Error = err.Error()
 		nextTryAt := time.Now().Add(time.Minute)
 		queue.NextTryAt = &nextTryAt
 	}
 
-	err = c.store.UpdateQueue(queue)
+	err = c.store.UpdateQueue(queue)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+// ClearQueue gets entry status from Factom, checks if it's 'completed' and then deletes task.
+// Otherwise it runs ProcessQueue() for force processing.
+func (c *Context) ClearQueue(queue *model.Queue) error {
+
+	debugMessage := fmt.Sprintf("Queue clearing: ID=%d", queue.ID)
+	c.logger.Debug(debugMessage)
+
+	params := &model.QueueParams{}
+	err := json.Unmarshal(queue.Params, &params)
+	if err != nil {
+		return err
+	}
+
+	entry := &model.Entry{EntryHash: queue.Result}
+
+	c.logger.Debug("Queue clearing: Checking entry " + entry.EntryHash + " status")
+	entry.Status = c.entryStatus(entry)
+
+	c.logger.Debug("Queue clearing: Entry status=" + entry.Status)
+
+	if entry.Status == model.EntryCompleted {
+		c.logger.Debug("Queue clearing: Soft delete row from queue table")
+		err := c.store.DeleteQueue(queue)
+		if err != nil {
+			c.logger.Error(err)
+			return err
+		}
+	} else {
+		c.logger.Debug("Queue clearing: Force processing this task again")
+		err := c.ProcessQueue(queue)
+		if err != nil {
+			c.logger.Error(err)
+		}
+		processedAt := time.Now()
+		err = c.store.UpdateQueue(&model.Queue{ID: queue.ID, ProcessedAt: &processedAt})
+		if err != nil {
+			c.logger.Error(err)
+			return err
+		}
+	}
+
+	return nil
+
+}
+
+// RetryQueue makes failed queue item to be processed on the next iteration instead of waiting for the next try
+func (c *Context) RetryQueue(queue *model.Queue) error {
+
+	return c.store.RetryQueue(queue)
+
+}
+
+// PurgeQueue deletes processed queue items, or all items if all is set
+func (c *Context) PurgeQueue(all bool) (int, error) {
+
+	return c.store.PurgeQueue(all)
+
+}
+
+// ResyncChain resets sync state of the local chain, so the running API parses all its entries again
+func (c *Context) ResyncChain(chain *model.Chain) error {
+
+	if c.store.GetChain(chain) == nil {
+		return fmt.Errorf("Chain %s not found", chain.ChainID)
+	}
+
+	return c.store.ResetChainSync(chain)
+
+}
+
+// SetChainSyncPriority sets history sync priority of not synced chain (accessible via Admin endpoint),
+// it also resumes sync of the chain, if it was cancelled
+func (c *Context) SetChainSyncPriority(chain *model.Chain, priority int) error {
+
+	if priority < model.ChainPriorityDefault {
+		return fmt.Errorf("Priority expected to be >= %d, %d received", model.ChainPriorityDefault, priority)
+	}
+
+	if err := c.checkChainNotSynced(chain); err != nil {
+		return err
+	}
+
+	if err := c.store.SetChainSyncPriority(chain, priority); err != nil {
+		return err
+	}
+
+	if scheduler := c.getScheduler(); scheduler != nil {
+		scheduler.Prioritize(chain.ChainID, priority)
+	}
+
+	return nil
+
+}
+
+// CancelChainSync removes chain from history fetching pool & prevents it from being sent into pool again (accessible via Admin endpoint).
+// If chain is being parsed, worker stops after the current batch of entry blocks. Sync is resumed by setting priority of the chain
+func (c *Context) CancelChainSync(chain *model.Chain) error {
+
+	if err := c.checkChainNotSynced(chain); err != nil {
+		return err
+	}
+
+	if err := c.store.SetChainSyncPriority(chain, model.ChainPriorityCancelled); err != nil {
+		return err
+	}
+
+	// queued chain is returned to unsynced chains, so it's sent into pool again when sync is resumed
+	if scheduler := c.getScheduler(); scheduler != nil && scheduler.Cancel(chain.ChainID) {
+		return c.ResetChainParsing(chain)
+	}
+
+	return nil
+
+}
+
+func (c *Context) checkChainNotSynced(chain *model.Chain) error {
+
+	localChain := c.store.GetChain(&model.Chain{ChainID: chain.ChainID})
+	if localChain == nil {
+		return fmt.Errorf("Chain %s not found", chain.ChainID)
+	}
+
+	if localChain.Synced != nil && *localChain.Synced {
+		return fmt.Errorf("Chain %s is already synced", chain.ChainID)
+	}
+
+	return nil
+
+}
+
+// MigrateContent moves content of at most limit entries saved by previous versions into content store,
+// returns number of processed entries
+func (c *Context) MigrateContent(limit int) (int, error) {
+
+	return c.store.MigrateEntriesContent(limit)
+
+}
+
+// Manual delete stucked queue items (accessible via Admin endpoint)
+func (c *Context) DeleteQueue(queue *model.Queue) error {
+
+	err := c.store.DeleteQueue(queue)
+	if err != nil {
+		c.logger.Error(err)
+		return err
+	}
+
+	return nil
+
+}
+
+// ParseNewChainEntries fetches new entries of chain, that appeared on Factom inside all entry blocks after the latest parsed one
+func (c *Context) ParseNewChainEntries(chain *model.Chain) error {
+
+	ctx, span := tracing.Start(c.ctx, "parse chain updates", trace.SpanKindInternal, attribute.String(tracing.AttrChainID, chain.ChainID))
+	defer span.End()
+	c = c.withContext(ctx)
+
+	c.logger.Debug("Updates parser: Checking chain " + chain.ChainID)
+
+	status, chainhead := c.chainStatus(chain)
+
+	// if chain has not processed on Factom, don't touch it
+	if status != model.ChainCompleted {
+		return fmt.Errorf("Updates parser: Chain has not processed on Factom yet")
+	}
+
+	return c.parseChainUpdates(chain, chainhead)
+
+}
+
+// ParseDBlock reads directory block & parses new entries of local completed chains, which have entry blocks in it.
+// Unlike ParseNewChainEntries for every chain, it makes one factomd call per dblock for unchanged chains
+func (c *Context) ParseDBlock(height int) error {
+
+	ctx, span := tracing.Start(c.ctx, "parse dblock", trace.SpanKindInternal, attribute.Int(tracing.AttrDBHeight, height))
+	defer span.End()
+	c = c.withContext(ctx)
+
+	c.logger.Debug("Updates parser: Checking dblock ", height)
+
+	var dblock *factom.DBlock
+	err := c.factomd("dblock-by-height", func() (err error) {
+		dblock, _, err = factom.GetDBlockByHeight(int64(height))
+		return err
+	}, attribute.Int(tracing.AttrDBHeight, height))
+	if err != nil {
+		return err
+	}
+
+	// entry block of the chain in dblock is its chain head at this height
+	heads := make(map[string]string)
+	var chainIDs []string
+	for _, item := range dblock.DBEntries {
+		heads[item.ChainID] = item.KeyMR
+		chainIDs = append(chainIDs, item.ChainID)
+	}
+
+	chains := c.store.GetTrackedChainsByID(chainIDs, &model.Chain{Status: model.ChainCompleted})
+
+	c.logger.Debug("Updates parser: DBlock ", height, " has ", len(chainIDs), " entry block(s), ", len(chains), " of local chains")
+
+	for _, chain := range chains {
+		ctx, span := tracing.Start(c.ctx, "parse chain updates", trace.SpanKindInternal, attribute.String(tracing.AttrChainID, chain.ChainID))
+		err := c.withContext(ctx).parseChainUpdates(chain, heads[chain.ChainID])
+		tracing.End(span, err)
+		if err != nil {
+			return fmt.Errorf("Updates parser: Parsing chain %s from dblock %d failed: %s", chain.ChainID, height, err)
+		}
+	}
+
+	return nil
+
+}
+
+// IndexDBlock registers chains, which have entry blocks in directory block, as local chains.
+// Their history is parsed by history parser. Returns number of new chains
+func (c *Context) IndexDBlock(height int) (int, error) {
+
+	ctx, span := tracing.Start(c.ctx, "index dblock", trace.SpanKindInternal, attribute.Int(tracing.AttrDBHeight, height))
+	defer span.End()
+	c = c.withContext(ctx)
+
+	var dblock *factom.DBlock
+	err := c.factomd("dblock-by-height", func() (err error) {
+		dblock, _, err = factom.GetDBlockByHeight(int64(height))
+		return err
+	}, attribute.Int(tracing.AttrDBHeight, height))
+	if err != nil {
+		return 0, err
+	}
+
+	var chainIDs []string
+	for _, item := range dblock.DBEntries {
+		if !systemChains[item.ChainID] {
+			chainIDs = append(chainIDs, item.ChainID)
+		}
+	}
+
+	local := make(map[string]bool)
+	for _, chain := range c.store.GetChainsByID(chainIDs, &model.Chain{}) {
+		local[chain.ChainID] = true
+	}
+
+	t := true
+	var n int
+	for _, chainID := range chainIDs {
+		if local[chainID] {
+			continue
+		}
+		// existing chains are completed, ExtIDs & time are set when the first entry block is parsed,
+		// indexed chains are pinned, so they're tracked without users
+		if err := c.store.CreateChain(&model.Chain{ChainID: chainID, Status: model.ChainCompleted, Pinned: &t}); err != nil {
+			return n, err
+		}
+		n++
+	}
 
-	if err != nil {
-		return err
+	if n > 0 {
+		c.logger.Debug("Chains indexer: DBlock ", height, ", registered ", n, " new chain(s)")
 	}
 
-	return nil
+	return n, nil
 
 }
 
-// ClearQueue gets entry status from Factom, checks if it's 'completed' and then deletes task.
-// Otherwise it runs ProcessQueue() for force processing.
-func (c *Context) ClearQueue(queue *model.Queue) error {
+// parseChainUpdates parses entry blocks of chain from chainhead till the latest parsed one.
+// Blocks are found by walking back from the chain head till a parsed block, so blocks missed by failed runs are parsed too.
+// Blocks are parsed from the oldest one and chain.LatestEntryBlock is saved after every block, so the next run resumes where this one stopped.
+// LatestEntryBlock is only moved to blocks of later dblocks, so old dblocks replayed during catch-up don't move it backwards.
+// If chain head has moved to another branch, only blocks of the new branch are added, blocks of the orphaned branch are kept as parsed
+func (c *Context) parseChainUpdates(chain *model.Chain, chainhead string) error {
 
-	debugMessage := fmt.Sprintf("Queue clearing: ID=%d", queue.ID)
-	log.Debug(debugMessage)
+	synced := chain.Synced != nil && *chain.Synced
 
-	params := &model.QueueParams{}
-	err := json.Unmarshal(queue.Params, &params)
-	if err != nil {
-		return err
+	// history of not synced chain is parsed by history parser from chain.LatestEntryBlock
+	if !synced && chain.LatestEntryBlock == "" {
+		c.logger.Debug("Updates parser: Chain history is not parsed yet")
+		return nil
 	}
 
-	entry := &model.Entry{EntryHash: queue.Result}
-
-	log.Debug("Queue clearing: Checking entry " + entry.EntryHash + " status")
-	entry.Status = entry.GetStatusFromFactom()
+	// entry blocks to parse, from the newest one
+	var eblocks []*factom.EBlock
+	var keymrs []string
+	var known *model.EBlock
 
-	log.Debug("Queue clearing: Entry status=" + entry.Status)
+	for keymr := chainhead; keymr != factom.ZeroHash; {
 
-	if entry.Status == model.EntryCompleted {
-		log.Debug("Queue clearing: Soft delete row from queue table")
-		err := c.store.DeleteQueue(queue)
-		if err != nil {
-			log.Error(err)
-			return err
+		if local := c.store.GetEBlock(&model.EBlock{KeyMR: keymr}); local != nil && local.Parsed {
+			known = local
+			break
 		}
-	} else {
-		log.Debug("Queue clearing: Force processing this task again")
-		err := c.ProcessQueue(queue)
-		if err != nil {
-			log.Error(err)
+
+		if !synced && keymr == chain.LatestEntryBlock {
+			break
 		}
-		processedAt := time.Now()
-		err = c.store.UpdateQueue(&model.Queue{ID: queue.ID, ProcessedAt: &processedAt})
+
+		eb, err := c.getEBlock(keymr)
 		if err != nil {
-			log.Error(err)
 			return err
 		}
-	}
-
-	return nil
 
-}
-
-// Manual delete stucked queue items (accessible via Admin endpoint)
-func (c *Context) DeleteQueue(queue *model.Queue) error {
+		eblocks = append(eblocks, eb)
+		keymrs = append(keymrs, keymr)
+		keymr = eb.Header.PrevKeyMR
 
-	err := c.store.DeleteQueue(queue)
-	if err != nil {
-		log.Error(err)
-		return err
 	}
 
-	return nil
-
-}
-
-// ParseNewChainEntries fetches new entries of chain, that appeared on Factom inside all new entry blocks till chain.LatestEntryBlock
-func (c *Context) ParseNewChainEntries(chain *model.Chain) error {
-
-	var parseFrom string
-	var parseTo string
+	if len(eblocks) == 0 {
+		c.logger.Debug("Updates parser: No new entries found")
+		return nil
+	}
 
-	log.Debug("Updates parser: Checking chain " + chain.ChainID)
+	c.logger.Debug("Updates parser: Chain "+chain.ChainID+" updated, parsing ", len(eblocks), " entry block(s)")
 
-	status, chainhead := chain.GetStatusFromFactom()
+	// chain head has moved to another branch: blocks parsed after the common block are not on the chain anymore,
+	// they aren't removed and stay available by their entry hashes
+	if known != nil && chain.LatestEntryBlock != "" && chain.LatestEntryBlock != known.KeyMR {
+		if latest := c.store.GetEBlock(&model.EBlock{KeyMR: chain.LatestEntryBlock}); latest != nil && latest.BlockSequenceNumber > known.BlockSequenceNumber {
+			c.logger.WithField(logging.FieldChainID, chain.ChainID).Warn("Updates parser: Chain reorg detected, entry blocks of the new branch after ", known.KeyMR, " are added, orphaned entry blocks till ", chain.LatestEntryBlock, " are kept")
+		}
+	}
 
-	// if chain has not processed on Factom, don't touch it
-	if status != model.ChainCompleted {
-		return fmt.Errorf("Updates parser: Chain has not processed on Factom yet")
+	// checkpoint is compared with dblock height of the latest parsed block, so it must be saved locally
+	if chain.LatestEntryBlock != "" && c.store.GetEBlock(&model.EBlock{KeyMR: chain.LatestEntryBlock}) == nil {
+		eb, err := c.getEBlock(chain.LatestEntryBlock)
+		if err != nil {
+			return err
+		}
+		if err := c.store.CreateEBlock(model.NewEBlockFromFactomModel(chain.LatestEntryBlock, eb)); err != nil {
+			return err
+		}
 	}
 
-	// parse new entries if new blocks appeared
-	if chain.LatestEntryBlock != chainhead {
-		log.Debug("Updates parser: Chain " + chain.ChainID + " updated, parsing new entries")
-		parseFrom = chainhead
-		parseTo = chain.LatestEntryBlock
-		err := c.parseEntryBlocks(parseFrom, parseTo, false)
-		if err == nil {
-			err = c.store.UpdateChain(&model.Chain{ChainID: chain.ChainID, LatestEntryBlock: chainhead})
-			if err != nil {
-				return err
-			}
+	for i := len(eblocks) - 1; i >= 0; i-- {
+		if err := c.parseEntryBlock(keymrs[i], eblocks[i], false); err != nil {
+			return err
+		}
+		// checkpoint: all blocks till this one are parsed, it only moves forward, so replayed old dblocks don't move it back
+		advanced, err := c.store.AdvanceChainLatestEntryBlock(chain, model.NewEBlockFromFactomModel(keymrs[i], eblocks[i]))
+		if err != nil {
+			return err
+		}
+		if !advanced {
+			c.logger.Debug("Updates parser: Entry block ", keymrs[i], " is older than the latest parsed one, checkpoint is kept")
 		}
-	} else {
-		log.Debug("Updates parser: No new entries found")
 	}
 
 	return nil
@@ -842,45 +1957,62 @@ func (c *Context) ParseNewChainEntries(chain *model.Chain) error {
 // ParseAllChainEntries fetches all entries of chain from Factom.
 // By default the parsing starts from ChainHead.
 // If chain is partially fetched (i.e. reference field chain.EarliestEntryBlock != ""), the parsing starts from EarliestEntryBlock
-func (c *Context) ParseAllChainEntries(chain *model.Chain, workerID int) error {
+// and entry blocks parsed before are skipped.
+// At most maxBlocks entry blocks are parsed per call (0 — no limit), returns true if chain is synced completely
+func (c *Context) ParseAllChainEntries(chain *model.Chain, workerID int, maxBlocks int) (bool, error) {
 
 	var parseFrom string
 	var parseTo string
 
-	log.Debug("History parse: Checking chain " + chain.ChainID)
+	ctx, span := tracing.Start(c.ctx, "parse chain history", trace.SpanKindInternal, attribute.String(tracing.AttrChainID, chain.ChainID))
+	defer span.End()
+	c = c.withContext(ctx)
+
+	c.logger.Debug("History parse: Checking chain " + chain.ChainID)
 
-	status, chainhead := chain.GetStatusFromFactom()
+	status, chainhead := c.chainStatus(chain)
 
 	// if chain has not processed on Factom, don't touch it
 	if status != model.ChainCompleted {
-		return fmt.Errorf("History parse: Chain has not processed on Factom yet")
+		return false, fmt.Errorf("History parse: Chain has not processed on Factom yet")
 	}
 
 	t := true
 
 	if chain.Synced == &t {
-		return fmt.Errorf("History parse: Chain already parsed")
+		return false, fmt.Errorf("History parse: Chain already parsed")
 	}
 
 	// by default, parse from chainhead
 	parseFrom = chainhead
 
-	log.Debug("History parse: Chain " + chain.ChainID + " not synced, parsing all entries")
+	c.logger.Debug("History parse: Chain " + chain.ChainID + " not synced, parsing all entries")
 	parseTo = factom.ZeroHash
 
 	// if some entryblocks already parsed, start from the latest parsed
 	if chain.EarliestEntryBlock != "" {
-		log.Debug("History parse: Start parsing from EntryBlock " + chain.EarliestEntryBlock)
+		c.logger.Debug("History parse: Start parsing from EntryBlock " + chain.EarliestEntryBlock)
 		parseFrom = chain.EarliestEntryBlock
 	}
 
 	// set chain LatestEntryBlock & assign worker ID
-	c.store.UpdateChain(&model.Chain{ChainID: chain.ChainID, LatestEntryBlock: chainhead, WorkerID: workerID})
+	// if history parsing is resumed, blocks after LatestEntryBlock are parsed by updates parser, so it's not changed
+	update := &model.Chain{ChainID: chain.ChainID, WorkerID: workerID}
+	if chain.EarliestEntryBlock == "" {
+		update.LatestEntryBlock = chainhead
+	}
+	c.store.UpdateChain(update)
 
 	// parsing chain entryblocks & entries recursively
-	err := c.parseEntryBlocks(parseFrom, parseTo, true)
+	done, err := c.parseEntryBlocks(parseFrom, parseTo, true, maxBlocks)
 	if err != nil {
-		return err
+		return false, err
+	}
+
+	// batch of entryblocks parsed, parsing is resumed from chain.EarliestEntryBlock later
+	if !done {
+		c.logger.Debug("History parse: Chain ", chain.ChainID, ", batch of ", maxBlocks, " EntryBlocks parsed")
+		return false, nil
 	}
 
 	// if no errors (chain synced completely), then update chain status to completed
@@ -891,75 +2023,119 @@ func (c *Context) ParseAllChainEntries(chain *model.Chain, workerID int) error {
 	// (except the case when API / factomd was offline for a long time, but there is no issue)
 	c.store.UpdateChain(&model.Chain{ChainID: chain.ChainID, Status: status})
 
-	return nil
+	return true, nil
 
 }
 
-// Parses entries from all entryblocks between parseFrom & parseTo.
-// updateEarliestEntryBlock is a flag, that reflect should chain.EarliestEntryBlock be updated during the parsing.
-// While history fetching (from X block till the first block), chain.EarliestEntryBlock is being updated.
-// While updates fetching (from ChainHead till latest parsed block), chain.EarliestEntryBlock is NOT being updated.
-func (c *Context) parseEntryBlocks(parseFrom string, parseTo string, updateEarliestEntryBlock bool) error {
+// Parses entries from all entryblocks between parseFrom & parseTo while history fetching (from X block till the first block),
+// chain.EarliestEntryBlock is being updated. Already parsed blocks are skipped, except the first block of the chain,
+// which completes chain sync. Parsing stops after maxBlocks entryblocks (if > 0), returns true if parseTo is reached
+func (c *Context) parseEntryBlocks(parseFrom string, parseTo string, updateEarliestEntryBlock bool, maxBlocks int) (bool, error) {
+
+	parsed := 0
 
 	for ebhash := parseFrom; ebhash != parseTo; {
-		var err error
-		ebhash, err = c.parseEntryBlock(ebhash, updateEarliestEntryBlock)
+
+		if maxBlocks > 0 && parsed >= maxBlocks {
+			return false, nil
+		}
+
+		if local := c.store.GetEBlock(&model.EBlock{KeyMR: ebhash}); local != nil && local.Parsed && local.PrevKeyMR != factom.ZeroHash {
+			c.logger.Debug("EntryBlock " + ebhash + " already parsed")
+			ebhash = local.PrevKeyMR
+			continue
+		}
+
+		eb, err := c.getEBlock(ebhash)
 		if err != nil {
-			return err
+			return false, err
 		}
+
+		if err := c.parseEntryBlock(ebhash, eb, updateEarliestEntryBlock); err != nil {
+			return false, err
+		}
+
+		parsed++
+		ebhash = eb.Header.PrevKeyMR
+
 	}
 
-	return nil
+	return true, nil
 
 }
 
-// Parses all entries from the entryblock and returns keymr of previous entryblock
-func (c *Context) parseEntryBlock(ebhash string, updateEarliestEntryBlock bool) (string, error) {
+// Fetches entry block from Factom
+func (c *Context) getEBlock(ebhash string) (*factom.EBlock, error) {
 
-	log.Debug("Fetching EntryBlock " + ebhash)
+	c.logger.Debug("Fetching EntryBlock " + ebhash)
 
-	eb, err := factom.GetEBlock(ebhash)
-	if err != nil {
-		return "", err
+	var eb *factom.EBlock
+	err := c.factomd("entry-block", func() (err error) {
+		eb, err = factom.GetEBlock(ebhash)
+		return err
+	}, attribute.String(tracing.AttrKeyMR, ebhash))
+
+	return eb, err
+
+}
+
+// Parses all entries from the entryblock and marks it as parsed, if all entries are saved
+func (c *Context) parseEntryBlock(ebhash string, eb *factom.EBlock, updateEarliestEntryBlock bool) error {
+
+	ctx, span := tracing.Start(c.ctx, "parse entry-block", trace.SpanKindInternal, attribute.String(tracing.AttrKeyMR, ebhash))
+	defer span.End()
+	c = c.withContext(ctx)
+
+	mode := "updates"
+	if updateEarliestEntryBlock {
+		mode = "history"
 	}
+
 	entryblock := model.NewEBlockFromFactomModel(ebhash, eb)
-	err = c.store.CreateEBlock(entryblock)
+	err := c.store.CreateEBlock(entryblock)
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	var entry *model.Entry
 	var fistEntryOfEntryBlock *model.Entry
 
 	for i, listItem := range eb.EntryList {
-		fe, err := factom.GetEntry(listItem.EntryHash)
+		var fe *factom.Entry
+		err := c.factomd("entry", func() (err error) {
+			fe, err = factom.GetEntry(listItem.EntryHash)
+			return err
+		}, attribute.String(tracing.AttrEntryHash, listItem.EntryHash))
 		if err != nil {
-			return "", err
+			return err
 		}
 		entry = model.NewEntryFromFactomModel(fe)
-		log.Debug("Fetching Entry " + entry.EntryHash)
+		c.logger.Debug("Fetching Entry " + entry.EntryHash)
 		entry.Status = model.EntryCompleted
 		t := time.Unix(listItem.Timestamp, 0).UTC()
 		entry.FactomTime = &t
 		err = c.store.CreateEntry(entry.Base64Encode())
 		if err != nil {
-			log.Error(err)
-			return "", err
+			c.logger.Error(err)
+			return err
 		}
 		err = c.store.BindEntryToEBlock(entry, entryblock)
 		if err != nil {
-			log.Error(err)
-			return "", err
+			c.logger.Error(err)
+			return err
 		}
 		if i == 0 {
 			fistEntryOfEntryBlock = entry
 		}
+		metrics.ParserEntries.WithLabelValues(mode).Inc()
 	}
 
+	metrics.ParserEntryBlocks.WithLabelValues(mode).Inc()
+
 	if updateEarliestEntryBlock == true {
 		err = c.store.UpdateChain(&model.Chain{ChainID: eb.Header.ChainID, EarliestEntryBlock: ebhash})
 		if err != nil {
-			return "", err
+			return err
 		}
 	}
 
@@ -970,11 +2146,11 @@ func (c *Context) parseEntryBlock(ebhash string, updateEarliestEntryBlock bool)
 		// s[0] — first entry of the entry block
 		err = c.store.UpdateChain(&model.Chain{ChainID: eb.Header.ChainID, Synced: &t, ExtIDs: fistEntryOfEntryBlock.Base64Encode().ExtIDs, FactomTime: &factomTime, WorkerID: -2})
 		if err != nil {
-			return "", err
+			return err
 		}
 	}
 
-	return eb.Header.PrevKeyMR, nil
+	return c.store.SetEBlockParsed(entryblock)
 
 }
 
@@ -995,7 +2171,7 @@ func (c *Context) GetCallbacks(callback *model.Callback) []*model.Callback {
 // createCallback checks if callback already exists into callbacks db and if not, then adds the callback into callbacks db
 func (c *Context) CreateCallback(entryHash string, url string, user *model.User) error {
 
-	log.Debug("Creating callback for: " + entryHash)
+	c.logger.Debug("Creating callback for: " + entryHash)
 
 	callback := &model.Callback{}
 	callback.EntryHash = entryHash
@@ -1018,9 +2194,12 @@ func (c *Context) CreateCallback(entryHash string, url string, user *model.User)
 // sendCallback sends JSONP request to callback.URL
 func (c *Context) SendCallback(callback *model.Callback) error {
 
-	log.Debug("Sending callback for: " + callback.EntryHash)
+	c.logger.Debug("Sending callback for: " + callback.EntryHash)
 
-	entry := c.store.GetEntry(&model.Entry{EntryHash: callback.EntryHash}, "")
+	entry, err := c.store.GetEntry(&model.Entry{EntryHash: callback.EntryHash}, "")
+	if err != nil {
+		return err
+	}
 
 	b, err := json.Marshal(entry)
 	if err != nil {
@@ -1033,11 +2212,18 @@ func (c *Context) SendCallback(callback *model.Callback) error {
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Error(err)
+		c.logger.Error(err)
 		// 408 – HTTP code for Time Out — update callbacks DB with this field
 		callback.Result = 408
+		metrics.Callbacks.WithLabelValues("failed").Inc()
 	} else {
+		resp.Body.Close()
 		callback.Result = resp.StatusCode
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			metrics.Callbacks.WithLabelValues("delivered").Inc()
+		} else {
+			metrics.Callbacks.WithLabelValues("rejected").Inc()
+		}
 	}
 
 	c.store.UpdateCallback(callback)
@@ -1050,3 +2236,315 @@ func (c *Context) SendCallback(callback *model.Callback) error {
 	return nil
 
 }
+
+// Audit appends entry to audit log. Failed audit doesn't fail the audited action, but is logged
+func (c *Context) Audit(entry *model.AuditLog) {
+
+	if err := c.store.CreateAuditLog(entry); err != nil {
+		c.logger.WithField("action", entry.Action).WithField("actor", entry.ActorName).Error(err)
+	}
+
+}
+
+// GetAuditLogs returns audit log entries matching the filter
+func (c *Context) GetAuditLogs(entry *model.AuditLog, from *time.Time, to *time.Time, start int, limit int, sort string) ([]*model.AuditLog, int) {
+
+	return c.store.GetAuditLogs(entry, from, to, start, limit, sort)
+
+}
+
+// UpdateMetrics refreshes gauges, which are too expensive to calculate on every scrape:
+// queue depth, chains sync state & EC balance
+func (c *Context) UpdateMetrics() error {
+
+	queue, err := c.store.CountQueueByState()
+	if err != nil {
+		return err
+	}
+	for _, state := range []string{"pending", "retrying", "processed"} {
+		metrics.QueueItems.WithLabelValues(state).Set(float64(queue[state]))
+	}
+
+	chains, err := c.store.CountChainsBySync()
+	if err != nil {
+		return err
+	}
+	for _, state := range []string{"synced", "unsynced"} {
+		metrics.ParserChains.WithLabelValues(state).Set(float64(chains[state]))
+	}
+
+	// EC address may be changed by config reload, so previous address is removed
+	metrics.ECBalance.Reset()
+	if wallet := c.getWallet(); wallet != nil {
+		address := wallet.GetEC().PubString()
+		var balance int64
+		err := c.factomd("entry-credit-balance", func() (err error) {
+			balance, err = factom.GetECBalance(address)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		metrics.ECBalance.WithLabelValues(address).Set(float64(balance))
+	}
+
+	return nil
+
+}
+
+// GetUpdatesHeight returns dblock height, which new entries were parsed till by updates parser before restart
+func (c *Context) GetUpdatesHeight() int {
+
+	state := c.store.GetSyncState(model.SyncStateUpdates)
+	if state == nil {
+		return 0
+	}
+
+	atomic.StoreInt64(&c.state.updatesHeight, state.Height)
+
+	return int(state.Height)
+
+}
+
+// SetUpdatesHeight saves dblock height, which new entries were parsed till by updates parser
+func (c *Context) SetUpdatesHeight(height int) error {
+
+	atomic.StoreInt64(&c.state.updatesHeight, int64(height))
+
+	return c.store.SaveSyncState(&model.SyncState{Name: model.SyncStateUpdates, Height: int64(height)})
+
+}
+
+// GetIndexHeight returns the latest dblock height, which chains were registered from by chains indexer, -1 if indexing is not started
+func (c *Context) GetIndexHeight() int {
+
+	state := c.store.GetSyncState(model.SyncStateIndex)
+	if state == nil {
+		return -1
+	}
+
+	return int(state.Height)
+
+}
+
+// SetIndexHeight saves dblock height, which chains were registered from by chains indexer
+func (c *Context) SetIndexHeight(height int) error {
+
+	return c.store.SaveSyncState(&model.SyncState{Name: model.SyncStateIndex, Height: int64(height)})
+
+}
+
+// GetSyncProgress reports heights of factomd, updates parser & chains indexer and number of synced chains
+func (c *Context) GetSyncProgress() *model.SyncProgress {
+
+	progress := &model.SyncProgress{
+		UpdatesHeight: atomic.LoadInt64(&c.state.updatesHeight),
+		IndexHeight:   int64(c.GetIndexHeight()),
+	}
+
+	var heights *factom.HeightsResponse
+	err := c.factomd("heights", func() (err error) {
+		heights, err = factom.GetHeights()
+		return err
+	})
+	if err != nil {
+		c.logger.Error(err)
+	} else {
+		progress.FactomdHeight = heights.DirectoryBlockHeight
+	}
+
+	chains, err := c.store.CountChainsBySync()
+	if err != nil {
+		c.logger.Error(err)
+	}
+	progress.Chains = make(map[string]int)
+	for _, state := range []string{"synced", "unsynced"} {
+		progress.Chains[state] = chains[state]
+	}
+
+	return progress
+
+}
+
+// CheckReadiness checks DB, factomd, wallet, queue & updates parser.
+// Failed DB or factomd make API not ready, other problems only degrade it
+func (c *Context) CheckReadiness() *model.Readiness {
+
+	var db, factomd, wallet, queue *model.HealthCheck
+	var heights *factom.HeightsResponse
+
+	// checks are independent and may wait for timeouts, so they run concurrently
+	var wg sync.WaitGroup
+	wg.Add(4)
+	go func() { defer wg.Done(); db = c.checkDB() }()
+	go func() { defer wg.Done(); factomd, heights = c.checkFactomd() }()
+	go func() { defer wg.Done(); wallet = c.checkWallet() }()
+	go func() { defer wg.Done(); queue = c.checkQueue() }()
+	wg.Wait()
+
+	r := &model.Readiness{}
+	r.AddCheck(model.HealthCheckDB, db)
+	r.AddCheck(model.HealthCheckFactomd, factomd)
+	r.AddCheck(model.HealthCheckWallet, wallet)
+	r.AddCheck(model.HealthCheckQueue, queue)
+	r.AddCheck(model.HealthCheckParser, c.checkParser(heights))
+
+	return r
+
+}
+
+func (c *Context) checkDB() *model.HealthCheck {
+
+	if err := c.store.Ping(); err != nil {
+		return model.NewHealthCheck(model.HealthFailed, err.Error(), nil)
+	}
+	return model.NewHealthCheck(model.HealthOK, "", nil)
+
+}
+
+func (c *Context) checkFactomd() (*model.HealthCheck, *factom.HeightsResponse) {
+
+	var heights *factom.HeightsResponse
+	err := c.factomd("heights", func() (err error) {
+		heights, err = factom.GetHeights()
+		return err
+	})
+	if err != nil {
+		return model.NewHealthCheck(model.HealthFailed, err.Error(), nil), nil
+	}
+
+	gap := heights.EntryBlockHeight - heights.EntryHeight
+	details := map[string]interface{}{
+		"directoryBlockHeight": heights.DirectoryBlockHeight,
+		"leaderHeight":         heights.LeaderHeight,
+		"entryBlockHeight":     heights.EntryBlockHeight,
+		"entryHeight":          heights.EntryHeight,
+		"syncGap":              gap,
+	}
+
+	if gap > ReadyMaxSyncGap {
+		return model.NewHealthCheck(model.HealthDegraded, "Factomd node is not fully synced", details), heights
+	}
+
+	return model.NewHealthCheck(model.HealthOK, "", details), heights
+
+}
+
+func (c *Context) checkWallet() *model.HealthCheck {
+
+	wallet := c.getWallet()
+	if wallet == nil {
+		return model.NewHealthCheck(model.HealthDegraded, "Es address not set in config or invalid, writes are disabled", nil)
+	}
+
+	address := wallet.GetEC().PubString()
+	var balance int64
+	err := c.factomd("entry-credit-balance", func() (err error) {
+		balance, err = factom.GetECBalance(address)
+		return err
+	})
+	if err != nil {
+		return model.NewHealthCheck(model.HealthDegraded, err.Error(), map[string]interface{}{"ecAddress": address})
+	}
+
+	details := map[string]interface{}{"ecAddress": address, "balance": balance}
+
+	if balance == 0 {
+		return model.NewHealthCheck(model.HealthDegraded, "EC address balance is 0 EC, writes will fail", details)
+	}
+
+	return model.NewHealthCheck(model.HealthOK, "", details)
+
+}
+
+func (c *Context) checkQueue() *model.HealthCheck {
+
+	count, oldest, err := c.store.GetQueueBacklog()
+	if err != nil {
+		return model.NewHealthCheck(model.HealthDegraded, err.Error(), nil)
+	}
+
+	details := map[string]interface{}{"backlog": count, "oldestAgeSeconds": 0}
+
+	if oldest != nil {
+		age := time.Since(*oldest)
+		details["oldestAgeSeconds"] = int(age.Seconds())
+		if age > ReadyMaxQueueAge {
+			return model.NewHealthCheck(model.HealthDegraded, "Queue items are not processed for more than "+ReadyMaxQueueAge.String(), details)
+		}
+	}
+
+	return model.NewHealthCheck(model.HealthOK, "", details)
+
+}
+
+func (c *Context) checkParser(heights *factom.HeightsResponse) *model.HealthCheck {
+
+	parsed := atomic.LoadInt64(&c.state.updatesHeight)
+	details := map[string]interface{}{"parsedHeight": parsed}
+
+	if parsed == 0 {
+		return model.NewHealthCheck(model.HealthDegraded, "Updates parser has not completed the first iteration yet", details)
+	}
+
+	// factomd check has already failed
+	if heights == nil {
+		return model.NewHealthCheck(model.HealthDegraded, "Parser lag is unknown, factomd is not available", details)
+	}
+
+	lag := heights.DirectoryBlockHeight - parsed
+	details["lag"] = lag
+
+	if lag > ReadyMaxParserLag {
+		return model.NewHealthCheck(model.HealthDegraded, "Updates parser is behind factomd", details)
+	}
+
+	return model.NewHealthCheck(model.HealthOK, "", details)
+
+}
+
+// factomd traces factomd call made by fn as child span of the service context
+func (c *Context) factomd(method string, fn func() error, attrs ...attribute.KeyValue) error {
+
+	_, span := tracing.StartFactomd(c.ctx, method, attrs...)
+	err := fn()
+	tracing.End(span, err)
+
+	return err
+
+}
+
+func (c *Context) chainExists(chain *model.Chain) bool {
+
+	var exists bool
+	c.factomd("chain-head", func() error {
+		exists = chain.Exists()
+		return nil
+	}, attribute.String(tracing.AttrChainID, chain.ChainID))
+
+	return exists
+
+}
+
+func (c *Context) chainStatus(chain *model.Chain) (status string, chainhead string) {
+
+	c.factomd("chain-head", func() error {
+		status, chainhead = chain.GetStatusFromFactom()
+		return nil
+	}, attribute.String(tracing.AttrChainID, chain.ChainID))
+
+	return status, chainhead
+
+}
+
+func (c *Context) entryStatus(entry *model.Entry) string {
+
+	var status string
+	c.factomd("entry-ack", func() error {
+		status = entry.GetStatusFromFactom()
+		return nil
+	}, attribute.String(tracing.AttrEntryHash, entry.EntryHash))
+
+	return status
+
+}
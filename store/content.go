@@ -0,0 +1,124 @@
+package store
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/DeFacto-Team/Factom-Open-API/model"
+	"github.com/jinzhu/gorm"
+)
+
+// putContent saves base64 content of the entry into content store & replaces it with content hash.
+// Content, which is not valid base64, is kept in entries table
+func (c *Context) putContent(entry *model.Entry) error {
+
+	if entry.Content == "" {
+		return nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(entry.Content)
+	if err != nil {
+		return nil
+	}
+
+	hash, err := c.content.Put(data)
+	if err != nil {
+		return err
+	}
+
+	entry.ContentHash = hash
+	entry.Content = ""
+
+	return nil
+
+}
+
+// resolveContent loads base64 content of entries from content store by one request.
+// Content missing in content store is an error, so entries are never returned without their content
+func (c *Context) resolveContent(entries ...*model.Entry) error {
+
+	var hashes []string
+	for _, entry := range entries {
+		if entry.Content == "" && entry.ContentHash != "" {
+			hashes = append(hashes, entry.ContentHash)
+		}
+	}
+
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	contents, err := c.content.Get(hashes)
+	if err != nil {
+		return fmt.Errorf("Store: Loading entries content failed: %v", err)
+	}
+
+	for _, entry := range entries {
+		if entry.Content != "" || entry.ContentHash == "" {
+			continue
+		}
+		data, ok := contents[entry.ContentHash]
+		if !ok {
+			return fmt.Errorf("Store: Content %s of entry %s not found", entry.ContentHash, entry.EntryHash)
+		}
+		entry.Content = base64.StdEncoding.EncodeToString(data)
+	}
+
+	return nil
+
+}
+
+// MigrateEntriesContent moves content of at most limit entries saved by previous versions into content store,
+// returns number of processed entries
+func (c *Context) MigrateEntriesContent(limit int) (int, error) {
+
+	entries := []*model.Entry{}
+	if err := c.db.Select("entry_hash, content").Where("content_hash IS NULL AND content <> ''").Limit(limit).Find(&entries).Error; err != nil {
+		return 0, err
+	}
+
+	for _, entry := range entries {
+
+		if err := c.putContent(entry); err != nil {
+			return 0, err
+		}
+
+		// content, which is not valid base64, stays in entries table & is marked with empty hash, so it's not selected again
+		update := map[string]interface{}{"content_hash": entry.ContentHash}
+		if entry.ContentHash != "" {
+			update["content"] = gorm.Expr("NULL")
+		}
+
+		// hooks & updated_at are skipped, entry itself is not changed
+		if err := c.db.Model(&model.Entry{}).Where("entry_hash = ?", entry.EntryHash).UpdateColumns(update).Error; err != nil {
+			return 0, err
+		}
+
+	}
+
+	return len(entries), nil
+
+}
+
+// PurgeUnreferencedContent deletes content saved into content store before the time,
+// which is not referenced by entries anymore. Returns number of deleted contents
+func (c *Context) PurgeUnreferencedContent(before time.Time) (int, error) {
+
+	return c.content.Sweep(before, func(hashes []string) (map[string]bool, error) {
+
+		var used []string
+		if err := c.db.Unscoped().Model(&model.Entry{}).Where("content_hash IN (?)", hashes).Pluck("DISTINCT content_hash", &used).Error; err != nil {
+			return nil, err
+		}
+
+		res := make(map[string]bool)
+		for _, hash := range used {
+			res[hash] = true
+		}
+
+		return res, nil
+
+	})
+
+}
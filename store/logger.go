@@ -0,0 +1,38 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DeFacto-Team/Factom-Open-API/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// gormLogger writes gorm logs via logrus, so they have the same format & fields as other logs.
+// Values of SQL params are not logged, as they may contain secrets
+type gormLogger struct {
+	entry *log.Entry
+}
+
+func (l gormLogger) Print(v ...interface{}) {
+
+	entry := l.entry.WithField(logging.FieldComponent, "gorm")
+
+	if len(v) == 6 && v[0] == "sql" {
+		duration, _ := v[2].(time.Duration)
+		entry.WithFields(log.Fields{
+			"file":               v[1],
+			logging.FieldLatency: float64(duration) / float64(time.Millisecond),
+			"rows":               v[5],
+		}).Debug(v[3])
+		return
+	}
+
+	if len(v) > 2 && v[0] == "log" {
+		entry.WithField("file", v[1]).Info(fmt.Sprint(v[2:]...))
+		return
+	}
+
+	entry.Error(fmt.Sprint(v...))
+
+}
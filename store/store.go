@@ -1,9 +1,13 @@
 package store
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/DeFacto-Team/Factom-Open-API/config"
+	"github.com/DeFacto-Team/Factom-Open-API/content"
+	"github.com/DeFacto-Team/Factom-Open-API/logging"
 	"github.com/DeFacto-Team/Factom-Open-API/model"
 
 	"github.com/jinzhu/gorm"
@@ -12,38 +16,103 @@ import (
 )
 
 type Store interface {
+	WithContext(ctx context.Context) Store
 	Ping() error
 	Close() error
+	Migrate(up bool, max int) (int, error)
+	GetMigrations() ([]*Migration, error)
 
 	CreateUser(user *model.User) (*model.User, error)
 	GetUser(user *model.User) *model.User
 	GetUsers(user *model.User) []*model.User
 	UpdateUser(user *model.User) error
 	DeleteUser(user *model.User) error
+	ResetUserUsage(user *model.User, now time.Time) (*model.User, error)
+	GetUsageHistory(history *model.UsageHistory) []*model.UsageHistory
+
+	CreateAdmin(admin *model.Admin) (*model.Admin, error)
+	GetAdmin(admin *model.Admin) *model.Admin
+	GetEnabledAdmin(username string) *model.Admin
+	GetAdmins(admin *model.Admin) []*model.Admin
+	UpdateAdmin(admin *model.Admin) error
+	DeleteAdmin(admin *model.Admin) error
+	UpdateAdminTOTP(admin *model.Admin) error
+	UseAdminTOTPStep(admin *model.Admin, step int64) bool
+	ReplaceAdminRecoveryCodes(admin *model.Admin, codes []*model.AdminRecoveryCode) error
+	UseAdminRecoveryCode(admin *model.Admin, codeHash string, now time.Time) bool
+
+	CreateJWTKey(key *model.JWTKey) (*model.JWTKey, error)
+	GetJWTKey(key *model.JWTKey) *model.JWTKey
+	GetJWTKeys(key *model.JWTKey) []*model.JWTKey
+	RetireJWTKeys(except *model.JWTKey, now time.Time) error
+	DeleteJWTKeysRetiredBefore(t time.Time) error
+
+	CreateAdminSession(session *model.AdminSession) (*model.AdminSession, error)
+	GetAdminSession(session *model.AdminSession) *model.AdminSession
+	GetActiveAdminSessions(session *model.AdminSession, now time.Time) []*model.AdminSession
+	UpdateAdminSession(session *model.AdminSession) error
+	RevokeAdminSessions(session *model.AdminSession, now time.Time) error
+	DeleteAdminSessionsExpiredBefore(t time.Time) error
+
+	CreateAPIKey(key *model.APIKey) (*model.APIKey, error)
+	GetAPIKey(key *model.APIKey) *model.APIKey
+	GetUserAPIKey(id int, userID int) *model.APIKey
+	GetAPIKeys(key *model.APIKey) []*model.APIKey
+	UpdateAPIKey(key *model.APIKey) error
+	DeleteAPIKey(key *model.APIKey) error
 
 	GetChain(chain *model.Chain) *model.Chain
 	GetChains(chain *model.Chain) []*model.Chain
+	GetEntryChain(entry *model.Entry) *model.Chain
+	GetChainsByID(chainIDs []string, chain *model.Chain) []*model.Chain
+	GetTrackedChains(chain *model.Chain) []*model.Chain
+	GetTrackedChainsByID(chainIDs []string, chain *model.Chain) []*model.Chain
+	GetChainsToSync(limit int) []*model.Chain
+	GetChainsToPurge(before time.Time, limit int) []*model.Chain
 	GetUserChains(chain *model.Chain, user *model.User, start int, limit int, sort string) ([]*model.Chain, int)
 	SearchUserChains(chain *model.Chain, user *model.User, start int, limit int, sort string) ([]*model.Chain, int)
-	GetChainEntries(chain *model.Chain, entry *model.Entry, start int, limit int, sort string) ([]*model.Entry, int)
-	SearchChainEntries(chain *model.Chain, entry *model.Entry, start int, limit int, sort string) ([]*model.Entry, int)
+	SearchChains(chain *model.Chain, user *model.User, start int, limit int, sort string) ([]*model.Chain, int)
+	GetChainEntries(chain *model.Chain, entry *model.Entry, start int, limit int, sort string) ([]*model.Entry, int, error)
+	SearchChainEntries(chain *model.Chain, entry *model.Entry, start int, limit int, sort string) ([]*model.Entry, int, error)
 	CreateChain(chain *model.Chain) error
 	UpdateChain(chain *model.Chain) error
+	AdvanceChainLatestEntryBlock(chain *model.Chain, eblock *model.EBlock) (bool, error)
 	UpdateChainsWhere(sql string, chain *model.Chain) error
+	ResetChainSync(chain *model.Chain) error
+	SetChainSyncPriority(chain *model.Chain, priority int) error
+	RaiseChainSyncPriority(chain *model.Chain, priority int) (bool, error)
+	CountChainsBySync() (map[string]int, error)
 	BindChainToUser(chain *model.Chain, user *model.User) error
+	UnbindChainFromUser(chain *model.Chain, user *model.User) error
+	SetChainPinned(chain *model.Chain, pinned bool) error
+	PurgeChainEntries(chain *model.Chain) (int, error)
+	PurgeUnreferencedContent(before time.Time) (int, error)
 
-	GetEntry(entry *model.Entry, sort string) *model.Entry
+	GetEntry(entry *model.Entry, sort string) (*model.Entry, error)
 	CreateEntry(entry *model.Entry) error
 	UpdateEntry(entry *model.Entry) error
+	MigrateEntriesContent(limit int) (int, error)
+	GetEBlock(eblock *model.EBlock) *model.EBlock
 	CreateEBlock(eblock *model.EBlock) error
+	SetEBlockParsed(eblock *model.EBlock) error
 	BindEntryToEBlock(entry *model.Entry, eblock *model.EBlock) error
 
+	GetSyncState(name string) *model.SyncState
+	SaveSyncState(state *model.SyncState) error
+
 	GetQueue(queue *model.Queue) []*model.Queue
 	GetQueueWhere(sql string) []*model.Queue
 	GetQueueItem(queue *model.Queue) *model.Queue
 	CreateQueue(queue *model.Queue) error
 	UpdateQueue(queue *model.Queue) error
 	DeleteQueue(queue *model.Queue) error
+	RetryQueue(queue *model.Queue) error
+	PurgeQueue(all bool) (int, error)
+	CountQueueByState() (map[string]int, error)
+	GetQueueBacklog() (int, *time.Time, error)
+
+	CreateAuditLog(entry *model.AuditLog) error
+	GetAuditLogs(entry *model.AuditLog, from *time.Time, to *time.Time, start int, limit int, sort string) ([]*model.AuditLog, int)
 
 	GetCallback(callback *model.Callback) *model.Callback
 	GetCallbacks(callback *model.Callback) []*model.Callback
@@ -55,6 +124,22 @@ type Store interface {
 // Контекст стореджа
 type Context struct {
 	db *gorm.DB
+	// content of entries
+	content content.Store
+}
+
+// SQL migrations applied at API start
+var migrations = &migrate.FileMigrationSource{
+	Dir: "migrations",
+}
+
+// SQL condition of tracked chains, which are synced & updated: pinned or bound to users
+const trackedChainSQL = "(chains.pinned IS TRUE OR EXISTS (SELECT 1 FROM users_chains WHERE users_chains.chain_chain_id = chains.chain_id))"
+
+// Migration is SQL migration, AppliedAt is nil if migration is not applied
+type Migration struct {
+	ID        string
+	AppliedAt *time.Time
 }
 
 // Create new store
@@ -69,25 +154,86 @@ func NewStore(conf *config.Config, applyMigration bool) (Store, error) {
 		return nil, err
 	}
 
+	db.SetLogger(gormLogger{log.NewEntry(log.StandardLogger())})
+	registerTracing(db)
 	if conf.API.Logging && conf.API.LogLevel >= 6 {
 		db.LogMode(true)
 	}
 
+	contentStore, err := content.New(conf.Store.ContentBackend, conf.Store.ContentPath, db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	store := &Context{db: db, content: contentStore}
+
 	if applyMigration == true {
 		log.Info("Store: applying SQL migrations")
 
-		migrations := &migrate.FileMigrationSource{
-			Dir: "migrations",
-		}
-
-		n, err := migrate.Exec(db.DB(), "postgres", migrations, migrate.Up)
+		n, err := store.Migrate(true, 0)
 		if err != nil {
-			log.Fatal(err)
+			db.Close()
+			return nil, err
 		}
 		log.Info("Store: applied ", n, " migration(s)")
 	}
 
-	return &Context{db}, nil
+	return store, nil
+
+}
+
+// WithContext returns store, which logs carry request ID from ctx & queries are traced as children of span from ctx
+func (c *Context) WithContext(ctx context.Context) Store {
+
+	db := c.db.New()
+	db.SetLogger(gormLogger{logging.FromContext(ctx)})
+	db.InstantSet(tracingContextKey, ctx)
+
+	return &Context{db: db, content: c.content}
+
+}
+
+// Migrate applies (up) or rolls back (down) at most max SQL migrations, 0 — all of them
+func (c *Context) Migrate(up bool, max int) (int, error) {
+
+	direction := migrate.Down
+	if up {
+		direction = migrate.Up
+	}
+
+	return migrate.ExecMax(c.db.DB(), "postgres", migrations, direction, max)
+
+}
+
+// GetMigrations returns all known SQL migrations with time they were applied at
+func (c *Context) GetMigrations() ([]*Migration, error) {
+
+	known, err := migrations.FindMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := migrate.GetMigrationRecords(c.db.DB(), "postgres")
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]time.Time)
+	for _, r := range records {
+		applied[r.Id] = r.AppliedAt
+	}
+
+	var res []*Migration
+	for _, m := range known {
+		item := &Migration{ID: m.Id}
+		if t, ok := applied[m.Id]; ok {
+			item.AppliedAt = &t
+		}
+		res = append(res, item)
+	}
+
+	return res, nil
 
 }
 
@@ -147,6 +293,26 @@ func (c *Context) UpdateUser(user *model.User) error {
 		c.db.Model(user).Update("usage_limit", user.UsageLimit)
 	}
 
+	if user.RateLimitRead == 0 {
+		c.db.Model(user).Update("rate_limit_read", user.RateLimitRead)
+	}
+
+	if user.RateLimitWrite == 0 {
+		c.db.Model(user).Update("rate_limit_write", user.RateLimitWrite)
+	}
+
+	if user.RateLimitProxy == 0 {
+		c.db.Model(user).Update("rate_limit_proxy", user.RateLimitProxy)
+	}
+
+	if user.UsagePeriod == model.UsagePeriodLifetime {
+		c.db.Model(user).Update("usage_period", user.UsagePeriod)
+	}
+
+	if user.UsagePeriodDays == 0 {
+		c.db.Model(user).Update("usage_period_days", user.UsagePeriodDays)
+	}
+
 	if c.db.Model(&user).Updates(user).RowsAffected > 0 {
 		return nil
 	}
@@ -163,6 +329,320 @@ func (c *Context) DeleteUser(user *model.User) error {
 
 }
 
+// ResetUserUsage moves usage of the expired period into usage history and starts the new period.
+// User row is locked, so concurrent resets of the same period are not possible
+func (c *Context) ResetUserUsage(user *model.User, now time.Time) (*model.User, error) {
+
+	tx := c.db.Begin()
+
+	res := &model.User{}
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&res, &model.User{ID: user.ID}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// period could be already reset by another request
+	if !res.UsagePeriodExpired(now) {
+		tx.Rollback()
+		return res, nil
+	}
+
+	history := &model.UsageHistory{
+		UserID:      res.ID,
+		PeriodStart: res.UsagePeriodStart,
+		PeriodEnd:   *res.UsagePeriodEnd(),
+		Usage:       res.Usage,
+		UsageLimit:  res.UsageLimit,
+	}
+
+	if err := tx.Create(history).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	start := res.NextUsagePeriodStart(now)
+	if err := tx.Model(res).Updates(map[string]interface{}{"usage": 0, "usage_period_start": start}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	res.Usage = 0
+	res.UsagePeriodStart = &start
+
+	return res, nil
+
+}
+
+func (c *Context) GetUsageHistory(history *model.UsageHistory) []*model.UsageHistory {
+
+	res := []*model.UsageHistory{}
+	c.db.Where(history).Order("period_end desc").Find(&res)
+	return res
+
+}
+
+func (c *Context) CreateAdmin(admin *model.Admin) (*model.Admin, error) {
+
+	if c.db.Create(&admin).RowsAffected > 0 {
+		return admin, nil
+	}
+
+	return nil, fmt.Errorf("Creating admin failed")
+
+}
+
+func (c *Context) GetAdmin(admin *model.Admin) *model.Admin {
+
+	res := &model.Admin{}
+	if c.db.First(&res, admin).RecordNotFound() {
+		return nil
+	}
+	return res
+
+}
+
+// GetEnabledAdmin returns enabled admin by username
+func (c *Context) GetEnabledAdmin(username string) *model.Admin {
+
+	res := &model.Admin{}
+	if c.db.Where("username = ? AND status = 1", username).First(&res).RecordNotFound() {
+		return nil
+	}
+	return res
+
+}
+
+func (c *Context) GetAdmins(admin *model.Admin) []*model.Admin {
+
+	res := []*model.Admin{}
+	c.db.Where(admin).Order("id").Find(&res)
+	return res
+
+}
+
+func (c *Context) UpdateAdmin(admin *model.Admin) error {
+
+	if admin.Status == 0 {
+		c.db.Model(admin).Update("status", admin.Status)
+	}
+
+	if c.db.Model(&admin).Updates(admin).RowsAffected > 0 {
+		return nil
+	}
+	return fmt.Errorf("DB: Updating admin failed")
+
+}
+
+func (c *Context) DeleteAdmin(admin *model.Admin) error {
+
+	if c.db.Delete(&admin).RowsAffected > 0 {
+		return nil
+	}
+	return fmt.Errorf("DB: Deletion admin failed")
+
+}
+
+// UpdateAdminTOTP writes TOTP fields of admin including zero values
+func (c *Context) UpdateAdminTOTP(admin *model.Admin) error {
+
+	return c.db.Model(admin).Updates(map[string]interface{}{
+		"totp_secret":    admin.TOTPSecret,
+		"totp_enabled":   admin.TOTPEnabled,
+		"totp_last_step": admin.TOTPLastStep,
+	}).Error
+
+}
+
+// UseAdminTOTPStep atomically marks TOTP step as used, so every code is accepted only once
+func (c *Context) UseAdminTOTPStep(admin *model.Admin, step int64) bool {
+
+	return c.db.Model(&model.Admin{}).Where("id = ? AND totp_last_step < ?", admin.ID, step).Update("totp_last_step", step).RowsAffected > 0
+
+}
+
+// ReplaceAdminRecoveryCodes deletes all recovery codes of admin and stores the new ones
+func (c *Context) ReplaceAdminRecoveryCodes(admin *model.Admin, codes []*model.AdminRecoveryCode) error {
+
+	tx := c.db.Begin()
+
+	if err := tx.Unscoped().Where("admin_id = ?", admin.ID).Delete(&model.AdminRecoveryCode{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, code := range codes {
+		if err := tx.Create(code).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+
+}
+
+// UseAdminRecoveryCode atomically marks unused recovery code as used
+func (c *Context) UseAdminRecoveryCode(admin *model.Admin, codeHash string, now time.Time) bool {
+
+	return c.db.Model(&model.AdminRecoveryCode{}).Where("admin_id = ? AND code_hash = ? AND used_at IS NULL", admin.ID, codeHash).Update("used_at", now).RowsAffected > 0
+
+}
+
+func (c *Context) CreateJWTKey(key *model.JWTKey) (*model.JWTKey, error) {
+
+	if c.db.Create(&key).RowsAffected > 0 {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("Creating JWT key failed")
+
+}
+
+func (c *Context) GetJWTKey(key *model.JWTKey) *model.JWTKey {
+
+	res := &model.JWTKey{}
+	if c.db.First(&res, key).RecordNotFound() {
+		return nil
+	}
+	return res
+
+}
+
+// GetJWTKeys returns keys, the latest first
+func (c *Context) GetJWTKeys(key *model.JWTKey) []*model.JWTKey {
+
+	res := []*model.JWTKey{}
+	c.db.Where(key).Order("id desc").Find(&res)
+	return res
+
+}
+
+// RetireJWTKeys retires all active keys except the given one
+func (c *Context) RetireJWTKeys(except *model.JWTKey, now time.Time) error {
+
+	return c.db.Model(&model.JWTKey{}).Where("retired_at IS NULL AND id <> ?", except.ID).Update("retired_at", now).Error
+
+}
+
+func (c *Context) DeleteJWTKeysRetiredBefore(t time.Time) error {
+
+	return c.db.Where("retired_at < ?", t).Delete(&model.JWTKey{}).Error
+
+}
+
+func (c *Context) CreateAdminSession(session *model.AdminSession) (*model.AdminSession, error) {
+
+	if c.db.Create(&session).RowsAffected > 0 {
+		return session, nil
+	}
+
+	return nil, fmt.Errorf("Creating admin session failed")
+
+}
+
+func (c *Context) GetAdminSession(session *model.AdminSession) *model.AdminSession {
+
+	res := &model.AdminSession{}
+	if c.db.First(&res, session).RecordNotFound() {
+		return nil
+	}
+	return res
+
+}
+
+// GetActiveAdminSessions returns not revoked & not expired sessions with their admins, the latest first
+func (c *Context) GetActiveAdminSessions(session *model.AdminSession, now time.Time) []*model.AdminSession {
+
+	res := []*model.AdminSession{}
+	c.db.Preload("Admin").Where(session).Where("revoked_at IS NULL AND expires_at > ?", now).Order("id desc").Find(&res)
+	return res
+
+}
+
+func (c *Context) UpdateAdminSession(session *model.AdminSession) error {
+
+	if c.db.Model(&session).Updates(session).RowsAffected > 0 {
+		return nil
+	}
+	return fmt.Errorf("DB: Updating admin session failed")
+
+}
+
+// RevokeAdminSessions revokes all active sessions matching the filter
+func (c *Context) RevokeAdminSessions(session *model.AdminSession, now time.Time) error {
+
+	return c.db.Model(&model.AdminSession{}).Where(session).Where("revoked_at IS NULL").Update("revoked_at", now).Error
+
+}
+
+func (c *Context) DeleteAdminSessionsExpiredBefore(t time.Time) error {
+
+	return c.db.Where("expires_at < ?", t).Delete(&model.AdminSession{}).Error
+
+}
+
+func (c *Context) CreateAPIKey(key *model.APIKey) (*model.APIKey, error) {
+
+	if c.db.Create(&key).RowsAffected > 0 {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("Creating API key failed")
+
+}
+
+func (c *Context) GetAPIKey(key *model.APIKey) *model.APIKey {
+
+	res := &model.APIKey{}
+	if c.db.First(&res, key).RecordNotFound() {
+		return nil
+	}
+	return res
+
+}
+
+// GetUserAPIKey returns API key by ID only if it's owned by the user,
+// conditions are explicit, as zero values of struct conditions are skipped by gorm
+func (c *Context) GetUserAPIKey(id int, userID int) *model.APIKey {
+
+	res := &model.APIKey{}
+	if c.db.Where("id = ? AND user_id = ?", id, userID).First(&res).RecordNotFound() {
+		return nil
+	}
+	return res
+
+}
+
+func (c *Context) GetAPIKeys(key *model.APIKey) []*model.APIKey {
+
+	res := []*model.APIKey{}
+	c.db.Where(key).Order("id").Find(&res)
+	return res
+
+}
+
+func (c *Context) UpdateAPIKey(key *model.APIKey) error {
+
+	if c.db.Model(&key).Updates(key).RowsAffected > 0 {
+		return nil
+	}
+	return fmt.Errorf("DB: Updating API key failed")
+
+}
+
+func (c *Context) DeleteAPIKey(key *model.APIKey) error {
+
+	if c.db.Delete(&key).RowsAffected > 0 {
+		return nil
+	}
+	return fmt.Errorf("DB: Deletion API key failed")
+
+}
+
 func (c *Context) GetChain(chain *model.Chain) *model.Chain {
 
 	res := &model.Chain{}
@@ -181,18 +661,75 @@ func (c *Context) GetChains(chain *model.Chain) []*model.Chain {
 
 }
 
+// GetChainsByID returns local chains from the list, which match chain
+func (c *Context) GetChainsByID(chainIDs []string, chain *model.Chain) []*model.Chain {
+
+	res := []*model.Chain{}
+	if len(chainIDs) == 0 {
+		return res
+	}
+	c.db.Where(chain).Where("chain_id IN (?)", chainIDs).Find(&res)
+	return res
+
+}
+
+// GetTrackedChains returns chains, which match chain & are pinned or bound to users
+func (c *Context) GetTrackedChains(chain *model.Chain) []*model.Chain {
+
+	res := []*model.Chain{}
+	c.db.Where(chain).Where(trackedChainSQL).Find(&res)
+	return res
+
+}
+
+// GetTrackedChainsByID returns local chains from the list, which match chain & are pinned or bound to users
+func (c *Context) GetTrackedChainsByID(chainIDs []string, chain *model.Chain) []*model.Chain {
+
+	res := []*model.Chain{}
+	if len(chainIDs) == 0 {
+		return res
+	}
+	c.db.Where(chain).Where("chain_id IN (?)", chainIDs).Where(trackedChainSQL).Find(&res)
+	return res
+
+}
+
+// GetChainsToSync returns not synced tracked chains, which are not sent to history parser yet and sync is not cancelled.
+// Chains with higher sync priority go first, then chains bound to users, then the least recently parsed ones
+func (c *Context) GetChainsToSync(limit int) []*model.Chain {
+
+	res := []*model.Chain{}
+	c.db.Where("synced IS FALSE AND worker_id = -1 AND sent_to_pool IS FALSE AND sync_priority >= 0").Where(trackedChainSQL).
+		Order("sync_priority DESC, EXISTS (SELECT 1 FROM users_chains WHERE users_chains.chain_chain_id = chains.chain_id) DESC, updated_at").
+		Limit(limit).Find(&res)
+	return res
+
+}
+
+// GetChainsToPurge returns chains, which are not tracked since before & are not being parsed by history parser
+func (c *Context) GetChainsToPurge(before time.Time, limit int) []*model.Chain {
+
+	res := []*model.Chain{}
+	c.db.Where("untracked_at < ? AND sent_to_pool IS FALSE", before).Where("NOT " + trackedChainSQL).
+		Order("untracked_at").Limit(limit).Find(&res)
+	return res
+
+}
+
 func (c *Context) GetUserChains(chain *model.Chain, user *model.User, start int, limit int, sort string) ([]*model.Chain, int) {
 
 	orderString := fmt.Sprintf("factom_time %s, created_at %s", sort, sort)
 
 	res := []*model.Chain{}
 
-	c.db.Order(orderString).Where(chain).Model(user).Related(&res, "Chains")
+	db := restrictByAPIKey(c.db, user)
+
+	db.Order(orderString).Where(chain).Model(user).Related(&res, "Chains")
 	total := len(res)
 
 	if start > 0 || total > limit {
 		log.Warn("Second DB Request")
-		c.db.Offset(start).Limit(limit).Order(orderString).Where(chain).Model(user).Related(&res, "Chains")
+		db.Offset(start).Limit(limit).Order(orderString).Where(chain).Model(user).Related(&res, "Chains")
 	}
 
 	return res, total
@@ -210,16 +747,40 @@ func (c *Context) SearchUserChains(chain *model.Chain, user *model.User, start i
 		where.Status = chain.Status
 	}
 
-	c.db.Order(orderString).Where("ext_ids @> ?", chain.ExtIDs).Where(where).Model(user).Related(&res, "Chains")
+	db := restrictByAPIKey(c.db, user)
+
+	db.Order(orderString).Where("ext_ids @> ?", chain.ExtIDs).Where(where).Model(user).Related(&res, "Chains")
 	total := len(res)
 
 	if start > 0 || total > limit {
-		c.db.Offset(start).Limit(limit).Order(orderString).Where("ext_ids @> ?", chain.ExtIDs).Where(where).Model(user).Related(&res, "Chains")
+		db.Offset(start).Limit(limit).Order(orderString).Where("ext_ids @> ?", chain.ExtIDs).Where(where).Model(user).Related(&res, "Chains")
 	}
 	return res, total
 
 }
 
+// SearchChains searches all local chains, not only bound to user, e.g. registered by chains indexer
+func (c *Context) SearchChains(chain *model.Chain, user *model.User, start int, limit int, sort string) ([]*model.Chain, int) {
+
+	orderString := fmt.Sprintf("factom_time %s, created_at %s", sort, sort)
+
+	res := []*model.Chain{}
+	var total int
+
+	where := &model.Chain{}
+	if chain.Status != "" {
+		where.Status = chain.Status
+	}
+
+	db := restrictByAPIKey(c.db, user).Model(&model.Chain{}).Where("ext_ids @> ?", chain.ExtIDs).Where(where)
+
+	db.Count(&total)
+	db.Offset(start).Limit(limit).Order(orderString).Find(&res)
+
+	return res, total
+
+}
+
 func (c *Context) CreateChain(chain *model.Chain) error {
 
 	if err := c.db.FirstOrCreate(&chain).Error; err != nil {
@@ -229,7 +790,7 @@ func (c *Context) CreateChain(chain *model.Chain) error {
 
 }
 
-func (c *Context) GetEntry(entry *model.Entry, sort string) *model.Entry {
+func (c *Context) GetEntry(entry *model.Entry, sort string) (*model.Entry, error) {
 
 	var orderString string
 	if sort != "" {
@@ -238,13 +799,27 @@ func (c *Context) GetEntry(entry *model.Entry, sort string) *model.Entry {
 
 	res := &model.Entry{}
 	if c.db.Order(orderString).First(&res, entry).RecordNotFound() {
+		return nil, nil
+	}
+	if err := c.resolveContent(res); err != nil {
+		return nil, err
+	}
+	return res, nil
+
+}
+
+// GetEntryChain returns chain of local entry, content of entry is not resolved
+func (c *Context) GetEntryChain(entry *model.Entry) *model.Chain {
+
+	res := &model.Entry{}
+	if c.db.Select("chain_id").Where("entry_hash = ?", entry.EntryHash).First(&res).RecordNotFound() {
 		return nil
 	}
-	return res
+	return res.GetChain()
 
 }
 
-func (c *Context) GetChainEntries(chain *model.Chain, entry *model.Entry, start int, limit int, sort string) ([]*model.Entry, int) {
+func (c *Context) GetChainEntries(chain *model.Chain, entry *model.Entry, start int, limit int, sort string) ([]*model.Entry, int, error) {
 
 	orderString := fmt.Sprintf("factom_time %s, created_at %s", sort, sort)
 
@@ -261,11 +836,14 @@ func (c *Context) GetChainEntries(chain *model.Chain, entry *model.Entry, start
 	if start > 0 || total > limit {
 		c.db.Offset(start).Limit(limit).Order(orderString).Model(chain).Where(where).Related(&res, "Entries")
 	}
-	return res, total
+	if err := c.resolveContent(res...); err != nil {
+		return nil, 0, err
+	}
+	return res, total, nil
 
 }
 
-func (c *Context) SearchChainEntries(chain *model.Chain, entry *model.Entry, start int, limit int, sort string) ([]*model.Entry, int) {
+func (c *Context) SearchChainEntries(chain *model.Chain, entry *model.Entry, start int, limit int, sort string) ([]*model.Entry, int, error) {
 
 	orderString := fmt.Sprintf("factom_time %s, created_at %s", sort, sort)
 
@@ -282,12 +860,22 @@ func (c *Context) SearchChainEntries(chain *model.Chain, entry *model.Entry, sta
 	if start > 0 || total > limit {
 		c.db.Offset(start).Limit(limit).Order(orderString).Where("ext_ids @> ?", entry.ExtIDs).Where(where).Model(chain).Related(&res, "Entries")
 	}
-	return res, total
+	if err := c.resolveContent(res...); err != nil {
+		return nil, 0, err
+	}
+	return res, total, nil
 
 }
 
 func (c *Context) CreateEntry(entry *model.Entry) error {
 
+	// content is saved into content store, entry keeps its hash only
+	content := entry.Content
+	defer func() { entry.Content = content }()
+	if err := c.putContent(entry); err != nil {
+		return err
+	}
+
 	assign := model.Entry{}
 	assign.Status = entry.Status
 	if entry.FactomTime != nil {
@@ -303,6 +891,12 @@ func (c *Context) CreateEntry(entry *model.Entry) error {
 
 func (c *Context) UpdateEntry(entry *model.Entry) error {
 
+	content := entry.Content
+	defer func() { entry.Content = content }()
+	if err := c.putContent(entry); err != nil {
+		return err
+	}
+
 	if c.db.Model(&entry).Updates(entry).RowsAffected > 0 {
 		return nil
 	}
@@ -310,6 +904,16 @@ func (c *Context) UpdateEntry(entry *model.Entry) error {
 
 }
 
+func (c *Context) GetEBlock(eblock *model.EBlock) *model.EBlock {
+
+	res := &model.EBlock{}
+	if c.db.First(&res, eblock).RecordNotFound() {
+		return nil
+	}
+	return res
+
+}
+
 func (c *Context) CreateEBlock(eblock *model.EBlock) error {
 
 	if err := c.db.FirstOrCreate(&eblock).Error; err != nil {
@@ -319,6 +923,35 @@ func (c *Context) CreateEBlock(eblock *model.EBlock) error {
 
 }
 
+// SetEBlockParsed marks entry block as parsed, i.e. all its entries are saved
+func (c *Context) SetEBlockParsed(eblock *model.EBlock) error {
+
+	if err := c.db.Model(&model.EBlock{}).Where("key_mr = ?", eblock.KeyMR).Update("parsed", true).Error; err != nil {
+		return err
+	}
+	eblock.Parsed = true
+	return nil
+
+}
+
+// GetSyncState returns parser checkpoint by name or nil, if it was not saved yet
+func (c *Context) GetSyncState(name string) *model.SyncState {
+
+	res := &model.SyncState{}
+	if c.db.First(&res, &model.SyncState{Name: name}).RecordNotFound() {
+		return nil
+	}
+	return res
+
+}
+
+// SaveSyncState creates or updates parser checkpoint
+func (c *Context) SaveSyncState(state *model.SyncState) error {
+
+	return c.db.Save(state).Error
+
+}
+
 func (c *Context) UpdateChain(chain *model.Chain) error {
 
 	if c.db.Model(&chain).Updates(chain).RowsAffected > 0 {
@@ -328,6 +961,21 @@ func (c *Context) UpdateChain(chain *model.Chain) error {
 
 }
 
+// AdvanceChainLatestEntryBlock sets chain LatestEntryBlock to eblock, if it's in a later dblock than the current one.
+// Returns false, if the checkpoint is kept, e.g. an old dblock is replayed
+func (c *Context) AdvanceChainLatestEntryBlock(chain *model.Chain, eblock *model.EBlock) (bool, error) {
+
+	res := c.db.Model(&model.Chain{}).
+		Where("chain_id = ?", chain.ChainID).
+		Where("NOT EXISTS (SELECT 1 FROM e_blocks WHERE e_blocks.key_mr = chains.latest_entry_block AND e_blocks.db_height >= ?)", eblock.DBHeight).
+		Update("latest_entry_block", eblock.KeyMR)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+
+}
+
 func (c *Context) UpdateChainsWhere(sql string, chain *model.Chain) error {
 
 	c.db.Model(model.Chain{}).Where(sql).Updates(chain)
@@ -336,14 +984,175 @@ func (c *Context) UpdateChainsWhere(sql string, chain *model.Chain) error {
 
 }
 
+// CountChainsBySync returns number of synced & unsynced local chains
+func (c *Context) CountChainsBySync() (map[string]int, error) {
+
+	return c.countBy(&model.Chain{}, "CASE WHEN synced THEN 'synced' ELSE 'unsynced' END")
+
+}
+
+// countBy counts rows of the model grouped by SQL expression
+func (c *Context) countBy(value interface{}, expr string) (map[string]int, error) {
+
+	rows, err := c.db.Model(value).Select(expr + " AS state, COUNT(*)").Group("state").Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make(map[string]int)
+	for rows.Next() {
+		var state string
+		var n int
+		if err := rows.Scan(&state, &n); err != nil {
+			return nil, err
+		}
+		res[state] = n
+	}
+
+	return res, rows.Err()
+
+}
+
+// ResetChainSync marks chain & its entry blocks as not synced, so its history is parsed again from the chain head
+func (c *Context) ResetChainSync(chain *model.Chain) error {
+
+	if err := c.db.Model(&model.EBlock{}).Where("chain_id = ?", chain.ChainID).Update("parsed", false).Error; err != nil {
+		return err
+	}
+
+	if c.db.Model(&chain).Updates(map[string]interface{}{
+		"synced":               false,
+		"earliest_entry_block": "",
+		"worker_id":            -1,
+		"sent_to_pool":         false,
+	}).RowsAffected > 0 {
+		return nil
+	}
+	return fmt.Errorf("DB: Resetting chain sync failed")
+
+}
+
+// SetChainSyncPriority sets history sync priority of the chain, negative priority cancels chain sync
+func (c *Context) SetChainSyncPriority(chain *model.Chain, priority int) error {
+
+	if c.db.Model(&model.Chain{}).Where("chain_id = ?", chain.ChainID).Update("sync_priority", priority).RowsAffected > 0 {
+		return nil
+	}
+	return fmt.Errorf("DB: Updating chain sync priority failed")
+
+}
+
+// RaiseChainSyncPriority raises sync priority of not synced chain up to priority, returns false if priority was not changed.
+// Sync priority of chains with cancelled sync is never raised
+func (c *Context) RaiseChainSyncPriority(chain *model.Chain, priority int) (bool, error) {
+
+	db := c.db.Model(&model.Chain{}).
+		Where("chain_id = ? AND synced IS FALSE AND sync_priority >= 0 AND sync_priority < ?", chain.ChainID, priority).
+		Update("sync_priority", priority)
+	if db.Error != nil {
+		return false, db.Error
+	}
+	return db.RowsAffected > 0, nil
+
+}
+
 func (c *Context) BindChainToUser(chain *model.Chain, user *model.User) error {
 
 	c.db.Model(user).Association("Chains").Append(chain)
 
+	// chain is tracked again
+	c.db.Model(&model.Chain{}).Where("chain_id = ? AND untracked_at IS NOT NULL", chain.ChainID).Update("untracked_at", gorm.Expr("NULL"))
+
+	return nil
+
+}
+
+// UnbindChainFromUser removes chain from user's chains, chain without users & not pinned is not tracked anymore
+func (c *Context) UnbindChainFromUser(chain *model.Chain, user *model.User) error {
+
+	if c.db.Exec("DELETE FROM users_chains WHERE user_id = ? AND chain_chain_id = ?", user.ID, chain.ChainID).RowsAffected == 0 {
+		return fmt.Errorf("DB: Chain %s is not bound to user", chain.ChainID)
+	}
+
+	return c.setUntracked(chain)
+
+}
+
+// SetChainPinned pins chain, so it's tracked without users, or unpins it
+func (c *Context) SetChainPinned(chain *model.Chain, pinned bool) error {
+
+	update := map[string]interface{}{"pinned": pinned}
+	if pinned {
+		update["untracked_at"] = gorm.Expr("NULL")
+	}
+
+	if c.db.Model(&model.Chain{}).Where("chain_id = ?", chain.ChainID).Updates(update).RowsAffected == 0 {
+		return fmt.Errorf("DB: Updating chain failed")
+	}
+
+	if !pinned {
+		return c.setUntracked(chain)
+	}
+
 	return nil
 
 }
 
+// setUntracked saves time since chain is not tracked, if it's not pinned & not bound to users
+func (c *Context) setUntracked(chain *model.Chain) error {
+
+	return c.db.Model(&model.Chain{}).
+		Where("chain_id = ? AND untracked_at IS NULL", chain.ChainID).Where("NOT "+trackedChainSQL).
+		Update("untracked_at", time.Now().UTC()).Error
+
+}
+
+// PurgeChainEntries deletes completed entries of the chain, except entries with callbacks.
+// Entry blocks are kept, but marked as not parsed, so chain history can be synced again. Returns number of deleted entries
+func (c *Context) PurgeChainEntries(chain *model.Chain) (int, error) {
+
+	// completed entries without callbacks
+	entries := "SELECT entry_hash FROM entries WHERE chain_id = ? AND status = ? AND NOT EXISTS (SELECT 1 FROM callbacks WHERE callbacks.entry_hash = entries.entry_hash)"
+
+	tx := c.db.Begin()
+
+	if err := tx.Exec("DELETE FROM entries_e_blocks WHERE entry_entry_hash IN ("+entries+")", chain.ChainID, model.EntryCompleted).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	res := tx.Exec("DELETE FROM entries WHERE entry_hash IN ("+entries+")", chain.ChainID, model.EntryCompleted)
+	if res.Error != nil {
+		tx.Rollback()
+		return 0, res.Error
+	}
+
+	if err := tx.Model(&model.EBlock{}).Where("chain_id = ?", chain.ChainID).Update("parsed", false).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	// untracked_at is cleared, so purged chain is not selected again
+	if err := tx.Model(&model.Chain{}).Where("chain_id = ?", chain.ChainID).Updates(map[string]interface{}{
+		"synced":               false,
+		"earliest_entry_block": "",
+		"worker_id":            -1,
+		"sent_to_pool":         false,
+		"untracked_at":         gorm.Expr("NULL"),
+	}).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	return int(res.RowsAffected), nil
+
+}
+
 func (c *Context) BindEntryToEBlock(entry *model.Entry, eblock *model.EBlock) error {
 
 	c.db.Model(eblock).Association("Entries").Append(entry)
@@ -406,6 +1215,54 @@ func (c *Context) DeleteQueue(queue *model.Queue) error {
 
 }
 
+// RetryQueue clears postponed time of the next attempt, so queue item is processed immediately
+func (c *Context) RetryQueue(queue *model.Queue) error {
+
+	if c.db.Model(&queue).Where("processed_at IS NULL").UpdateColumn("next_try_at", nil).RowsAffected > 0 {
+		return nil
+	}
+	return fmt.Errorf("DB: Queue item %d not found or already processed", queue.ID)
+
+}
+
+// PurgeQueue deletes processed queue items or all of them, returns number of deleted items
+func (c *Context) PurgeQueue(all bool) (int, error) {
+
+	db := c.db
+	if !all {
+		db = db.Where("processed_at IS NOT NULL")
+	}
+
+	res := db.Delete(&model.Queue{})
+	return int(res.RowsAffected), res.Error
+
+}
+
+// CountQueueByState returns number of queue items: pending, retrying (failed before) & processed
+func (c *Context) CountQueueByState() (map[string]int, error) {
+
+	return c.countBy(&model.Queue{}, `CASE
+		WHEN processed_at IS NOT NULL THEN 'processed'
+		WHEN next_try_at IS NOT NULL THEN 'retrying'
+		ELSE 'pending' END`)
+
+}
+
+// GetQueueBacklog returns number of unprocessed queue items & creation time of the oldest one
+func (c *Context) GetQueueBacklog() (int, *time.Time, error) {
+
+	var res struct {
+		Count  int
+		Oldest *time.Time
+	}
+
+	err := c.db.Model(&model.Queue{}).Select("COUNT(*) AS count, MIN(created_at) AS oldest").
+		Where("processed_at IS NULL").Scan(&res).Error
+
+	return res.Count, res.Oldest, err
+
+}
+
 func (c *Context) GetCallback(callback *model.Callback) *model.Callback {
 
 	res := &model.Callback{}
@@ -452,3 +1309,43 @@ func (c *Context) DeleteCallback(callback *model.Callback) error {
 	return fmt.Errorf("DB: Deletion callback failed")
 
 }
+
+// restrictByAPIKey limits chains query to the chains allowed by API key of the user
+func restrictByAPIKey(db *gorm.DB, user *model.User) *gorm.DB {
+
+	if user.APIKey == nil || !user.APIKey.IsRestricted() {
+		return db
+	}
+
+	return db.Where("chains.chain_id = ANY(?) OR chains.ext_ids[1] = ANY(?)", user.APIKey.ChainIDs, user.APIKey.ExtIDNamespaces)
+
+}
+
+func (c *Context) CreateAuditLog(entry *model.AuditLog) error {
+
+	if c.db.Create(entry).RowsAffected > 0 {
+		return nil
+	}
+	return fmt.Errorf("DB: Creating audit log entry failed")
+
+}
+
+func (c *Context) GetAuditLogs(entry *model.AuditLog, from *time.Time, to *time.Time, start int, limit int, sort string) ([]*model.AuditLog, int) {
+
+	res := []*model.AuditLog{}
+	var total int
+
+	db := c.db.Model(&model.AuditLog{}).Where(entry)
+	if from != nil {
+		db = db.Where("created_at >= ?", from)
+	}
+	if to != nil {
+		db = db.Where("created_at < ?", to)
+	}
+
+	db.Count(&total)
+	db.Order(fmt.Sprintf("id %s", sort)).Offset(start).Limit(limit).Find(&res)
+
+	return res, total
+
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+
+	"github.com/DeFacto-Team/Factom-Open-API/tracing"
+	"github.com/jinzhu/gorm"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// gorm setting with context of the store, set by WithContext
+	tracingContextKey = "tracing:context"
+	// gorm instance setting with span of the current operation
+	tracingSpanKey = "tracing:span"
+)
+
+// registerTracing wraps gorm create, query, update, delete & raw queries into spans,
+// which are children of the span from the store context
+func registerTracing(db *gorm.DB) {
+
+	callback := db.Callback()
+
+	callback.Create().Before("gorm:create").Register("tracing:before_create", startSpan("create"))
+	callback.Create().After("gorm:create").Register("tracing:after_create", endSpan)
+	callback.Query().Before("gorm:query").Register("tracing:before_query", startSpan("query"))
+	callback.Query().After("gorm:query").Register("tracing:after_query", endSpan)
+	callback.Update().Before("gorm:update").Register("tracing:before_update", startSpan("update"))
+	callback.Update().After("gorm:update").Register("tracing:after_update", endSpan)
+	callback.Delete().Before("gorm:delete").Register("tracing:before_delete", startSpan("delete"))
+	callback.Delete().After("gorm:delete").Register("tracing:after_delete", endSpan)
+	callback.RowQuery().Before("gorm:row_query").Register("tracing:before_row_query", startSpan("row_query"))
+	callback.RowQuery().After("gorm:row_query").Register("tracing:after_row_query", endSpan)
+
+}
+
+func startSpan(operation string) func(scope *gorm.Scope) {
+
+	return func(scope *gorm.Scope) {
+
+		ctx := context.Background()
+		if v, ok := scope.Get(tracingContextKey); ok {
+			ctx = v.(context.Context)
+		}
+
+		_, span := tracing.Start(ctx, "gorm."+operation, trace.SpanKindClient,
+			attribute.String(tracing.AttrDBSystem, "postgresql"),
+			attribute.String(tracing.AttrDBTable, scope.TableName()),
+		)
+		scope.InstanceSet(tracingSpanKey, span)
+
+	}
+
+}
+
+func endSpan(scope *gorm.Scope) {
+
+	v, ok := scope.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+
+	span := v.(trace.Span)
+	// SQL contains placeholders only, values of params are not recorded
+	span.SetAttributes(attribute.String(tracing.AttrDBStatement, scope.SQL))
+
+	err := scope.DB().Error
+	if gorm.IsRecordNotFoundError(err) {
+		err = nil
+	}
+	tracing.End(span, err)
+
+}
@@ -0,0 +1,116 @@
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// delay before restarting worker after panic
+const RestartDelay = 5 * time.Second
+
+// Supervisor owns background workers: starts them with common context,
+// restarts them after panic and stops them by context cancellation
+type Supervisor struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates supervisor, which workers are stopped when parent context is done or Stop is called
+func New(parent context.Context) *Supervisor {
+
+	ctx, cancel := context.WithCancel(parent)
+	return &Supervisor{ctx: ctx, cancel: cancel}
+
+}
+
+// Go starts worker. Worker should return as soon as ctx is done
+func (s *Supervisor) Go(name string, worker func(ctx context.Context)) {
+
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+		for {
+			if err := run(s.ctx, worker); err != nil {
+				log.WithField("worker", name).Error(err)
+				if !Sleep(s.ctx, RestartDelay) {
+					return
+				}
+				log.WithField("worker", name).Warn("Restarting worker")
+				continue
+			}
+			return
+		}
+	}()
+
+}
+
+// Every starts worker, which runs fn every interval until ctx is done
+func (s *Supervisor) Every(name string, interval time.Duration, fn func(ctx context.Context)) {
+
+	s.Go(name, func(ctx context.Context) {
+		for {
+			fn(ctx)
+			if !Sleep(ctx, interval) {
+				return
+			}
+		}
+	})
+
+}
+
+// Stop cancels context of all workers and waits until they return.
+// Returns error if workers were not stopped before ctx is done
+func (s *Supervisor) Stop(ctx context.Context) error {
+
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("Background workers were not stopped in time: %s", ctx.Err())
+	}
+
+}
+
+// Sleep pauses for duration d. Returns false if ctx is done earlier
+func Sleep(ctx context.Context, d time.Duration) bool {
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+
+}
+
+// run calls worker and converts its panic into error
+func run(ctx context.Context, worker func(ctx context.Context)) (err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Worker panic: %v", r)
+		}
+	}()
+
+	worker(ctx)
+
+	return nil
+
+}
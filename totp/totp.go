@@ -0,0 +1,116 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// RFC 6238 defaults supported by all authenticator apps
+	Period = 30 * time.Second
+	Digits = 6
+	// number of periods before & after the current one, which codes are accepted to tolerate clock drift
+	Skew = 1
+	// length of generated secrets in bytes (160 bits, as recommended by RFC 4226)
+	SecretLength = 20
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns random base32 encoded secret
+func GenerateSecret() (string, error) {
+
+	b := make([]byte, SecretLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return encoding.EncodeToString(b), nil
+
+}
+
+// Step returns the number of the period containing the moment
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period.Seconds())
+}
+
+// Code returns TOTP code of the secret at the moment
+func Code(secret string, t time.Time) (string, error) {
+	return codeAt(secret, Step(t))
+}
+
+// Validate checks the code at the moment, tolerating Skew periods of clock drift.
+// Matched step is returned, so the caller can reject codes, which were already used
+func Validate(secret string, code string, t time.Time) (int64, bool) {
+
+	code = strings.TrimSpace(code)
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	current := Step(t)
+	for step := current - Skew; step <= current+Skew; step++ {
+		expected, err := codeAt(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+
+}
+
+// ProvisioningURI returns otpauth:// URI to be encoded into QR code for authenticator apps
+func ProvisioningURI(issuer string, account string, secret string) string {
+
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprintf("%d", Digits))
+	v.Set("period", fmt.Sprintf("%d", int(Period.Seconds())))
+
+	label := url.PathEscape(issuer + ":" + account)
+
+	// some authenticator apps do not decode "+" as space
+	return "otpauth://totp/" + label + "?" + strings.Replace(v.Encode(), "+", "%20", -1)
+
+}
+
+// codeAt implements HOTP (RFC 4226) for the counter
+func codeAt(secret string, counter int64) (string, error) {
+
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", fmt.Errorf("Invalid TOTP secret: %v", err)
+	}
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", Digits, value%mod), nil
+
+}
@@ -0,0 +1,95 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCode(t *testing.T) {
+
+	// RFC 6238 test vectors (SHA1, truncated to 6 digits)
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for ts, expected := range vectors {
+		code, err := Code(secret, time.Unix(ts, 0))
+		if assert.NoError(t, err) {
+			assert.Equal(t, expected, code)
+		}
+	}
+
+	// lowercase & padded secrets are accepted, invalid are not
+	code, err := Code(strings.ToLower(secret)+"==", time.Unix(59, 0))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "287082", code)
+	}
+
+	_, err = Code("not base32!", time.Unix(59, 0))
+	assert.Error(t, err)
+
+}
+
+func TestValidate(t *testing.T) {
+
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	now := time.Unix(1234567890, 0)
+
+	code := func(d time.Duration) string {
+		c, _ := Code(secret, now.Add(d))
+		return c
+	}
+
+	tests := []struct {
+		name string
+		code string
+		step int64
+		ok   bool
+	}{
+		{"current", code(0), Step(now), true},
+		{"previous", code(-Period), Step(now) - 1, true},
+		{"next", code(Period), Step(now) + 1, true},
+		{"with spaces", " " + code(0) + " ", Step(now), true},
+		{"expired", code(-3 * Period), 0, false},
+		{"short", "12345", 0, false},
+		{"empty", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		step, ok := Validate(secret, tt.code, now)
+		assert.Equal(t, tt.ok, ok, tt.name)
+		assert.Equal(t, tt.step, step, tt.name)
+	}
+
+}
+
+func TestGenerateSecret(t *testing.T) {
+
+	secret, err := GenerateSecret()
+	if assert.NoError(t, err) {
+		// 20 bytes are encoded into 32 base32 chars
+		assert.Len(t, secret, 32)
+		_, err = Code(secret, time.Now())
+		assert.NoError(t, err)
+	}
+
+}
+
+func TestProvisioningURI(t *testing.T) {
+
+	uri := ProvisioningURI("Factom Open API", "admin", "SECRET")
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Factom%20Open%20API:admin?"))
+	assert.Contains(t, uri, "issuer=Factom%20Open%20API")
+	assert.Contains(t, uri, "secret=SECRET")
+	assert.Contains(t, uri, "digits=6")
+	assert.Contains(t, uri, "period=30")
+
+}
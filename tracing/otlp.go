@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	// path of OTLP/HTTP traces receiver, appended if endpoint has no path
+	OTLPTracesPath = "/v1/traces"
+	// timeout of one export request
+	OTLPTimeout = 10 * time.Second
+)
+
+// NewOTLPExporter creates exporter sending spans to OTLP/HTTP endpoint, e.g. http://otel-collector:4318.
+// Exporter sends requests via own transport, so they are not traced & not counted as factomd calls
+func NewOTLPExporter(endpoint string) (sdktrace.SpanExporter, error) {
+
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("OTLP endpoint expected to be http(s) URL, '%s' received", endpoint)
+	}
+
+	if u.Path == "" || u.Path == "/" {
+		u.Path = OTLPTracesPath
+	}
+
+	return otlptracehttp.New(context.Background(),
+		otlptracehttp.WithEndpointURL(u.String()),
+		otlptracehttp.WithTimeout(OTLPTimeout),
+	)
+
+}
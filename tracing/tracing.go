@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// name of the service & instrumentation scope in exported spans
+	ServiceName = "factom-open-api"
+
+	// span attributes
+	AttrHTTPMethod     = "http.method"
+	AttrHTTPRoute      = "http.route"
+	AttrHTTPStatusCode = "http.status_code"
+	AttrDBSystem       = "db.system"
+	AttrDBStatement    = "db.statement"
+	AttrDBTable        = "db.sql.table"
+	AttrRPCSystem      = "rpc.system"
+	AttrRPCMethod      = "rpc.method"
+	AttrRequestID      = "request_id"
+	AttrChainID        = "chain_id"
+	AttrEntryHash      = "entry_hash"
+	AttrKeyMR          = "keymr"
+	AttrDBHeight       = "dbheight"
+)
+
+// Setup sets global tracer provider exporting spans via OTLP/HTTP to endpoint,
+// only a ratio of new traces is sampled, sampling decision of incoming traces is respected.
+// Tracing is disabled if endpoint is empty. Returned func flushes spans & stops exporter
+func Setup(endpoint string, ratio float64) (func(context.Context) error, error) {
+
+	// W3C traceparent is extracted from incoming requests even if tracing is disabled,
+	// so request logs are correlated with caller's traces
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := NewOTLPExporter(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := NewProvider(sdktrace.NewBatchSpanProcessor(exporter), sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio)))
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+
+}
+
+// NewProvider creates tracer provider of the API with span processor & sampler
+func NewProvider(processor sdktrace.SpanProcessor, sampler sdktrace.Sampler) *sdktrace.TracerProvider {
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", ServiceName))),
+	)
+
+}
+
+// Start starts span using global tracer provider, i.e. noop span if tracing is disabled
+func Start(ctx context.Context, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return otel.Tracer(ServiceName).Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+
+}
+
+// StartFactomd starts client span of factomd JSON-RPC call
+func StartFactomd(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+
+	attrs = append(attrs, attribute.String(AttrRPCSystem, "jsonrpc"), attribute.String(AttrRPCMethod, method))
+	return Start(ctx, "factomd "+method, trace.SpanKindClient, attrs...)
+
+}
+
+// End records err (if any) in span & ends it
+func End(span trace.Span, err error) {
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+
+}
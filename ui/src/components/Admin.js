@@ -11,11 +11,17 @@ import Dashboard from './admin/Dashboard';
 import Queue from './admin/Queue';
 import Users from './admin/Users';
 import Settings from './admin/Settings';
+import Admins from './admin/Admins';
+import Security from './admin/Security';
+import Audit from './admin/Audit';
 
 const { Header, Content, Footer } = Layout;
 
 const Admin = props => {
   const [currentMenu, setCurrentMenu] = useState([window.location.hash]);
+  const [me, setMe] = useState({});
+
+  const isSuperadmin = me.role === 'superadmin';
 
   const handleMenuClick = e => {
     setCurrentMenu([e.key]);
@@ -32,8 +38,20 @@ const Admin = props => {
       });
   };
     
+  const getMe = () => {
+    axios
+      .get('/admin/me')
+      .then(function(response) {
+        setMe(response.data.result);
+      })
+      .catch(function(error) {
+        NotifyNetworkError();
+      });
+  };
+
   useEffect(() => {
     setCurrentMenu([window.location.hash]);
+    getMe();
   }, []);
 
   return (
@@ -66,12 +84,36 @@ const Admin = props => {
                 Queue
               </Link>
             </Menu.Item>
-            <Menu.Item key="#/settings">
-              <Link to="/settings">
-                <Icon type="setting" />
-                Settings
+            <Menu.Item key="#/security">
+              <Link to="/security">
+                <Icon type="safety" />
+                Security
               </Link>
             </Menu.Item>
+            {isSuperadmin && (
+              <Menu.Item key="#/admins">
+                <Link to="/admins">
+                  <Icon type="crown" />
+                  Admins
+                </Link>
+              </Menu.Item>
+            )}
+            {isSuperadmin && (
+              <Menu.Item key="#/audit">
+                <Link to="/audit">
+                  <Icon type="audit" />
+                  Audit
+                </Link>
+              </Menu.Item>
+            )}
+            {isSuperadmin && (
+              <Menu.Item key="#/settings">
+                <Link to="/settings">
+                  <Icon type="setting" />
+                  Settings
+                </Link>
+              </Menu.Item>
+            )}
             <Menu.Item key="#/logout" onClick={logout} className="menu-logout">
               <Link to="/">
                 <Icon type="logout" />
@@ -84,6 +126,9 @@ const Admin = props => {
           <Route exact path="/" component={Dashboard} />
           <Route exact path="/users" component={Users} />
           <Route exact path="/queue" component={Queue} />
+          <Route exact path="/security" component={Security} />
+          <Route exact path="/admins" component={Admins} />
+          <Route exact path="/audit" component={Audit} />
           <Route exact path="/settings" component={Settings} />
         </Content>
         <Footer style={{ padding: '18px 24px', margin: 0, background: '#fff' }}>
@@ -13,12 +13,13 @@ const { Title, Text } = Typography;
 const Login = props => {
   const [isSubmitting, setIsSubmitting] = useState(false);
   const [loginError, setLoginError] = useState(null);
+  const [mfaToken, setMfaToken] = useState(null);
 
   const handleSubmit = event => {
     event.preventDefault();
     setIsSubmitting(true);
     if (!event.target.checkValidity()) {
-      setLoginError('Please fill login/password');
+      setLoginError(mfaToken ? 'Please enter the code' : 'Please fill login/password');
       setIsSubmitting(false);
       return;
     }
@@ -30,6 +31,11 @@ const Login = props => {
       .post('/login', data)
       .then(function(response) {
         setLoginError(null);
+        if (response.data.totpRequired) {
+          setMfaToken(response.data.mfaToken);
+          setIsSubmitting(false);
+          return;
+        }
         props.setLoggedIn(true);
       })
       .catch(function(error) {
@@ -54,25 +60,42 @@ const Login = props => {
       <Content style={{ padding: 24, margin: 0 }}>
         <Title level={4}>Administrator area</Title>
         <Form layout="inline" onSubmit={handleSubmit} noValidate>
-          <Form.Item>
-            <Input
-              prefix={<Icon type="user" style={{ color: 'rgba(0,0,0,.25)' }} />}
-              placeholder="User"
-              id="user"
-              name="user"
-              required
-            />
-          </Form.Item>
-          <Form.Item>
-            <Input
-              prefix={<Icon type="lock" style={{ color: 'rgba(0,0,0,.25)' }} />}
-              type="password"
-              placeholder="Password"
-              id="password"
-              name="password"
-              required
-            />
-          </Form.Item>
+          {mfaToken ? (
+            <Form.Item>
+              <input type="hidden" name="mfaToken" value={mfaToken} />
+              <Input
+                prefix={<Icon type="safety" style={{ color: 'rgba(0,0,0,.25)' }} />}
+                placeholder="Authentication or recovery code"
+                id="code"
+                name="code"
+                autoComplete="one-time-code"
+                autoFocus
+                required
+              />
+            </Form.Item>
+          ) : (
+            <>
+              <Form.Item>
+                <Input
+                  prefix={<Icon type="user" style={{ color: 'rgba(0,0,0,.25)' }} />}
+                  placeholder="User"
+                  id="user"
+                  name="user"
+                  required
+                />
+              </Form.Item>
+              <Form.Item>
+                <Input
+                  prefix={<Icon type="lock" style={{ color: 'rgba(0,0,0,.25)' }} />}
+                  type="password"
+                  placeholder="Password"
+                  id="password"
+                  name="password"
+                  required
+                />
+              </Form.Item>
+            </>
+          )}
           <Form.Item>
             <Button type="primary" htmlType="submit" loading={isSubmitting}>
               Login
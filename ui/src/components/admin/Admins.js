@@ -0,0 +1,254 @@
+import React, { useState, useEffect } from 'react';
+import Moment from 'react-moment';
+import axios from 'axios';
+
+import {
+  Typography,
+  Button,
+  Icon,
+  Table,
+  Input,
+  Popconfirm,
+  Select,
+  message,
+  Tag,
+  Form
+} from 'antd';
+import { NotifyNetworkError } from './../common/Notifications';
+import Sessions from './Sessions';
+
+const { Title, Text } = Typography;
+const { Option } = Select;
+
+const roles = ['viewer', 'operator', 'superadmin'];
+
+const Admins = () => {
+  const [admins, setAdmins] = useState([]);
+  const [username, setUsername] = useState('');
+  const [password, setPassword] = useState('');
+  const [role, setRole] = useState('viewer');
+  const [isSubmitting, setIsSubmitting] = useState(false);
+  const [tableIsLoading, setTableIsLoading] = useState(true);
+
+  const handleError = error => {
+    if (error.response) {
+      message.error(error.response.data.error);
+    } else {
+      NotifyNetworkError();
+    }
+  };
+
+  const getAdmins = () => {
+    axios
+      .get('/admin/admins')
+      .then(function(response) {
+        setAdmins(response.data.result);
+      })
+      .catch(handleError)
+      .finally(function() {
+        setTableIsLoading(false);
+      });
+  };
+
+  const handleSubmit = event => {
+    event.preventDefault();
+    setIsSubmitting(true);
+
+    axios
+      .post('/admin/admins', { username: username, password: password, role: role })
+      .then(function(response) {
+        setAdmins([...admins, response.data.result]);
+        setUsername('');
+        setPassword('');
+        message.success(`Admin '${response.data.result.username}' added`);
+      })
+      .catch(handleError)
+      .finally(function() {
+        setIsSubmitting(false);
+      });
+  };
+
+  const updateAdmin = (admin, field, value) => {
+    var payload = {};
+    payload[field] = value;
+
+    axios
+      .put('/admin/admins/' + admin.id, payload)
+      .then(function(response) {
+        const array = [...admins];
+        const index = array.findIndex(v => v.id === admin.id);
+        array[index] = response.data.result;
+        setAdmins(array);
+        message.success(`Admin '${admin.username}' updated`);
+      })
+      .catch(handleError);
+  };
+
+  const resetTOTP = admin => {
+    axios
+      .delete('/admin/admins/' + admin.id + '/totp')
+      .then(function(response) {
+        const array = [...admins];
+        const index = array.findIndex(v => v.id === admin.id);
+        array[index] = response.data.result;
+        setAdmins(array);
+        message.success(`Two-factor authentication of '${admin.username}' reset`);
+      })
+      .catch(handleError);
+  };
+
+  const deleteAdmin = admin => {
+    setTableIsLoading(true);
+
+    axios
+      .delete('/admin/admins/' + admin.id)
+      .then(function() {
+        setAdmins([...admins].filter(v => v.id !== admin.id));
+        message.success(`Admin '${admin.username}' deleted`);
+      })
+      .catch(handleError)
+      .finally(function() {
+        setTableIsLoading(false);
+      });
+  };
+
+  const columns = [
+    {
+      title: 'Username',
+      dataIndex: 'username'
+    },
+    {
+      title: 'Role',
+      dataIndex: 'role',
+      render: (text, admin) => (
+        <Select
+          value={admin.role}
+          size="small"
+          style={{ width: 120 }}
+          onChange={value => updateAdmin(admin, 'role', value)}
+        >
+          {roles.map(r => (
+            <Option key={r} value={r}>
+              {r}
+            </Option>
+          ))}
+        </Select>
+      )
+    },
+    {
+      title: '2FA',
+      dataIndex: 'totpEnabled',
+      render: (text, admin) =>
+        admin.totpEnabled ? (
+          <Popconfirm
+            title={`Reset two-factor authentication of '${admin.username}'?`}
+            onConfirm={() => resetTOTP(admin)}
+            okText="Reset"
+            cancelText="No"
+          >
+            <Tag color="green" className="pointer">
+              ON
+            </Tag>
+          </Popconfirm>
+        ) : (
+          <Tag>OFF</Tag>
+        )
+    },
+    {
+      title: 'Last login',
+      dataIndex: 'lastLoginAt',
+      render: (text, admin) =>
+        admin.lastLoginAt ? (
+          <Moment date={admin.lastLoginAt} format="YYYY-MM-DD HH:mm:ss" local />
+        ) : (
+          <Text type="secondary">never</Text>
+        )
+    },
+    {
+      title: 'Status',
+      dataIndex: 'status',
+      render: (text, admin) => (
+        <Tag
+          color={admin.status ? 'green' : 'orange'}
+          onClick={() => updateAdmin(admin, 'status', admin.status ? 0 : 1)}
+          className="pointer"
+        >
+          {admin.status ? 'ON' : 'OFF'}
+        </Tag>
+      )
+    },
+    {
+      title: 'Actions',
+      key: 'actions',
+      render: (text, admin) => (
+        <Popconfirm
+          title={`Delete admin '${admin.username}'?`}
+          onConfirm={() => deleteAdmin(admin)}
+          okText="Delete"
+          cancelText="No"
+        >
+          <a href="javascript:;" style={{ color: '#f5222d' }}>
+            <Icon type="delete" theme="twoTone" twoToneColor="#f5222d" />
+             Delete
+          </a>
+        </Popconfirm>
+      )
+    }
+  ];
+
+  useEffect(() => getAdmins(), []);
+
+  return (
+    <div>
+      <Title level={3}>Admins</Title>
+      <Form layout="inline" noValidate onSubmit={handleSubmit}>
+        <Form.Item>
+          <Input
+            prefix={<Icon type="user" style={{ color: 'rgba(0,0,0,.25)' }} />}
+            placeholder="Username"
+            value={username}
+            onChange={event => setUsername(event.target.value)}
+          />
+        </Form.Item>
+        <Form.Item>
+          <Input
+            prefix={<Icon type="lock" style={{ color: 'rgba(0,0,0,.25)' }} />}
+            type="password"
+            placeholder="Password (8+ chars)"
+            value={password}
+            onChange={event => setPassword(event.target.value)}
+          />
+        </Form.Item>
+        <Form.Item>
+          <Select value={role} style={{ width: 120 }} onChange={setRole}>
+            {roles.map(r => (
+              <Option key={r} value={r}>
+                {r}
+              </Option>
+            ))}
+          </Select>
+        </Form.Item>
+        <Form.Item>
+          <Button
+            type="primary"
+            icon="plus"
+            htmlType="submit"
+            disabled={username === '' || password.length < 8}
+            loading={isSubmitting}
+          >
+            Add admin
+          </Button>
+        </Form.Item>
+      </Form>
+      <Table
+        dataSource={admins}
+        columns={columns}
+        rowKey="id"
+        loading={tableIsLoading}
+      />
+      <Sessions />
+    </div>
+  );
+};
+
+export default Admins;
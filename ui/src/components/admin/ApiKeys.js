@@ -0,0 +1,186 @@
+import React, { useState, useEffect } from 'react';
+import Moment from 'react-moment';
+import axios from 'axios';
+
+import {
+  Typography,
+  Button,
+  Icon,
+  Table,
+  Input,
+  Popconfirm,
+  message,
+  Tag,
+  Form
+} from 'antd';
+import { NotifyNetworkError } from './../common/Notifications';
+
+const { Text } = Typography;
+
+const ApiKeys = props => {
+  const [keys, setKeys] = useState([]);
+  const [name, setName] = useState('');
+  const [isSubmitting, setIsSubmitting] = useState(false);
+  const [tableIsLoading, setTableIsLoading] = useState(true);
+
+  const handleError = error => {
+    if (error.response) {
+      message.error(error.response.data.error);
+    } else {
+      NotifyNetworkError();
+    }
+  };
+
+  const getKeys = () => {
+    axios
+      .get('/admin/users/' + props.user.id + '/keys')
+      .then(function(response) {
+        setKeys(response.data.result);
+      })
+      .catch(handleError)
+      .finally(function() {
+        setTableIsLoading(false);
+      });
+  };
+
+  const handleSubmit = event => {
+    event.preventDefault();
+    setIsSubmitting(true);
+
+    axios
+      .post('/admin/users/' + props.user.id + '/keys', { name: name })
+      .then(function(response) {
+        setKeys([...keys, response.data.result]);
+        setName('');
+        message.success(`API key '${response.data.result.name}' created`);
+      })
+      .catch(handleError)
+      .finally(function() {
+        setIsSubmitting(false);
+      });
+  };
+
+  const revokeKey = key => {
+    setTableIsLoading(true);
+
+    axios
+      .delete('/admin/users/' + props.user.id + '/keys/' + key.id)
+      .then(function() {
+        setKeys([...keys].filter(v => v.id !== key.id));
+        message.success(`API key '${key.name}' revoked`);
+      })
+      .catch(handleError)
+      .finally(function() {
+        setTableIsLoading(false);
+      });
+  };
+
+  const columns = [
+    {
+      title: 'Name',
+      dataIndex: 'name'
+    },
+    {
+      title: 'Key',
+      dataIndex: 'prefix',
+      render: (text, key) => {
+        if (key.secret) {
+          return (
+            <Text copyable={{ text: key.secret }} mark>
+              {key.secret}
+            </Text>
+          );
+        }
+        return <Text code>{key.prefix}…</Text>;
+      }
+    },
+    {
+      title: 'Scopes',
+      dataIndex: 'scopes',
+      render: (text, key) => (
+        <span>
+          {key.scopes && key.scopes.length > 0 ? (
+            key.scopes.map(scope => <Tag key={scope}>{scope}</Tag>)
+          ) : (
+            <Text type="secondary">default</Text>
+          )}
+          {(key.chainIds || []).length + (key.extIdNamespaces || []).length >
+            0 && <Tag color="orange">restricted</Tag>}
+        </span>
+      )
+    },
+    {
+      title: 'Last used',
+      dataIndex: 'lastUsedAt',
+      render: (text, key) =>
+        key.lastUsedAt ? (
+          <Moment date={key.lastUsedAt} format="YYYY-MM-DD HH:mm:ss" local />
+        ) : (
+          <Text type="secondary">never</Text>
+        )
+    },
+    {
+      title: 'Expires',
+      dataIndex: 'expiresAt',
+      render: (text, key) =>
+        key.expiresAt ? (
+          <Moment date={key.expiresAt} format="YYYY-MM-DD HH:mm:ss" local />
+        ) : (
+          <Text type="secondary">never</Text>
+        )
+    },
+    {
+      title: 'Actions',
+      key: 'actions',
+      render: (text, key) => (
+        <Popconfirm
+          title={`Revoke API key '${key.name}'?`}
+          onConfirm={() => revokeKey(key)}
+          okText="Revoke"
+          cancelText="No"
+        >
+          <a href="javascript:;" style={{ color: '#f5222d' }}>
+            <Icon type="stop" theme="twoTone" twoToneColor="#f5222d" />
+             Revoke
+          </a>
+        </Popconfirm>
+      )
+    }
+  ];
+
+  useEffect(() => getKeys(), []);
+
+  return (
+    <div>
+      <Form layout="inline" noValidate onSubmit={handleSubmit}>
+        <Form.Item>
+          <Input
+            placeholder="New key name"
+            value={name}
+            onChange={event => setName(event.target.value)}
+          />
+        </Form.Item>
+        <Form.Item>
+          <Button
+            icon="key"
+            htmlType="submit"
+            disabled={name === ''}
+            loading={isSubmitting}
+          >
+            Add key
+          </Button>
+        </Form.Item>
+      </Form>
+      <Table
+        dataSource={keys}
+        columns={columns}
+        rowKey="id"
+        size="small"
+        pagination={false}
+        loading={tableIsLoading}
+      />
+    </div>
+  );
+};
+
+export default ApiKeys;
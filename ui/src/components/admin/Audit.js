@@ -0,0 +1,216 @@
+import React, { useState, useEffect } from 'react';
+import Moment from 'react-moment';
+import axios from 'axios';
+
+import {
+  Typography,
+  Button,
+  Table,
+  Input,
+  Select,
+  DatePicker,
+  message,
+  Tag,
+  Form
+} from 'antd';
+import { NotifyNetworkError } from './../common/Notifications';
+
+const { Title, Text } = Typography;
+const { Option } = Select;
+const { RangePicker } = DatePicker;
+
+const pageSize = 30;
+
+const Audit = () => {
+  const [logs, setLogs] = useState([]);
+  const [total, setTotal] = useState(0);
+  const [page, setPage] = useState(1);
+  const [filters, setFilters] = useState({});
+  const [action, setAction] = useState('');
+  const [actorName, setActorName] = useState('');
+  const [targetType, setTargetType] = useState('');
+  const [targetId, setTargetId] = useState('');
+  const [range, setRange] = useState([]);
+  const [tableIsLoading, setTableIsLoading] = useState(true);
+
+  const getLogs = () => {
+    setTableIsLoading(true);
+
+    const params = {
+      ...filters,
+      start: (page - 1) * pageSize,
+      limit: pageSize,
+      sort: 'desc'
+    };
+
+    axios
+      .get('/admin/audit', { params: params })
+      .then(function(response) {
+        setLogs(response.data.result);
+        setTotal(response.data.total);
+      })
+      .catch(function(error) {
+        if (error.response) {
+          message.error(error.response.data.error);
+        } else {
+          NotifyNetworkError();
+        }
+      })
+      .finally(function() {
+        setTableIsLoading(false);
+      });
+  };
+
+  const handleSubmit = event => {
+    event.preventDefault();
+
+    const f = {};
+    if (action) f.action = action;
+    if (actorName) f.actorName = actorName;
+    if (targetType) f.targetType = targetType;
+    if (targetId) f.targetId = targetId;
+    if (range.length === 2) {
+      f.from = range[0].startOf('day').toISOString();
+      f.to = range[1].endOf('day').toISOString();
+    }
+
+    setPage(1);
+    setFilters(f);
+  };
+
+  const renderJSON = value =>
+    value ? (
+      <Text code style={{ whiteSpace: 'pre-wrap' }}>
+        {JSON.stringify(value, null, 2)}
+      </Text>
+    ) : null;
+
+  const columns = [
+    {
+      title: 'ID',
+      dataIndex: 'id'
+    },
+    {
+      title: 'Time (UTC+' + -(new Date().getTimezoneOffset() / 60) + ')',
+      dataIndex: 'createdAt',
+      render: (text, log) => (
+        <Moment date={log.createdAt} format="YYYY-MM-DD HH:mm:ss" local />
+      )
+    },
+    {
+      title: 'Actor',
+      dataIndex: 'actorName',
+      render: (text, log) => (
+        <span>
+          <Tag>{log.actorType}</Tag>
+          {log.actorName}
+        </span>
+      )
+    },
+    {
+      title: 'Action',
+      dataIndex: 'action',
+      render: (text, log) => (
+        <Tag color={log.action === 'login.failed' ? 'red' : 'blue'}>
+          {log.action}
+        </Tag>
+      )
+    },
+    {
+      title: 'Target',
+      dataIndex: 'targetId',
+      render: (text, log) => (
+        <span>
+          {log.targetType && <Tag>{log.targetType}</Tag>}
+          {log.targetId && <Text code>{log.targetId}</Text>}
+        </span>
+      )
+    },
+    {
+      title: 'IP',
+      dataIndex: 'ip'
+    }
+  ];
+
+  useEffect(() => getLogs(), [page, filters]);
+
+  return (
+    <div>
+      <Title level={3}>Audit log</Title>
+      <Form layout="inline" noValidate onSubmit={handleSubmit}>
+        <Form.Item>
+          <Input
+            placeholder="Action"
+            value={action}
+            onChange={event => setAction(event.target.value)}
+          />
+        </Form.Item>
+        <Form.Item>
+          <Input
+            placeholder="Actor"
+            value={actorName}
+            onChange={event => setActorName(event.target.value)}
+          />
+        </Form.Item>
+        <Form.Item>
+          <Select
+            value={targetType}
+            style={{ width: 140 }}
+            onChange={setTargetType}
+          >
+            <Option value="">Any target</Option>
+            <Option value="user">user</Option>
+            <Option value="apikey">apikey</Option>
+            <Option value="admin">admin</Option>
+            <Option value="session">session</Option>
+            <Option value="settings">settings</Option>
+            <Option value="queue">queue</Option>
+            <Option value="chain">chain</Option>
+            <Option value="entry">entry</Option>
+            <Option value="factomd">factomd</Option>
+          </Select>
+        </Form.Item>
+        <Form.Item>
+          <Input
+            placeholder="Target ID"
+            value={targetId}
+            onChange={event => setTargetId(event.target.value)}
+          />
+        </Form.Item>
+        <Form.Item>
+          <RangePicker value={range} onChange={setRange} />
+        </Form.Item>
+        <Form.Item>
+          <Button icon="search" htmlType="submit">
+            Filter
+          </Button>
+        </Form.Item>
+      </Form>
+      <Table
+        dataSource={logs}
+        columns={columns}
+        rowKey="id"
+        size="small"
+        loading={tableIsLoading}
+        expandedRowRender={log =>
+          log.before || log.after ? (
+            <div>
+              {log.before && <div><b>Before:</b> {renderJSON(log.before)}</div>}
+              {log.after && <div><b>After:</b> {renderJSON(log.after)}</div>}
+            </div>
+          ) : (
+            <Text type="secondary">No details</Text>
+          )
+        }
+        pagination={{
+          current: page,
+          pageSize: pageSize,
+          total: total,
+          onChange: setPage
+        }}
+      />
+    </div>
+  );
+};
+
+export default Audit;
@@ -1,20 +1,141 @@
-import React from 'react';
+import React, { useState, useEffect } from 'react';
+import axios from 'axios';
 
 import {
   Typography,
   Row,
   Col,
   Statistic,
-  Icon
+  Progress,
+  Table,
+  Tag,
+  Spin,
+  Icon,
+  message
 } from 'antd';
+import { NotifyNetworkError } from './../common/Notifications';
 
 const { Title, Paragraph } = Typography;
 
 const Dashboard = () => {
+  const [sync, setSync] = useState(null);
+  const [pool, setPool] = useState(null);
+  const [isLoading, setIsLoading] = useState(true);
+
+  const getSync = () => {
+    axios
+      .get('/admin/sync')
+      .then(function(response) {
+        setSync(response.data.result);
+      })
+      .catch(function(error) {
+        if (error.response) {
+          message.error(error.response.data.error);
+        } else {
+          NotifyNetworkError();
+        }
+      })
+      .finally(function() {
+        setIsLoading(false);
+      });
+  };
+
+  const getPool = () => {
+    axios
+      .get('/admin/pool')
+      .then(function(response) {
+        setPool(response.data.result);
+      })
+      .catch(function(error) {
+        if (error.response) {
+          message.error(error.response.data.error);
+        } else {
+          NotifyNetworkError();
+        }
+      });
+  };
+
+  useEffect(() => {
+    getSync();
+    getPool();
+    const interval = setInterval(() => {
+      getSync();
+      getPool();
+    }, 30000);
+    return () => clearInterval(interval);
+  }, []);
+
+  const poolColumns = [
+    {
+      title: 'Chain',
+      dataIndex: 'chainId',
+      render: (chainId) => (chainId ? <code>{chainId}</code> : <Tag>idle</Tag>)
+    },
+    {
+      title: 'Priority',
+      dataIndex: 'priority'
+    }
+  ];
+
+  const percent = (value, total) => (total > 0 ? Math.floor((value / total) * 100) : 0);
+
+  const chains = (sync && sync.chains) || {};
+  const synced = chains.synced || 0;
+  const total = synced + (chains.unsynced || 0);
+
   return (
     <div>
       <Title level={3}>Dashboard</Title>
-      <Paragraph type="secondary"><Icon type="info-circle" theme="twoTone" /> Dashboard is in development</Paragraph>
+      {isLoading ? (
+        <Spin />
+      ) : sync ? (
+        <div>
+          <Title level={4}><Icon type="sync" /> Blockchain sync</Title>
+          <Row gutter={16}>
+            <Col span={6}>
+              <Statistic title="Factomd DBlock" value={sync.factomdHeight} groupSeparator="" />
+            </Col>
+            <Col span={6}>
+              <Statistic title={'Updates parser DBlock (' + sync.syncMode + ' mode)'} value={sync.updatesHeight} groupSeparator="" />
+            </Col>
+            <Col span={6}>
+              <Statistic title="Synced chains" value={synced} suffix={'/ ' + total} />
+            </Col>
+          </Row>
+          <Paragraph style={{ marginTop: 16 }}>
+            Chains history
+            <Progress percent={percent(synced, total)} />
+          </Paragraph>
+          {sync.index ? (
+            <Paragraph>
+              Chains indexer: {sync.indexHeight < 0 ? 'not started' : 'DBlock ' + sync.indexHeight + ' / ' + sync.factomdHeight}
+              <Progress percent={percent(Math.max(sync.indexHeight, 0), sync.factomdHeight)} />
+            </Paragraph>
+          ) : (
+            <Paragraph type="secondary"><Icon type="info-circle" theme="twoTone" /> Chains indexer is disabled, only chains used via API are synced</Paragraph>
+          )}
+          {pool ? (
+            <div>
+              <Title level={4}><Icon type="cluster" /> History fetching pool</Title>
+              <Table
+                dataSource={pool.workers}
+                columns={[{ title: 'Worker', dataIndex: 'id' }, ...poolColumns]}
+                rowKey="id"
+                pagination={false}
+                size="small"
+              />
+              <Paragraph style={{ marginTop: 16 }}>Queue</Paragraph>
+              <Table
+                dataSource={pool.queue}
+                columns={poolColumns}
+                rowKey="chainId"
+                pagination={false}
+                size="small"
+              />
+            </div>
+          ) : null}
+        </div>
+      ) : null}
     </div>
   );
 };
@@ -0,0 +1,179 @@
+import React, { useState, useEffect } from 'react';
+import axios from 'axios';
+
+import {
+  Typography,
+  Button,
+  Icon,
+  Input,
+  message,
+  Tag,
+  Form,
+  Alert
+} from 'antd';
+import { NotifyNetworkError } from './../common/Notifications';
+
+const { Title, Text, Paragraph } = Typography;
+
+const Security = () => {
+  const [me, setMe] = useState({});
+  const [enrolment, setEnrolment] = useState(null);
+  const [recoveryCodes, setRecoveryCodes] = useState([]);
+  const [code, setCode] = useState('');
+  const [isSubmitting, setIsSubmitting] = useState(false);
+
+  const handleError = error => {
+    if (error.response) {
+      message.error(error.response.data.error);
+    } else {
+      NotifyNetworkError();
+    }
+  };
+
+  const codeData = () => {
+    const data = new FormData();
+    data.append('code', code);
+    return data;
+  };
+
+  const getMe = () => {
+    axios
+      .get('/admin/me')
+      .then(function(response) {
+        setMe(response.data.result);
+      })
+      .catch(handleError);
+  };
+
+  const enroll = () => {
+    axios
+      .post('/admin/me/totp')
+      .then(function(response) {
+        setEnrolment(response.data.result);
+        setRecoveryCodes([]);
+      })
+      .catch(handleError);
+  };
+
+  const post = (path, onSuccess) => event => {
+    event.preventDefault();
+    setIsSubmitting(true);
+
+    axios
+      .post(path, codeData())
+      .then(function(response) {
+        setCode('');
+        onSuccess(response.data.result);
+      })
+      .catch(handleError)
+      .finally(function() {
+        setIsSubmitting(false);
+      });
+  };
+
+  const enable = post('/admin/me/totp/enable', result => {
+    setEnrolment(null);
+    setRecoveryCodes(result.recoveryCodes);
+    setMe({ ...me, totpEnabled: true });
+    message.success('Two-factor authentication enabled');
+  });
+
+  const disable = post('/admin/me/totp/disable', () => {
+    setRecoveryCodes([]);
+    setMe({ ...me, totpEnabled: false });
+    message.success('Two-factor authentication disabled');
+  });
+
+  const regenerate = post('/admin/me/totp/recovery', result => {
+    setRecoveryCodes(result.recoveryCodes);
+    message.success('Recovery codes regenerated');
+  });
+
+  const codeForm = (onSubmit, button, danger) => (
+    <Form layout="inline" noValidate onSubmit={onSubmit}>
+      <Form.Item>
+        <Input
+          prefix={<Icon type="safety" style={{ color: 'rgba(0,0,0,.25)' }} />}
+          placeholder="Authentication code"
+          value={code}
+          autoComplete="one-time-code"
+          onChange={event => setCode(event.target.value)}
+        />
+      </Form.Item>
+      <Form.Item>
+        <Button
+          type={danger ? 'danger' : 'primary'}
+          htmlType="submit"
+          disabled={code === ''}
+          loading={isSubmitting}
+        >
+          {button}
+        </Button>
+      </Form.Item>
+    </Form>
+  );
+
+  useEffect(() => getMe(), []);
+
+  return (
+    <div>
+      <Title level={3}>Security</Title>
+      <Paragraph>
+        Two-factor authentication:{' '}
+        <Tag color={me.totpEnabled ? 'green' : 'orange'}>
+          {me.totpEnabled ? 'ON' : 'OFF'}
+        </Tag>
+      </Paragraph>
+      {recoveryCodes.length > 0 && (
+        <Alert
+          type="warning"
+          message="Save recovery codes"
+          description={
+            <div>
+              <Paragraph>
+                Each code can be used once instead of the authentication code.
+                They will not be shown again.
+              </Paragraph>
+              <Text code copyable={{ text: recoveryCodes.join('\n') }}>
+                {recoveryCodes.join(' ')}
+              </Text>
+            </div>
+          }
+          style={{ marginBottom: 16 }}
+        />
+      )}
+      {!me.totpEnabled && !enrolment && (
+        <Button type="primary" icon="safety" onClick={enroll}>
+          Set up two-factor authentication
+        </Button>
+      )}
+      {!me.totpEnabled && enrolment && (
+        <div>
+          <Paragraph>
+            Add the account into your authenticator app using the link or the
+            secret key, then enter the code from the app.
+          </Paragraph>
+          <Paragraph>
+            <a href={enrolment.uri}>{enrolment.uri}</a>
+          </Paragraph>
+          <Paragraph>
+            Secret key: <Text code copyable>{enrolment.secret}</Text>
+          </Paragraph>
+          {codeForm(enable, 'Enable')}
+        </div>
+      )}
+      {me.totpEnabled && (
+        <div>
+          <Paragraph>
+            Enter the authentication code to regenerate recovery codes or to
+            disable two-factor authentication.
+          </Paragraph>
+          {codeForm(regenerate, 'Regenerate recovery codes')}
+          {codeForm(disable, 'Disable', true)}
+        </div>
+      )}
+    </div>
+  );
+};
+
+export default Security;
@@ -0,0 +1,137 @@
+import React, { useState, useEffect } from 'react';
+import Moment from 'react-moment';
+import axios from 'axios';
+
+import { Typography, Button, Icon, Table, Popconfirm, message, Tag } from 'antd';
+import { NotifyNetworkError } from './../common/Notifications';
+
+const { Title, Text } = Typography;
+
+const Sessions = () => {
+  const [sessions, setSessions] = useState([]);
+  const [tableIsLoading, setTableIsLoading] = useState(true);
+  const [isRotating, setIsRotating] = useState(false);
+
+  const handleError = error => {
+    if (error.response) {
+      message.error(error.response.data.error);
+    } else {
+      NotifyNetworkError();
+    }
+  };
+
+  const getSessions = () => {
+    axios
+      .get('/admin/sessions')
+      .then(function(response) {
+        setSessions(response.data.result);
+      })
+      .catch(handleError)
+      .finally(function() {
+        setTableIsLoading(false);
+      });
+  };
+
+  const revokeSession = session => {
+    setTableIsLoading(true);
+
+    axios
+      .delete('/admin/sessions/' + session.id)
+      .then(function() {
+        setSessions([...sessions].filter(v => v.id !== session.id));
+        message.success('Session revoked');
+      })
+      .catch(handleError)
+      .finally(function() {
+        setTableIsLoading(false);
+      });
+  };
+
+  const rotateKey = () => {
+    setIsRotating(true);
+
+    axios
+      .post('/admin/sessions/rotate')
+      .then(function() {
+        message.success('Signing key rotated');
+      })
+      .catch(handleError)
+      .finally(function() {
+        setIsRotating(false);
+      });
+  };
+
+  const columns = [
+    {
+      title: 'Admin',
+      dataIndex: 'admin',
+      render: (text, session) => (
+        <span>
+          {session.admin ? session.admin.username : session.adminId}
+          {session.current && <Tag color="green" style={{ marginLeft: 8 }}>current</Tag>}
+        </span>
+      )
+    },
+    {
+      title: 'IP',
+      dataIndex: 'ip'
+    },
+    {
+      title: 'User agent',
+      dataIndex: 'userAgent',
+      render: text => <Text type="secondary">{text}</Text>
+    },
+    {
+      title: 'Last seen',
+      dataIndex: 'lastSeenAt',
+      render: (text, session) =>
+        session.lastSeenAt && (
+          <Moment date={session.lastSeenAt} format="YYYY-MM-DD HH:mm:ss" local />
+        )
+    },
+    {
+      title: 'Expires',
+      dataIndex: 'expiresAt',
+      render: (text, session) => (
+        <Moment date={session.expiresAt} format="YYYY-MM-DD HH:mm:ss" local />
+      )
+    },
+    {
+      title: 'Actions',
+      key: 'actions',
+      render: (text, session) => (
+        <Popconfirm
+          title="Revoke session?"
+          onConfirm={() => revokeSession(session)}
+          okText="Revoke"
+          cancelText="No"
+        >
+          <a href="javascript:;" style={{ color: '#f5222d' }}>
+            <Icon type="stop" theme="twoTone" twoToneColor="#f5222d" />
+             Revoke
+          </a>
+        </Popconfirm>
+      )
+    }
+  ];
+
+  useEffect(() => getSessions(), []);
+
+  return (
+    <div>
+      <Title level={3}>Sessions</Title>
+      <Button icon="sync" onClick={rotateKey} loading={isRotating}>
+        Rotate signing key
+      </Button>
+      <Table
+        dataSource={sessions}
+        columns={columns}
+        rowKey="id"
+        loading={tableIsLoading}
+        style={{ marginTop: 16 }}
+      />
+    </div>
+  );
+};
+
+export default Sessions;
@@ -43,8 +43,12 @@ const Settings = () => {
 
     axios
       .post('/admin/settings', data)
-      .then(function() {
+      .then(function(response) {
         message.success(`Settings updated`);
+        if (response.data.restartRequired) {
+          message.warning(`Restart Factom Open API to apply database settings`);
+        }
+        getSettings();
       })
       .catch(function(error) {
         if (error.response) {
@@ -52,29 +56,9 @@ const Settings = () => {
         } else {
           NotifyNetworkError();
         }
-        setIsSubmitting(false);
       })
       .finally(function() {
-        restartAPI();
-      });
-  };
-
-  const restartAPI = () => {
-
-    axios
-      .get('/admin/restart')
-      .then(function() {
-        message.loading('Restarting API…', 0);
-        setTimeout(function() {
-          window.location.reload(true);
-        }, 5000);
-      })
-      .catch(function(error) {
-        if (error.response) {
-          message.error(error.response.data.error);
-        } else {
-          NotifyNetworkError();
-        }
+        setIsSubmitting(false);
       });
   };
 
@@ -155,7 +139,7 @@ const Settings = () => {
     <div className="settings-form">
       <Title level={3}>Settings</Title>
 
-      <Paragraph type="secondary"><Icon type="info-circle" theme="twoTone" /> API server will be restarted after settings update.<br />Current admin session will be terminated.</Paragraph>
+      <Paragraph type="secondary"><Icon type="info-circle" theme="twoTone" /> Settings are validated and applied without restart of API server.</Paragraph>
       
       {settings.Admin ? (
         <Form layout="vertical" onSubmit={handleSubmit}>
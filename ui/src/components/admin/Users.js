@@ -12,12 +12,15 @@ import {
   Tooltip,
   message,
   Tag,
+  Select,
   Form
 } from 'antd';
 import { NotifyNetworkError } from './../common/Notifications';
 import EditableText from './../common/EditableText';
+import ApiKeys from './ApiKeys';
 
 const { Title, Text } = Typography;
+const { Option } = Select;
 
 const Users = () => {
   const [formHasErrors, setFormHasErrors] = useState(true);
@@ -51,6 +54,11 @@ const Users = () => {
             accessToken: user.accessToken,
             usage: user.usage,
             usageLimit: user.usageLimit,
+            usagePeriod: user.usagePeriod,
+            usagePeriodDays: user.usagePeriodDays,
+            rateLimitRead: user.rateLimitRead,
+            rateLimitWrite: user.rateLimitWrite,
+            rateLimitProxy: user.rateLimitProxy,
             status: user.status
           }
         ]);
@@ -166,11 +174,16 @@ const Users = () => {
     {
       title: 'Token',
       dataIndex: 'accessToken',
-      render: (text, user) => (
-        <Text copyable={{ text: user.accessToken }}>
-          {user.accessToken.substring(0, 6)}…
-        </Text>
-      )
+      render: (text, user) => {
+        if (user.accessToken) {
+          return (
+            <Text copyable={{ text: user.accessToken }} mark>
+              {user.accessToken}
+            </Text>
+          );
+        }
+        return <Text type="secondary">hidden</Text>;
+      }
     },
     {
       title: () => (
@@ -200,7 +213,7 @@ const Users = () => {
         <span>
           Usage limit 
           <Tooltip placement="top" title={
-            <span>Total usage limit <b>(not monthly)</b><br />Set <b>0</b> for no limit</span>
+            <span>Usage limit per period<br />Set <b>0</b> for no limit</span>
           }>
             <Text type="secondary">
               <Icon type="info-circle" />
@@ -218,6 +231,61 @@ const Users = () => {
         />
       )
     },
+    {
+      title: 'Period',
+      dataIndex: 'usagePeriod',
+      render: (text, user) => (
+        <span>
+          <Select
+            value={user.usagePeriod}
+            size="small"
+            style={{ width: 100 }}
+            onChange={value => updateUser(user, 'usagePeriod', value)}
+          >
+            <Option value="">lifetime</Option>
+            <Option value="daily">daily</Option>
+            <Option value="monthly">monthly</Option>
+            <Option value="custom">custom</Option>
+          </Select>
+          {user.usagePeriod === 'custom' && (
+            <EditableText
+              text={user.usagePeriodDays}
+              placeholder="days"
+              type="number"
+              onSave={value => updateUser(user, 'usagePeriodDays', value)}
+            />
+          )}
+        </span>
+      )
+    },
+    {
+      title: () => (
+        <span>
+          Rate limits 
+          <Tooltip placement="top" title={
+            <span>Requests per minute for <b>read / write / factomd</b> endpoints<br />Set <b>0</b> for default, <b>-1</b> for no limit</span>
+          }>
+            <Text type="secondary">
+              <Icon type="info-circle" />
+            </Text>
+          </Tooltip>
+        </span>
+      ),
+      key: 'rateLimits',
+      render: (text, user) => (
+        <span>
+          {['rateLimitRead', 'rateLimitWrite', 'rateLimitProxy'].map(field => (
+            <EditableText
+              key={field}
+              text={user[field]}
+              placeholder="0"
+              type="number"
+              onSave={value => updateUser(user, field, value)}
+            />
+          ))}
+        </span>
+      )
+    },
     {
       title: 'Status',
       dataIndex: 'status',
@@ -308,6 +376,7 @@ const Users = () => {
         columns={columns}
         rowKey="id"
         loading={tableIsLoading}
+        expandedRowRender={user => <ApiKeys user={user} />}
       />
     </div>
   );